@@ -2,20 +2,22 @@ package command
 
 import (
 	"log"
-	"os"
 
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/samber/do/v2"
 
 	"gorm.io/gorm"
 )
 
-func Commands(db *gorm.DB) bool {
+// Commands runs the database commands in args, and reports whether the
+// server should start.
+func Commands(injector do.Injector, args []string) bool {
 	migrate := false
 	seed := false
 	rollback := false
 	run := false
 
-	for _, arg := range os.Args[1:] {
+	for _, arg := range args {
 		if arg == "--migrate" {
 			migrate = true
 		}
@@ -30,6 +32,8 @@ func Commands(db *gorm.DB) bool {
 		}
 	}
 
+	db := do.MustInvoke[*gorm.DB](injector)
+
 	if migrate {
 		if err := migration.Migrate(db); err != nil {
 			log.Fatalf("error migration: %v", err)
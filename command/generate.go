@@ -0,0 +1,55 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/scaffold"
+)
+
+var ErrorGenerateUsage = errors.New("usage: generate module <name> --fields name:type,...")
+
+// Generate runs the generate subcommand, which scaffolds a bounded context
+// under root:
+//
+//	generate module product --fields title:string,price:float
+func Generate(root string, args []string) error {
+	if len(args) < 2 || args[0] != "module" {
+		return ErrorGenerateUsage
+	}
+	name := args[1]
+
+	flags := flag.NewFlagSet("generate module", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	fieldSpec := flags.String("fields", "", "comma separated name:type pairs")
+	if err := flags.Parse(args[2:]); err != nil {
+		return fmt.Errorf("%w: %v", ErrorGenerateUsage, err)
+	}
+
+	fields, err := scaffold.ParseFields(*fieldSpec)
+	if err != nil {
+		return err
+	}
+	m, err := scaffold.NewModule(root, name, fields)
+	if err != nil {
+		return err
+	}
+
+	files, err := scaffold.Plan(root, m)
+	if err != nil {
+		return err
+	}
+	paths, err := scaffold.Write(root, files)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		log.Printf("wrote %s", path)
+	}
+	log.Printf("module %s generated, translate its messages in the non-English catalogs then run --migrate and --openapi", name)
+	return nil
+}
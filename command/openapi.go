@@ -0,0 +1,120 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/provider"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// OpenAPIPath is where the generated OpenAPI document is committed.
+const OpenAPIPath = "docs/openapi.json"
+
+// ErrorOpenAPIStale is returned by CheckOpenAPI when the committed document
+// differs from the generated one.
+var ErrorOpenAPIStale = errors.New("openapi document is stale, regenerate it with --openapi")
+
+// OpenAPI runs the --openapi and --openapi-check flags in args and returns the
+// other args. The document only needs the routes, so the flags run against an
+// injector of their own whose database is an empty in-memory SQLite one. They
+// neither read .env nor connect to the configured database, and so run on a
+// clean checkout.
+func OpenAPI(args []string) []string {
+	var (
+		rest         []string
+		writeOpenAPI bool
+		checkOpenAPI bool
+	)
+	for _, arg := range args {
+		switch arg {
+		case "--openapi":
+			writeOpenAPI = true
+		case "--openapi-check":
+			checkOpenAPI = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if !writeOpenAPI && !checkOpenAPI {
+		return rest
+	}
+
+	injector := do.New()
+	provider.RegisterDependencies(injector)
+	do.Override(injector, func(do.Injector) (*gorm.DB, error) {
+		return config.OpenSQLite(config.SQLiteMemory, &gorm.Config{Logger: logger.Discard})
+	})
+	defer func() { _ = injector.Shutdown() }()
+
+	if writeOpenAPI {
+		if err := WriteOpenAPI(injector, OpenAPIPath); err != nil {
+			log.Fatalf("error writing openapi document: %v", err)
+		}
+		log.Printf("openapi document written to %s", OpenAPIPath)
+	}
+
+	if checkOpenAPI {
+		if err := CheckOpenAPI(injector, OpenAPIPath); err != nil {
+			log.Fatalf("error checking openapi document: %v", err)
+		}
+		log.Printf("openapi document %s is up to date", OpenAPIPath)
+	}
+
+	return rest
+}
+
+// GenerateOpenAPI registers the routes on an engine of their own and returns
+// the encoded document. Scopes cannot be removed, so each call gets a scope of
+// its own name.
+func GenerateOpenAPI(injector do.Injector) ([]byte, error) {
+	scope := injector.Scope("openapi-" + uuid.NewString())
+	defer func() { _ = scope.Shutdown() }()
+
+	mode := gin.Mode()
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(mode)
+
+	do.ProvideValue(scope, gin.New())
+	route.RegisterRoutes(scope)
+
+	document, err := do.Invoke[*openapi.Document](scope)
+	if err != nil {
+		return nil, err
+	}
+	return document.JSON()
+}
+
+func WriteOpenAPI(injector do.Injector, path string) error {
+	content, err := GenerateOpenAPI(injector)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+func CheckOpenAPI(injector do.Injector, path string) error {
+	content, err := GenerateOpenAPI(injector)
+	if err != nil {
+		return err
+	}
+
+	committed, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(committed, content) {
+		return fmt.Errorf("%s: %w", path, ErrorOpenAPIStale)
+	}
+	return nil
+}
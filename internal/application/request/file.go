@@ -0,0 +1,9 @@
+package request
+
+type (
+	FileDownload struct {
+		Path      string `form:"-" binding:"required"`
+		Expires   int64  `form:"expires" binding:"required"`
+		Signature string `form:"signature" binding:"required"`
+	}
+)
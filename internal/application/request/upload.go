@@ -0,0 +1,18 @@
+package request
+
+import "io"
+
+type (
+	UploadCreate struct {
+		Length   int64
+		Metadata string
+	}
+
+	UploadPatch struct {
+		ID            string
+		Offset        int64
+		Checksum      string
+		ContentLength int64
+		Body          io.Reader
+	}
+)
@@ -17,6 +17,11 @@ type (
 		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"omitempty,min=8,max=20"`
 	}
 
+	UserAvatar struct {
+		Image  *multipart.FileHeader `json:"-" form:"image" binding:"required_without=FileID"`
+		FileID string                `json:"file_id" form:"file_id" binding:"omitempty,uuid"`
+	}
+
 	UserLogin struct {
 		Email    string `json:"email" form:"email" binding:"required,email"`
 		Password string `json:"password" form:"password" binding:"required,min=8"`
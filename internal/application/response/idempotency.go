@@ -0,0 +1,18 @@
+package response
+
+type (
+	// IdempotentResponse is the response stored for a request made with an
+	// Idempotency-Key.
+	IdempotentResponse struct {
+		Status int
+		Header map[string][]string
+		Body   []byte
+	}
+
+	// IdempotencyClaim is the outcome of claiming a key: the ID of the claim
+	// when the request may run, or the response to replay when it already has.
+	IdempotencyClaim struct {
+		ID     string
+		Replay *IdempotentResponse
+	}
+)
@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type Upload struct {
+	ID        string            `json:"id"`
+	Offset    int64             `json:"offset"`
+	Length    int64             `json:"length"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	FileID    string            `json:"file_id,omitempty"`
+}
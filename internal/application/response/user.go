@@ -1,24 +1,33 @@
 package response
 
+import "time"
+
 type (
 	User struct {
-		ID          string `json:"id"`
-		Name        string `json:"name"`
-		Email       string `json:"email"`
-		PhoneNumber string `json:"phone_number"`
-		Role        string `json:"role"`
-		ImageUrl    string `json:"image_url"`
-		IsVerified  bool   `json:"is_verified"`
+		ID              string            `json:"id"`
+		Name            string            `json:"name"`
+		Email           string            `json:"email"`
+		PhoneNumber     string            `json:"phone_number"`
+		Role            string            `json:"role"`
+		ImageUrl        string            `json:"image_url"`
+		ImageThumbnails map[string]string `json:"image_thumbnails,omitempty"`
+		IsVerified      bool              `json:"is_verified"`
+		// Highlights marks where a searched term occurs in the name and
+		// email, as HTML, for the fields it occurs in.
+		Highlights map[string]string `json:"highlights,omitempty"`
+		// Version is sent in the ETag header rather than the body.
+		Version int64 `json:"-"`
 	}
 
 	UserCreate struct {
-		ID          string `json:"id"`
-		Name        string `json:"name"`
-		Email       string `json:"email"`
-		PhoneNumber string `json:"phone_number"`
-		Role        string `json:"role"`
-		ImageUrl    string `json:"image_url"`
-		IsVerified  bool   `json:"is_verified"`
+		ID              string            `json:"id"`
+		Name            string            `json:"name"`
+		Email           string            `json:"email"`
+		PhoneNumber     string            `json:"phone_number"`
+		Role            string            `json:"role"`
+		ImageUrl        string            `json:"image_url"`
+		ImageThumbnails map[string]string `json:"image_thumbnails,omitempty"`
+		IsVerified      bool              `json:"is_verified"`
 	}
 
 	UserUpdate struct {
@@ -28,5 +37,14 @@ type (
 		PhoneNumber string `json:"phone_number"`
 		Role        string `json:"role"`
 		IsVerified  bool   `json:"is_verified"`
+		Version     int64  `json:"-"`
+	}
+
+	// UserSession is a signed-in device of a user, identified by its refresh
+	// token, which is never included.
+	UserSession struct {
+		ID        string    `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		ExpiresAt time.Time `json:"expires_at"`
 	}
 )
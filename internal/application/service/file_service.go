@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/samber/do/v2"
+)
+
+type (
+	FileService interface {
+		Download(ctx context.Context, req request.FileDownload) (io.ReadSeekCloser, port.FileInfo, error)
+		DeleteUnreferenced(ctx context.Context) (int, error)
+	}
+
+	fileService struct {
+		fileStorage       port.FileStoragePort
+		urlSigner         port.URLSignerPort
+		fileDomainService *file.Service
+	}
+)
+
+func NewFileService(injector do.Injector) FileService {
+	fileStorage := do.MustInvoke[port.FileStoragePort](injector)
+	urlSigner := do.MustInvoke[port.URLSignerPort](injector)
+	fileDomainService := do.MustInvoke[*file.Service](injector)
+	return &fileService{
+		fileStorage:       fileStorage,
+		urlSigner:         urlSigner,
+		fileDomainService: fileDomainService,
+	}
+}
+
+func (s *fileService) Download(ctx context.Context, req request.FileDownload) (io.ReadSeekCloser, port.FileInfo, error) {
+	filePath := strings.TrimPrefix(req.Path, "/")
+	if filePath == "" || path.Clean(filePath) != filePath || strings.HasPrefix(filePath, "../") {
+		return nil, port.FileInfo{}, file.ErrorInvalidPath
+	}
+
+	if err := s.urlSigner.Verify(filePath, time.Unix(req.Expires, 0), req.Signature); err != nil {
+		return nil, port.FileInfo{}, err
+	}
+
+	info, err := s.fileStorage.Stat(ctx, filePath)
+	if err != nil {
+		if errors.Is(err, file.ErrorFileNotFound) {
+			return nil, port.FileInfo{}, file.ErrorFileNotFound
+		}
+		return nil, port.FileInfo{}, file.ErrorDownloadFile
+	}
+
+	content, err := s.fileStorage.Open(ctx, filePath)
+	if err != nil {
+		return nil, port.FileInfo{}, file.ErrorDownloadFile
+	}
+
+	return content, info, nil
+}
+
+func (s *fileService) DeleteUnreferenced(ctx context.Context) (int, error) {
+	return s.fileDomainService.DeleteUnreferenced(ctx)
+}
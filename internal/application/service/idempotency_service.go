@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/samber/do/v2"
+
+	"gorm.io/gorm"
+)
+
+type (
+	IdempotencyService interface {
+		// Begin claims key within scope for a request with fingerprint, or
+		// returns the response to replay when a request with the same
+		// fingerprint has already completed under it.
+		Begin(ctx context.Context, scope string, key string, fingerprint string) (response.IdempotencyClaim, error)
+		// Complete stores the response of a claimed request for replay.
+		Complete(ctx context.Context, claimID string, res response.IdempotentResponse) error
+		// Release gives up a claim without storing a response, so a retry
+		// runs the request again.
+		Release(ctx context.Context, claimID string) error
+		DeleteExpired(ctx context.Context) (int, error)
+	}
+
+	idempotencyService struct {
+		idempotencyRepository idempotency.Repository
+		clock                 port.ClockPort
+		ttl                   time.Duration
+		lockTimeout           time.Duration
+	}
+)
+
+func NewIdempotencyService(injector do.Injector) IdempotencyService {
+	idempotencyRepository := do.MustInvoke[idempotency.Repository](injector)
+	clock := do.MustInvoke[port.ClockPort](injector)
+	return &idempotencyService{
+		idempotencyRepository: idempotencyRepository,
+		clock:                 clock,
+		ttl:                   getIdempotencyKeyTTL(),
+		lockTimeout:           getIdempotencyLockTimeout(),
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, scope string, key string, fingerprint string) (response.IdempotencyClaim, error) {
+	if len(key) > idempotency.MaxKeyLength {
+		return response.IdempotencyClaim{}, idempotency.ErrorKeyInvalid
+	}
+
+	// The unique index on the key settles races between retries: a second
+	// attempt is only needed after taking over a stale record.
+	for range 2 {
+		now := s.clock.Now()
+		created, err := s.idempotencyRepository.Create(ctx, idempotency.Record{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(s.ttl),
+		})
+		if err == nil {
+			return response.IdempotencyClaim{ID: created.ID.String()}, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return response.IdempotencyClaim{}, idempotency.ErrorClaimKey
+		}
+
+		existing, err := s.idempotencyRepository.GetByKey(ctx, scope, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return response.IdempotencyClaim{}, idempotency.ErrorClaimKey
+		}
+
+		if !existing.Stale(now, s.lockTimeout) {
+			return replay(existing, fingerprint)
+		}
+		if err = s.idempotencyRepository.Delete(ctx, existing.ID.String()); err != nil {
+			return response.IdempotencyClaim{}, idempotency.ErrorClaimKey
+		}
+	}
+
+	return response.IdempotencyClaim{}, idempotency.ErrorRequestInFlight
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, claimID string, res response.IdempotentResponse) error {
+	return s.idempotencyRepository.Complete(ctx, claimID, idempotency.Response{
+		Status: res.Status,
+		Header: res.Header,
+		Body:   res.Body,
+	})
+}
+
+func (s *idempotencyService) Release(ctx context.Context, claimID string) error {
+	return s.idempotencyRepository.Delete(ctx, claimID)
+}
+
+// DeleteExpired removes the keys past their TTL and returns how many were
+// removed.
+func (s *idempotencyService) DeleteExpired(ctx context.Context) (int, error) {
+	return s.idempotencyRepository.DeleteExpired(ctx, s.clock.Now())
+}
+
+// replay answers a request whose key is held by record.
+func replay(record idempotency.Record, fingerprint string) (response.IdempotencyClaim, error) {
+	if record.InFlight() {
+		return response.IdempotencyClaim{}, idempotency.ErrorRequestInFlight
+	}
+	if record.Fingerprint != fingerprint {
+		return response.IdempotencyClaim{}, idempotency.ErrorKeyReused
+	}
+
+	return response.IdempotencyClaim{Replay: &response.IdempotentResponse{
+		Status: record.Status,
+		Header: record.Header,
+		Body:   record.Body,
+	}}, nil
+}
+
+func getIdempotencyKeyTTL() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil || duration <= 0 {
+		return idempotency.DefaultTTL
+	}
+	return duration
+}
+
+func getIdempotencyLockTimeout() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_LOCK_TIMEOUT"))
+	if err != nil || duration <= 0 {
+		return idempotency.DefaultLockTimeout
+	}
+	return duration
+}
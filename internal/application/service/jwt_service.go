@@ -8,7 +8,9 @@ import (
 	"os"
 	"time"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/samber/do/v2"
 )
 
 type (
@@ -30,26 +32,30 @@ type (
 		issuer            string
 		accessExpiration  time.Duration
 		refreshExpiration time.Duration
+		clock             port.ClockPort
 	}
 )
 
-func NewJWTService() JWTService {
+func NewJWTService(injector do.Injector) JWTService {
+	clock := do.MustInvoke[port.ClockPort](injector)
 	return &jwtService{
 		secretKey:         getSecretKey(),
 		issuer:            getIssuer(),
 		accessExpiration:  getAccessExpiration(),
 		refreshExpiration: getRefreshExpiration(),
+		clock:             clock,
 	}
 }
 
 func (j *jwtService) GenerateAccessToken(userID string, role string) string {
+	now := j.clock.Now()
 	claims := jwtCustomClaim{
 		userID,
 		role,
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessExpiration)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.accessExpiration)),
 			Issuer:    j.issuer,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -71,13 +77,13 @@ func (j *jwtService) GenerateRefreshToken() (string, time.Time) {
 	}
 
 	refreshToken := base64.StdEncoding.EncodeToString(b)
-	expiresAt := time.Now().Add(j.refreshExpiration)
+	expiresAt := j.clock.Now().Add(j.refreshExpiration)
 
 	return refreshToken, expiresAt
 }
 
 func (j *jwtService) ValidateToken(token string) (*jwt.Token, error) {
-	return jwt.Parse(token, j.parseToken)
+	return jwt.Parse(token, j.parseToken, jwt.WithTimeFunc(j.clock.Now))
 }
 
 func (j *jwtService) GetUserIDByToken(token string) (string, error) {
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+
+	"gorm.io/gorm"
+)
+
+type (
+	UploadService interface {
+		Create(ctx context.Context, userID string, req request.UploadCreate) (response.Upload, error)
+		Get(ctx context.Context, userID string, uploadID string) (response.Upload, error)
+		Patch(ctx context.Context, userID string, req request.UploadPatch) (response.Upload, error)
+		Terminate(ctx context.Context, userID string, uploadID string) error
+		DeleteExpired(ctx context.Context) (int, error)
+		MaxSize() int64
+	}
+
+	uploadService struct {
+		uploadRepository  file.UploadRepository
+		fileDomainService *file.Service
+		fileStorage       port.FileStoragePort
+		unitOfWork        application.UnitOfWork
+		clock             port.ClockPort
+		idGenerator       port.IDGeneratorPort
+		maxSize           int64
+		expiration        time.Duration
+	}
+)
+
+func NewUploadService(injector do.Injector) UploadService {
+	uploadRepository := do.MustInvoke[file.UploadRepository](injector)
+	fileDomainService := do.MustInvoke[*file.Service](injector)
+	fileStorage := do.MustInvoke[port.FileStoragePort](injector)
+	unitOfWork := do.MustInvoke[application.UnitOfWork](injector)
+	clock := do.MustInvoke[port.ClockPort](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &uploadService{
+		uploadRepository:  uploadRepository,
+		fileDomainService: fileDomainService,
+		fileStorage:       fileStorage,
+		unitOfWork:        unitOfWork,
+		clock:             clock,
+		idGenerator:       idGenerator,
+		maxSize:           getUploadMaxSize(),
+		expiration:        getUploadExpiration(),
+	}
+}
+
+func (s *uploadService) Create(ctx context.Context, userID string, req request.UploadCreate) (response.Upload, error) {
+	if req.Length < 0 {
+		return response.Upload{}, file.ErrorUploadLengthInvalid
+	}
+	if req.Length > s.maxSize {
+		return response.Upload{}, file.ErrorUploadTooLarge
+	}
+
+	metadata, err := file.ParseMetadata(req.Metadata)
+	if err != nil {
+		return response.Upload{}, err
+	}
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return response.Upload{}, file.ErrorCreateUpload
+	}
+
+	if err = s.fileDomainService.CheckQuota(ctx, userID, req.Length); err != nil {
+		return response.Upload{}, err
+	}
+
+	createdUpload, err := s.uploadRepository.Create(ctx, file.Upload{
+		OwnerID:   identity.NewID(ownerID),
+		Length:    req.Length,
+		Metadata:  metadata,
+		ExpiresAt: s.clock.Now().Add(s.expiration),
+	})
+	if err != nil {
+		return response.Upload{}, file.ErrorCreateUpload
+	}
+
+	if createdUpload.IsComplete() {
+		if createdUpload, err = s.complete(ctx, createdUpload); err != nil {
+			return response.Upload{}, err
+		}
+	}
+
+	return uploadResponse(createdUpload), nil
+}
+
+func (s *uploadService) Get(ctx context.Context, userID string, uploadID string) (response.Upload, error) {
+	retrievedUpload, err := s.find(ctx, userID, uploadID)
+	if err != nil {
+		return response.Upload{}, err
+	}
+
+	return uploadResponse(retrievedUpload), nil
+}
+
+// Patch stores the request body as the part starting at req.Offset. A chunk
+// is only counted once it has been fully written and, when a checksum is
+// given, verified; anything else is discarded so the client can resume from
+// the last accepted offset.
+func (s *uploadService) Patch(ctx context.Context, userID string, req request.UploadPatch) (response.Upload, error) {
+	retrievedUpload, err := s.find(ctx, userID, req.ID)
+	if err != nil {
+		return response.Upload{}, err
+	}
+
+	if req.Offset != retrievedUpload.Offset {
+		return response.Upload{}, file.ErrorUploadOffsetMismatch
+	}
+
+	remaining := retrievedUpload.Length - retrievedUpload.Offset
+	if req.ContentLength > remaining {
+		return response.Upload{}, file.ErrorUploadTooLarge
+	}
+
+	var checksum *file.Checksum
+	if req.Checksum != "" {
+		if checksum, err = file.NewChecksum(req.Checksum); err != nil {
+			return response.Upload{}, err
+		}
+	}
+
+	partKey := retrievedUpload.PartKey(req.Offset, s.idGenerator.NewID().String())
+	counter := &countingWriter{}
+	var sink io.Writer = counter
+	if checksum != nil {
+		sink = io.MultiWriter(counter, checksum)
+	}
+
+	body := io.TeeReader(io.LimitReader(req.Body, remaining), sink)
+	if err = s.fileStorage.Put(ctx, partKey, body, -1, file.TusContentType); err != nil {
+		// A client that disconnects mid-chunk cancels ctx, so clean up without it.
+		_ = s.fileStorage.Delete(context.WithoutCancel(ctx), partKey)
+		return response.Upload{}, file.ErrorUploadChunk
+	}
+
+	if checksum != nil {
+		if err = checksum.Verify(); err != nil {
+			_ = s.fileStorage.Delete(context.WithoutCancel(ctx), partKey)
+			return response.Upload{}, err
+		}
+	}
+
+	if counter.written == 0 {
+		_ = s.fileStorage.Delete(context.WithoutCancel(ctx), partKey)
+
+		// An upload can be complete without a file when assembling it failed,
+		// so an empty chunk retries that.
+		if retrievedUpload.IsComplete() && retrievedUpload.FileID == nil {
+			if retrievedUpload, err = s.complete(ctx, retrievedUpload); err != nil {
+				return response.Upload{}, err
+			}
+		}
+		return uploadResponse(retrievedUpload), nil
+	}
+
+	advancedUpload, err := s.uploadRepository.AdvanceOffset(ctx, req.ID, req.Offset, req.Offset+counter.written, partKey)
+	if err != nil {
+		_ = s.fileStorage.Delete(context.WithoutCancel(ctx), partKey)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Upload{}, file.ErrorUploadOffsetMismatch
+		}
+		return response.Upload{}, file.ErrorUploadChunk
+	}
+
+	if advancedUpload.IsComplete() {
+		if advancedUpload, err = s.complete(ctx, advancedUpload); err != nil {
+			return response.Upload{}, err
+		}
+	}
+
+	return uploadResponse(advancedUpload), nil
+}
+
+func (s *uploadService) Terminate(ctx context.Context, userID string, uploadID string) error {
+	retrievedUpload, err := s.find(ctx, userID, uploadID)
+	if err != nil && !errors.Is(err, file.ErrorUploadExpired) {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.uploadRepository.Delete(ctx, uploadID); err != nil {
+			return err
+		}
+
+		if retrievedUpload.FileID != nil {
+			if err := s.fileDomainService.Release(ctx, retrievedUpload.FileID.String()); err != nil {
+				return err
+			}
+		}
+
+		s.unitOfWork.OnCommit(ctx, func(ctx context.Context) error {
+			return s.deleteParts(ctx, retrievedUpload)
+		})
+
+		return nil
+	})
+}
+
+// DeleteExpired removes every incomplete upload past its expiration along with
+// the parts stored for it, and returns how many were removed.
+func (s *uploadService) DeleteExpired(ctx context.Context) (int, error) {
+	expiredUploads, err := s.uploadRepository.FindExpired(ctx, s.clock.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	deleted := 0
+	for _, expiredUpload := range expiredUploads {
+		if err = s.uploadRepository.Delete(ctx, expiredUpload.ID.String()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = s.deleteParts(ctx, expiredUpload); err != nil {
+			errs = append(errs, err)
+		}
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+func (s *uploadService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// find returns an upload owned by userID. Uploads owned by someone else are
+// reported as missing so their IDs cannot be probed.
+func (s *uploadService) find(ctx context.Context, userID string, uploadID string) (file.Upload, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return file.Upload{}, file.ErrorUploadNotFound
+	}
+
+	retrievedUpload, err := s.uploadRepository.GetUploadByID(ctx, uploadID)
+	if err != nil || retrievedUpload.OwnerID.String() != userID {
+		return file.Upload{}, file.ErrorUploadNotFound
+	}
+
+	if retrievedUpload.IsExpired(s.clock.Now()) {
+		return retrievedUpload, file.ErrorUploadExpired
+	}
+
+	return retrievedUpload, nil
+}
+
+// complete joins the accepted parts into a single registered file, which the
+// upload keeps a reference to. The parts are only removed once that has been
+// committed, or straight away when the file turns out to be infected.
+func (s *uploadService) complete(ctx context.Context, completedUpload file.Upload) (file.Upload, error) {
+	readers := make([]io.Reader, 0, len(completedUpload.Parts))
+	for _, partKey := range completedUpload.Parts {
+		opened, err := s.fileStorage.Open(ctx, partKey)
+		if err != nil {
+			return file.Upload{}, file.ErrorUploadComplete
+		}
+		defer opened.Close()
+		readers = append(readers, opened)
+	}
+
+	var markedUpload file.Upload
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		storedFile, err := s.fileDomainService.Store(ctx, completedUpload.OwnerID, completedUpload.Metadata["filename"], io.MultiReader(readers...))
+		if err != nil {
+			if errors.Is(err, file.ErrorQuotaExceeded) || errors.Is(err, file.ErrorFileInfected) || errors.Is(err, file.ErrorScanFile) {
+				return err
+			}
+			return fmt.Errorf("%w: %w", file.ErrorUploadComplete, err)
+		}
+
+		s.unitOfWork.OnRollback(ctx, func(ctx context.Context) error {
+			return s.fileDomainService.DeleteIfOrphaned(ctx, storedFile.StorageKey)
+		})
+
+		markedUpload, err = s.uploadRepository.MarkCompleted(ctx, completedUpload.ID.String(), storedFile.ID)
+		if err != nil {
+			return fmt.Errorf("%w: %w", file.ErrorUploadComplete, err)
+		}
+
+		s.unitOfWork.OnCommit(ctx, func(ctx context.Context) error {
+			return s.deleteParts(ctx, completedUpload)
+		})
+
+		return nil
+	})
+	if errors.Is(err, file.ErrorFileInfected) {
+		_ = s.uploadRepository.Delete(ctx, completedUpload.ID.String())
+		_ = s.deleteParts(ctx, completedUpload)
+	}
+	if err != nil {
+		return file.Upload{}, err
+	}
+
+	return markedUpload, nil
+}
+
+func (s *uploadService) deleteParts(ctx context.Context, uploadEntity file.Upload) error {
+	parts, err := s.fileStorage.List(ctx, uploadEntity.PartPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to list upload parts: %w", err)
+	}
+
+	var errs []error
+	for _, part := range parts {
+		if err = s.fileStorage.Delete(ctx, part.Path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func uploadResponse(uploadEntity file.Upload) response.Upload {
+	res := response.Upload{
+		ID:        uploadEntity.ID.String(),
+		Offset:    uploadEntity.Offset,
+		Length:    uploadEntity.Length,
+		Metadata:  uploadEntity.Metadata,
+		ExpiresAt: uploadEntity.ExpiresAt,
+	}
+	if uploadEntity.FileID != nil {
+		res.FileID = uploadEntity.FileID.String()
+	}
+	return res
+}
+
+type countingWriter struct {
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	return len(p), nil
+}
+
+func getUploadMaxSize() int64 {
+	size, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_SIZE_MB"), 10, 64)
+	if err != nil || size <= 0 {
+		return file.DefaultMaxUploadSize
+	}
+	return size << 20
+}
+
+func getUploadExpiration() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("UPLOAD_EXPIRATION"))
+	if err != nil || duration <= 0 {
+		return file.DefaultUploadExpiration
+	}
+	return duration
+}
@@ -3,15 +3,17 @@ package service
 import (
 	"context"
 	"errors"
-	"time"
+	"fmt"
 
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
 	"github.com/samber/do/v2"
 
@@ -23,97 +25,143 @@ type (
 		Register(ctx context.Context, req request.UserRegister) (response.UserCreate, error)
 		GetAllUsersWithPagination(ctx context.Context, req pagination.Request) (pagination.ResponseWithData, error)
 		GetUserByID(ctx context.Context, userID string) (response.User, error)
+		// GetSessionsByUserIDs returns the active sessions of each user in
+		// userIDs, loaded in a single query.
+		GetSessionsByUserIDs(ctx context.Context, userIDs []string) (map[string][]response.UserSession, error)
 		GetUserByEmail(ctx context.Context, email string) (response.User, error)
-		Update(ctx context.Context, userID string, req request.UserUpdate) (response.UserUpdate, error)
-		Delete(ctx context.Context, userID string) error
+		// Update and Delete act on the user only while it is still at version,
+		// the version the client last read, or at any version when it is 0.
+		Update(ctx context.Context, userID string, version int64, req request.UserUpdate) (response.UserUpdate, error)
+		Delete(ctx context.Context, userID string, version int64) error
 		Verify(ctx context.Context, req request.UserLogin) (response.RefreshToken, error)
 		RefreshToken(ctx context.Context, req request.RefreshToken) (response.RefreshToken, error)
 		RevokeRefreshToken(ctx context.Context, userID string) error
+		UpdateAvatar(ctx context.Context, userID string, version int64, req request.UserAvatar) (response.User, error)
+		DeleteAvatar(ctx context.Context, userID string, version int64) (int64, error)
 	}
 
 	userService struct {
 		userRepository         user.Repository
 		refreshTokenRepository refresh_token.Repository
+		fileRepository         file.Repository
 		userDomainService      *user.Service
 		jwtService             JWTService
-		injector               do.Injector
+		unitOfWork             application.UnitOfWork
+		clock                  port.ClockPort
 	}
 )
 
 func NewUserService(injector do.Injector) UserService {
 	userRepository := do.MustInvoke[user.Repository](injector)
 	refreshTokenRepository := do.MustInvoke[refresh_token.Repository](injector)
+	fileRepository := do.MustInvoke[file.Repository](injector)
 	userDomainService := do.MustInvoke[*user.Service](injector)
 	jwtService := do.MustInvoke[JWTService](injector)
+	unitOfWork := do.MustInvoke[application.UnitOfWork](injector)
+	clock := do.MustInvoke[port.ClockPort](injector)
 	return &userService{
 		userRepository:         userRepository,
 		refreshTokenRepository: refreshTokenRepository,
+		fileRepository:         fileRepository,
 		userDomainService:      userDomainService,
 		jwtService:             jwtService,
-		injector:               injector,
+		unitOfWork:             unitOfWork,
+		clock:                  clock,
 	}
 }
 
 func (s *userService) Register(ctx context.Context, req request.UserRegister) (response.UserCreate, error) {
-	var filename string
+	var registeredUser user.User
 
-	_, flag, err := s.userRepository.CheckEmail(ctx, nil, req.Email)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return response.UserCreate{}, err
-	}
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		_, flag, err := s.userRepository.CheckEmail(ctx, req.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
 
-	if flag {
-		return response.UserCreate{}, user.ErrorEmailAlreadyExists
-	}
+		if flag {
+			return user.ErrorEmailAlreadyExists
+		}
 
-	if req.Image != nil {
-		filename, err = s.userDomainService.UploadImage(req.Image)
+		password, err := user.NewPassword(req.Password)
 		if err != nil {
-			return response.UserCreate{}, err
+			return err
+		}
+		role, err := user.NewRole(user.RoleUser)
+		if err != nil {
+			return err
 		}
-	}
 
-	password, err := user.NewPassword(req.Password)
-	if err != nil {
-		return response.UserCreate{}, err
-	}
-	role, err := user.NewRole(user.RoleUser)
-	if err != nil {
-		return response.UserCreate{}, err
-	}
-	imageUrl, err := shared.NewURL(filename)
+		userEntity := user.User{
+			Name:        req.Name,
+			Email:       req.Email,
+			PhoneNumber: req.PhoneNumber,
+			Password:    password,
+			Role:        role,
+			IsVerified:  false,
+		}
+
+		registeredUser, err = s.userRepository.Register(ctx, userEntity)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return user.ErrorEmailAlreadyExists
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", user.ErrorCreateUser, err)
+		}
+
+		// The image is stored once the user exists, since it is registered
+		// as one of their files.
+		if req.Image != nil {
+			filename, stored, err := s.userDomainService.UploadImage(ctx, registeredUser.ID, req.Image)
+			if err != nil {
+				return err
+			}
+
+			if stored {
+				s.unitOfWork.OnRollback(ctx, func(ctx context.Context) error {
+					return s.userDomainService.DeleteImage(ctx, filename)
+				})
+			}
+
+			imageUrl, err := shared.NewURL(filename)
+			if err != nil {
+				return err
+			}
+
+			registeredUser, err = s.userRepository.UpdateImageUrl(ctx, registeredUser.ID.String(), registeredUser.Version, imageUrl)
+			if err != nil {
+				return fmt.Errorf("%w: %w", user.ErrorCreateUser, err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		return response.UserCreate{}, err
 	}
 
-	userEntity := user.User{
-		Name:        req.Name,
-		Email:       req.Email,
-		PhoneNumber: req.PhoneNumber,
-		Password:    password,
-		Role:        role,
-		ImageUrl:    imageUrl,
-		IsVerified:  false,
-	}
-
-	registeredUser, err := s.userRepository.Register(ctx, nil, userEntity)
+	imageUrl, thumbnails, err := s.imageURLs(ctx, registeredUser)
 	if err != nil {
-		return response.UserCreate{}, user.ErrorCreateUser
+		return response.UserCreate{}, err
 	}
 
 	return response.UserCreate{
-		ID:          registeredUser.ID.String(),
-		Name:        registeredUser.Name,
-		Email:       registeredUser.Email,
-		PhoneNumber: registeredUser.PhoneNumber,
-		Role:        registeredUser.Role.Name,
-		ImageUrl:    registeredUser.ImageUrl.Path,
-		IsVerified:  registeredUser.IsVerified,
+		ID:              registeredUser.ID.String(),
+		Name:            registeredUser.Name,
+		Email:           registeredUser.Email,
+		PhoneNumber:     registeredUser.PhoneNumber,
+		Role:            registeredUser.Role.Name,
+		ImageUrl:        imageUrl,
+		ImageThumbnails: thumbnails,
+		IsVerified:      registeredUser.IsVerified,
 	}, nil
 }
 
 func (s *userService) GetAllUsersWithPagination(ctx context.Context, req pagination.Request) (pagination.ResponseWithData, error) {
-	retrievedData, err := s.userRepository.GetAllUsersWithPagination(ctx, nil, req)
+	retrievedData, err := s.userRepository.GetAllUsersWithPagination(ctx, req)
+	if domainError, ok := shared.AsError(err); ok && domainError.Category == shared.CategoryValidation {
+		return pagination.ResponseWithData{}, err
+	}
 	if err != nil {
 		return pagination.ResponseWithData{}, user.ErrorGetAllUsers
 	}
@@ -122,16 +170,22 @@ func (s *userService) GetAllUsersWithPagination(ctx context.Context, req paginat
 	for _, retrievedUser := range retrievedData.Data {
 		userEntity, ok := retrievedUser.(user.User)
 		if !ok {
-			return pagination.ResponseWithData{}, errors.New("failed to cast retrieved data to user.User")
+			return pagination.ResponseWithData{}, user.ErrorGetAllUsers
+		}
+		imageUrl, thumbnails, err := s.imageURLs(ctx, userEntity)
+		if err != nil {
+			return pagination.ResponseWithData{}, err
 		}
 		data = append(data, response.User{
-			ID:          userEntity.ID.String(),
-			Name:        userEntity.Name,
-			Email:       userEntity.Email,
-			PhoneNumber: userEntity.PhoneNumber,
-			Role:        userEntity.Role.Name,
-			ImageUrl:    userEntity.ImageUrl.Path,
-			IsVerified:  userEntity.IsVerified,
+			ID:              userEntity.ID.String(),
+			Name:            userEntity.Name,
+			Email:           userEntity.Email,
+			PhoneNumber:     userEntity.PhoneNumber,
+			Role:            userEntity.Role.Name,
+			ImageUrl:        imageUrl,
+			ImageThumbnails: thumbnails,
+			IsVerified:      userEntity.IsVerified,
+			Highlights:      searchHighlights(req.Search, map[string]string{"name": userEntity.Name, "email": userEntity.Email}),
 		})
 	}
 
@@ -143,56 +197,114 @@ func (s *userService) GetAllUsersWithPagination(ctx context.Context, req paginat
 }
 
 func (s *userService) GetUserByID(ctx context.Context, userID string) (response.User, error) {
-	retrievedUser, err := s.userRepository.GetUserByID(ctx, nil, userID)
+	retrievedUser, err := s.userRepository.GetUserByID(ctx, userID)
 	if err != nil {
-		return response.User{}, user.ErrorGetUserById
+		return response.User{}, userLookupError(err)
+	}
+
+	imageUrl, thumbnails, err := s.imageURLs(ctx, retrievedUser)
+	if err != nil {
+		return response.User{}, err
 	}
 
 	return response.User{
-		ID:          retrievedUser.ID.String(),
-		Name:        retrievedUser.Name,
-		Email:       retrievedUser.Email,
-		PhoneNumber: retrievedUser.PhoneNumber,
-		Role:        retrievedUser.Role.Name,
-		ImageUrl:    retrievedUser.ImageUrl.Path,
-		IsVerified:  retrievedUser.IsVerified,
+		ID:              retrievedUser.ID.String(),
+		Name:            retrievedUser.Name,
+		Email:           retrievedUser.Email,
+		PhoneNumber:     retrievedUser.PhoneNumber,
+		Role:            retrievedUser.Role.Name,
+		ImageUrl:        imageUrl,
+		ImageThumbnails: thumbnails,
+		IsVerified:      retrievedUser.IsVerified,
+		Version:         retrievedUser.Version,
 	}, nil
 }
 
+func (s *userService) GetSessionsByUserIDs(ctx context.Context, userIDs []string) (map[string][]response.UserSession, error) {
+	tokens, err := s.refreshTokenRepository.FindActiveByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, user.ErrorGetUserSessions
+	}
+
+	sessions := make(map[string][]response.UserSession, len(userIDs))
+	for _, userID := range userIDs {
+		sessions[userID] = []response.UserSession{}
+	}
+	for _, token := range tokens {
+		userID := token.UserID.String()
+		sessions[userID] = append(sessions[userID], response.UserSession{
+			ID:        token.ID.String(),
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
 func (s *userService) GetUserByEmail(ctx context.Context, email string) (response.User, error) {
-	retrievedUser, err := s.userRepository.GetUserByEmail(ctx, nil, email)
+	retrievedUser, err := s.userRepository.GetUserByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.User{}, user.ErrorUserNotFound
+		}
 		return response.User{}, user.ErrorGetUserByEmail
 	}
 
+	imageUrl, thumbnails, err := s.imageURLs(ctx, retrievedUser)
+	if err != nil {
+		return response.User{}, err
+	}
+
 	return response.User{
-		ID:          retrievedUser.ID.String(),
-		Name:        retrievedUser.Name,
-		Email:       retrievedUser.Email,
-		PhoneNumber: retrievedUser.PhoneNumber,
-		Role:        retrievedUser.Role.Name,
-		ImageUrl:    retrievedUser.ImageUrl.Path,
-		IsVerified:  retrievedUser.IsVerified,
+		ID:              retrievedUser.ID.String(),
+		Name:            retrievedUser.Name,
+		Email:           retrievedUser.Email,
+		PhoneNumber:     retrievedUser.PhoneNumber,
+		Role:            retrievedUser.Role.Name,
+		ImageUrl:        imageUrl,
+		ImageThumbnails: thumbnails,
+		IsVerified:      retrievedUser.IsVerified,
+		Version:         retrievedUser.Version,
 	}, nil
 }
 
-func (s *userService) Update(ctx context.Context, userID string, req request.UserUpdate) (response.UserUpdate, error) {
-	retrievedUser, err := s.userRepository.GetUserByID(ctx, nil, userID)
-	if err != nil {
-		return response.UserUpdate{}, user.ErrorUserNotFound
-	}
+func (s *userService) Update(ctx context.Context, userID string, version int64, req request.UserUpdate) (response.UserUpdate, error) {
+	var updatedUser user.User
 
-	userEntity := user.User{
-		ID:          retrievedUser.ID,
-		Name:        req.Name,
-		Email:       req.Email,
-		PhoneNumber: req.PhoneNumber,
-		Role:        retrievedUser.Role,
-	}
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedUser, err := s.userRepository.GetUserByID(ctx, userID)
+		if err != nil {
+			return userLookupError(err)
+		}
 
-	updatedUser, err := s.userRepository.Update(ctx, nil, userEntity)
+		expectedVersion := version
+		if expectedVersion == 0 {
+			expectedVersion = retrievedUser.Version
+		}
+		userEntity := user.User{
+			ID:          retrievedUser.ID,
+			Name:        req.Name,
+			Email:       req.Email,
+			PhoneNumber: req.PhoneNumber,
+			Role:        retrievedUser.Role,
+			Version:     expectedVersion,
+		}
+
+		updatedUser, err = s.userRepository.Update(ctx, userEntity)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return user.ErrorEmailAlreadyExists
+		}
+		if errors.Is(err, shared.ErrorVersionConflict) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", user.ErrorUpdateUser, err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return response.UserUpdate{}, user.ErrorUpdateUser
+		return response.UserUpdate{}, err
 	}
 
 	return response.UserUpdate{
@@ -202,126 +314,263 @@ func (s *userService) Update(ctx context.Context, userID string, req request.Use
 		PhoneNumber: updatedUser.PhoneNumber,
 		Role:        updatedUser.Role.Name,
 		IsVerified:  updatedUser.IsVerified,
+		Version:     updatedUser.Version,
 	}, nil
 }
 
-func (s *userService) Delete(ctx context.Context, userID string) error {
-	transactionRepository := do.MustInvoke[*transaction.Repository](s.injector)
+func (s *userService) Delete(ctx context.Context, userID string, version int64) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedUser, err := s.userRepository.GetUserByID(ctx, userID)
+		if err != nil {
+			return userLookupError(err)
+		}
 
-	tx, err := transactionRepository.Begin(ctx)
-	if err != nil {
-		return err
-	}
+		expectedVersion := version
+		if expectedVersion == 0 {
+			expectedVersion = retrievedUser.Version
+		}
+		err = s.userRepository.Delete(ctx, retrievedUser.ID.String(), expectedVersion)
+		if errors.Is(err, shared.ErrorVersionConflict) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", user.ErrorDeleteUser, err)
+		}
+
+		return nil
+	})
+}
+
+func (s *userService) Verify(ctx context.Context, req request.UserLogin) (response.RefreshToken, error) {
+	var result response.RefreshToken
 
-	defer func() {
-		if r := recover(); r != nil {
-			err = application.RecoveredFromPanic(r)
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedUser, err := s.userRepository.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return user.ErrorEmailNotFound
+			}
+			return fmt.Errorf("%w: %w", user.ErrorGetUserByEmail, err)
 		}
-		transactionRepository.CommitOrRollback(ctx, tx, err)
-	}()
 
-	retrievedUser, err := s.userRepository.GetUserByID(ctx, tx, userID)
-	if err != nil {
-		return user.ErrorUserNotFound
-	}
+		checkPassword, err := retrievedUser.Password.IsPasswordMatch([]byte(req.Password))
+		if err != nil || !checkPassword {
+			return refresh_token.ErrorPasswordNotMatch
+		}
 
-	err = s.userRepository.Delete(ctx, tx, retrievedUser.ID.String())
+		result, err = s.issueTokens(ctx, retrievedUser)
+		return err
+	})
 	if err != nil {
-		return user.ErrorDeleteUser
+		return response.RefreshToken{}, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (s *userService) Verify(ctx context.Context, req request.UserLogin) (response.RefreshToken, error) {
-	transactionRepository := do.MustInvoke[*transaction.Repository](s.injector)
+func (s *userService) RefreshToken(ctx context.Context, req request.RefreshToken) (response.RefreshToken, error) {
+	var result response.RefreshToken
+
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedRefreshToken, err := s.refreshTokenRepository.FindByUserID(ctx, req.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return refresh_token.ErrorThisUserRefreshTokenNotFound
+			}
+			return err
+		}
+
+		if !refresh_token.IsRefreshTokenMatch(req.RefreshToken, retrievedRefreshToken.Token) {
+			return user.ErrorTokenInvalid
+		}
 
-	tx, err := transactionRepository.Begin(ctx)
+		if s.clock.Now().After(retrievedRefreshToken.ExpiresAt) {
+			return user.ErrorTokenExpired
+		}
+
+		retrievedUser, err := s.userRepository.GetUserByID(ctx, retrievedRefreshToken.UserID.String())
+		if err != nil {
+			return userLookupError(err)
+		}
+
+		result, err = s.issueTokens(ctx, retrievedUser)
+		return err
+	})
 	if err != nil {
 		return response.RefreshToken{}, err
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			err = application.RecoveredFromPanic(r)
+	return result, nil
+}
+
+func (s *userService) RevokeRefreshToken(ctx context.Context, userID string) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetUserByID(ctx, userID); err != nil {
+			return userLookupError(err)
+		}
+
+		return s.refreshTokenRepository.DeleteByUserID(ctx, userID)
+	})
+}
+
+func (s *userService) UpdateAvatar(ctx context.Context, userID string, version int64, req request.UserAvatar) (response.User, error) {
+	var updatedUser user.User
+
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedUser, err := s.userRepository.GetUserByID(ctx, userID)
+		if err != nil {
+			return userLookupError(err)
+		}
+
+		expectedVersion := version
+		if expectedVersion == 0 {
+			expectedVersion = retrievedUser.Version
+		}
+		if retrievedUser.Version != expectedVersion {
+			return shared.ErrorVersionConflict
+		}
+
+		filename, stored, err := s.uploadAvatar(ctx, retrievedUser.ID, req)
+		if err != nil {
+			return err
+		}
+
+		if stored {
+			s.unitOfWork.OnRollback(ctx, func(ctx context.Context) error {
+				return s.userDomainService.DeleteImage(ctx, filename)
+			})
+		}
+
+		updatedUser, err = s.userRepository.UpdateImageUrl(ctx, userID, expectedVersion, shared.NewURLFromTable(filename))
+		if errors.Is(err, shared.ErrorVersionConflict) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", user.ErrorUpdateUser, err)
 		}
-		transactionRepository.CommitOrRollback(ctx, tx, err)
-	}()
 
-	retrievedUser, err := s.userRepository.GetUserByEmail(ctx, tx, req.Email)
+		return s.releaseImage(ctx, retrievedUser)
+	})
 	if err != nil {
-		return response.RefreshToken{}, user.ErrorEmailNotFound
+		return response.User{}, err
 	}
 
-	checkPassword, err := retrievedUser.Password.IsPasswordMatch([]byte(req.Password))
-	if err != nil || !checkPassword {
-		return response.RefreshToken{}, refresh_token.ErrorPasswordNotMatch
+	imageUrl, thumbnails, err := s.imageURLs(ctx, updatedUser)
+	if err != nil {
+		return response.User{}, err
 	}
 
-	accessToken := s.jwtService.GenerateAccessToken(retrievedUser.ID.String(), retrievedUser.Role.Name)
+	return response.User{
+		ID:              updatedUser.ID.String(),
+		Name:            updatedUser.Name,
+		Email:           updatedUser.Email,
+		PhoneNumber:     updatedUser.PhoneNumber,
+		Role:            updatedUser.Role.Name,
+		ImageUrl:        imageUrl,
+		ImageThumbnails: thumbnails,
+		IsVerified:      updatedUser.IsVerified,
+		Version:         updatedUser.Version,
+	}, nil
+}
 
-	refreshTokenString, expiresAt := s.jwtService.GenerateRefreshToken()
+// DeleteAvatar removes the avatar of the user and returns the version the
+// user moved to.
+func (s *userService) DeleteAvatar(ctx context.Context, userID string, version int64) (int64, error) {
+	var updatedUser user.User
 
-	hashedToken, err := refresh_token.HashToken(refreshTokenString)
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		retrievedUser, err := s.userRepository.GetUserByID(ctx, userID)
+		if err != nil {
+			return userLookupError(err)
+		}
+
+		expectedVersion := version
+		if expectedVersion == 0 {
+			expectedVersion = retrievedUser.Version
+		}
+		if retrievedUser.Version != expectedVersion {
+			return shared.ErrorVersionConflict
+		}
+		if retrievedUser.ImageUrl.Path == "" {
+			return user.ErrorAvatarNotFound
+		}
+
+		updatedUser, err = s.userRepository.UpdateImageUrl(ctx, userID, expectedVersion, shared.URL{})
+		if errors.Is(err, shared.ErrorVersionConflict) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", user.ErrorUpdateUser, err)
+		}
+
+		return s.releaseImage(ctx, retrievedUser)
+	})
 	if err != nil {
-		return response.RefreshToken{}, err
+		return 0, err
 	}
 
-	if err = s.refreshTokenRepository.DeleteByUserID(ctx, tx, retrievedUser.ID.String()); err != nil {
-		return response.RefreshToken{}, err
-	}
+	return updatedUser.Version, nil
+}
 
-	refreshTokenEntity := refresh_token.RefreshToken{
-		UserID:    retrievedUser.ID,
-		Token:     hashedToken,
-		ExpiresAt: expiresAt,
+// uploadAvatar stores the avatar sent with the request or, when req.FileID is
+// set, one previously uploaded by the same user.
+func (s *userService) uploadAvatar(ctx context.Context, ownerID identity.ID, req request.UserAvatar) (string, bool, error) {
+	if req.FileID == "" {
+		return s.userDomainService.UploadImage(ctx, ownerID, req.Image)
 	}
 
-	if _, err = s.refreshTokenRepository.Create(ctx, tx, refreshTokenEntity); err != nil {
-		return response.RefreshToken{}, err
+	fileEntity, err := s.fileRepository.GetFileByID(ctx, req.FileID)
+	if err != nil || fileEntity.OwnerID != ownerID {
+		return "", false, file.ErrorFileNotFound
 	}
 
-	return response.RefreshToken{
-		AccessToken:  accessToken,
-		RefreshToken: refreshTokenString,
-		Role:         retrievedUser.Role.Name,
-	}, nil
+	return s.userDomainService.UploadImageFromFile(ctx, ownerID, fileEntity)
 }
 
-func (s *userService) RefreshToken(ctx context.Context, req request.RefreshToken) (response.RefreshToken, error) {
-	transactionRepository := do.MustInvoke[*transaction.Repository](s.injector)
+// releaseImage drops the user's references to their previous image, which the
+// cleanup job removes once nothing else uses it. Images stored before files
+// were registered are deleted as soon as the transaction commits.
+func (s *userService) releaseImage(ctx context.Context, userEntity user.User) error {
+	oldFilename := userEntity.ImageUrl.Path
 
-	tx, err := transactionRepository.Begin(ctx)
+	legacy, err := s.userDomainService.ReleaseImage(ctx, userEntity.ID, oldFilename)
 	if err != nil {
-		return response.RefreshToken{}, err
+		return err
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			err = application.RecoveredFromPanic(r)
-		}
-		transactionRepository.CommitOrRollback(ctx, tx, err)
-	}()
-
-	retrievedRefreshToken, err := s.refreshTokenRepository.FindByUserID(ctx, tx, req.UserID)
-	if err != nil {
-		return response.RefreshToken{}, refresh_token.ErrorThisUserRefreshTokenNotFound
+	if legacy {
+		s.unitOfWork.OnCommit(ctx, func(ctx context.Context) error {
+			return s.userDomainService.DeleteImage(ctx, oldFilename)
+		})
 	}
 
-	if !refresh_token.IsRefreshTokenMatch(req.RefreshToken, retrievedRefreshToken.Token) {
-		return response.RefreshToken{}, user.ErrorTokenInvalid
+	return nil
+}
+
+// userLookupError tells a missing user apart from a failed lookup.
+func userLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.ErrorUserNotFound
 	}
+	return fmt.Errorf("%w: %w", user.ErrorGetUserById, err)
+}
 
-	if time.Now().After(retrievedRefreshToken.ExpiresAt) {
-		return response.RefreshToken{}, user.ErrorTokenExpired
+func (s *userService) imageURLs(ctx context.Context, userEntity user.User) (string, map[string]string, error) {
+	imageUrl, err := s.userDomainService.ImageURL(ctx, userEntity.ImageUrl.Path)
+	if err != nil {
+		return "", nil, err
 	}
 
-	retrievedUser, err := s.userRepository.GetUserByID(ctx, tx, retrievedRefreshToken.UserID.String())
+	thumbnails, err := s.userDomainService.ThumbnailURLs(ctx, userEntity.ImageUrl.Path)
 	if err != nil {
-		return response.RefreshToken{}, user.ErrorUserNotFound
+		return "", nil, err
 	}
 
-	accessToken := s.jwtService.GenerateAccessToken(retrievedUser.ID.String(), retrievedUser.Role.Name)
+	return imageUrl, thumbnails, nil
+}
+
+func (s *userService) issueTokens(ctx context.Context, userEntity user.User) (response.RefreshToken, error) {
+	accessToken := s.jwtService.GenerateAccessToken(userEntity.ID.String(), userEntity.Role.Name)
 
 	refreshTokenString, expiresAt := s.jwtService.GenerateRefreshToken()
 
@@ -330,50 +579,38 @@ func (s *userService) RefreshToken(ctx context.Context, req request.RefreshToken
 		return response.RefreshToken{}, err
 	}
 
-	if err = s.refreshTokenRepository.DeleteByUserID(ctx, tx, retrievedUser.ID.String()); err != nil {
+	if err = s.refreshTokenRepository.DeleteByUserID(ctx, userEntity.ID.String()); err != nil {
 		return response.RefreshToken{}, err
 	}
 
 	refreshTokenEntity := refresh_token.RefreshToken{
-		UserID:    retrievedUser.ID,
+		UserID:    userEntity.ID,
 		Token:     hashedToken,
 		ExpiresAt: expiresAt,
 	}
 
-	if _, err = s.refreshTokenRepository.Create(ctx, tx, refreshTokenEntity); err != nil {
+	if _, err = s.refreshTokenRepository.Create(ctx, refreshTokenEntity); err != nil {
 		return response.RefreshToken{}, err
 	}
 
 	return response.RefreshToken{
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenString,
-		Role:         retrievedUser.Role.Name,
+		Role:         userEntity.Role.Name,
 	}, nil
 }
 
-func (s *userService) RevokeRefreshToken(ctx context.Context, userID string) error {
-	transactionRepository := do.MustInvoke[*transaction.Repository](s.injector)
-
-	tx, err := transactionRepository.Begin(ctx)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			err = application.RecoveredFromPanic(r)
+// searchHighlights highlights term in the fields it occurs in, or returns nil
+// when nothing was searched.
+func searchHighlights(term string, fields map[string]string) map[string]string {
+	highlights := make(map[string]string)
+	for name, value := range fields {
+		if highlighted, ok := pagination.Highlight(value, term); ok {
+			highlights[name] = highlighted
 		}
-		transactionRepository.CommitOrRollback(ctx, tx, err)
-	}()
-
-	_, err = s.userRepository.GetUserByID(ctx, tx, userID)
-	if err != nil {
-		return user.ErrorUserNotFound
 	}
-
-	if err = s.refreshTokenRepository.DeleteByUserID(ctx, tx, userID); err != nil {
-		return err
+	if len(highlights) == 0 {
+		return nil
 	}
-
-	return nil
+	return highlights
 }
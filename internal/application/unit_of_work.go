@@ -0,0 +1,19 @@
+package application
+
+import "context"
+
+type (
+	Hook func(ctx context.Context) error
+
+	// UnitOfWork runs fn inside a transaction carried by the context passed to fn.
+	// Nested calls reuse the outer transaction through savepoints.
+	UnitOfWork interface {
+		Do(ctx context.Context, fn func(ctx context.Context) error) error
+		// OnCommit runs hook once the outermost transaction commits, or right away
+		// when ctx carries no transaction.
+		OnCommit(ctx context.Context, hook Hook)
+		// OnRollback runs hook when the transaction, or the savepoint it was
+		// registered in, is rolled back.
+		OnRollback(ctx context.Context, hook Hook)
+	}
+)
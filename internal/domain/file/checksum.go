@@ -0,0 +1,61 @@
+package file
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"strings"
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+var ChecksumAlgorithms = []string{"md5", "sha1", "sha256"}
+
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+	hash      hash.Hash
+}
+
+// NewChecksum parses an Upload-Checksum value: the algorithm name, a space and
+// the base64 encoded digest.
+func NewChecksum(value string) (*Checksum, error) {
+	algorithm, encoded, ok := strings.Cut(value, " ")
+	if !ok {
+		return nil, ErrorChecksumInvalid
+	}
+
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return nil, ErrorChecksumAlgorithm
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrorChecksumInvalid
+	}
+
+	return &Checksum{
+		Algorithm: algorithm,
+		Sum:       sum,
+		hash:      newHash(),
+	}, nil
+}
+
+func (c *Checksum) Write(p []byte) (int, error) {
+	return c.hash.Write(p)
+}
+
+func (c *Checksum) Verify() error {
+	if !bytes.Equal(c.hash.Sum(nil), c.Sum) {
+		return ErrorChecksumMismatch
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package file
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+)
+
+// File is a stored object registered to an owner. RefCount counts the records
+// that use it; a file nothing refers to is removed by the cleanup job.
+type File struct {
+	ID          identity.ID
+	OwnerID     identity.ID
+	StorageKey  string
+	Name        string
+	Size        int64
+	ContentType string
+	SHA256      string
+	RefCount    int64
+	shared.Timestamp
+}
+
+// StorageKey addresses content by its SHA-256 digest so identical files share
+// a single stored object.
+func StorageKey(sha256 string) string {
+	return "files/" + sha256
+}
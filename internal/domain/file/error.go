@@ -0,0 +1,31 @@
+package file
+
+import "github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+var (
+	ErrorFileNotFound     = shared.NewError(shared.CategoryNotFound, "file_not_found", "file not found")
+	ErrorInvalidPath      = shared.NewError(shared.CategoryValidation, "file_path_invalid", "invalid file path")
+	ErrorSignatureInvalid = shared.NewError(shared.CategoryForbidden, "signature_invalid", "signature invalid")
+	ErrorSignatureExpired = shared.NewError(shared.CategoryForbidden, "signature_expired", "signature expired")
+	ErrorDownloadFile     = shared.NewError(shared.CategoryInternal, "file_download_failed", "failed to download file")
+	ErrorCreateFile       = shared.NewError(shared.CategoryInternal, "file_create_failed", "failed to create file")
+	ErrorStoreFile        = shared.NewError(shared.CategoryInternal, "file_store_failed", "failed to store file")
+	ErrorQuotaExceeded    = shared.NewError(shared.CategoryTooLarge, "quota_exceeded", "storage quota exceeded")
+	ErrorFileInfected     = shared.NewError(shared.CategoryUnprocessable, "file_infected", "file is infected")
+	ErrorScanFile         = shared.NewError(shared.CategoryUnavailable, "file_scan_failed", "failed to scan file")
+
+	ErrorCreateUpload         = shared.NewError(shared.CategoryInternal, "upload_create_failed", "failed to create upload")
+	ErrorUploadNotFound       = shared.NewError(shared.CategoryNotFound, "upload_not_found", "upload not found")
+	ErrorUploadExpired        = shared.NewError(shared.CategoryGone, "upload_expired", "upload expired")
+	ErrorUploadLengthInvalid  = shared.NewError(shared.CategoryValidation, "upload_length_invalid", "upload length must be a non-negative integer")
+	ErrorUploadTooLarge       = shared.NewError(shared.CategoryTooLarge, "upload_too_large", "upload exceeds the maximum size")
+	ErrorUploadMetadata       = shared.NewError(shared.CategoryValidation, "upload_metadata_invalid", "upload metadata is malformed")
+	ErrorUploadOffsetInvalid  = shared.NewError(shared.CategoryValidation, "upload_offset_invalid", "Upload-Offset must be a non-negative integer")
+	ErrorUploadOffsetMismatch = shared.NewError(shared.CategoryConflict, "upload_offset_mismatch", "upload offset does not match")
+	ErrorUploadContentType    = shared.NewError(shared.CategoryValidation, "upload_content_type_invalid", "Content-Type must be "+TusContentType)
+	ErrorUploadChunk          = shared.NewError(shared.CategoryInternal, "upload_chunk_failed", "failed to store upload chunk")
+	ErrorUploadComplete       = shared.NewError(shared.CategoryInternal, "upload_complete_failed", "failed to complete upload")
+	ErrorChecksumInvalid      = shared.NewError(shared.CategoryValidation, "checksum_invalid", "upload checksum is malformed")
+	ErrorChecksumAlgorithm    = shared.NewError(shared.CategoryValidation, "checksum_algorithm_unsupported", "upload checksum algorithm is not supported")
+	ErrorChecksumMismatch     = shared.NewError(shared.CategoryValidation, "checksum_mismatch", "upload checksum mismatch")
+)
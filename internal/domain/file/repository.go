@@ -0,0 +1,40 @@
+package file
+
+import (
+	"context"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+)
+
+type (
+	Repository interface {
+		Create(ctx context.Context, fileEntity File) (File, error)
+		GetFileByID(ctx context.Context, id string) (File, error)
+		GetFileByStorageKey(ctx context.Context, ownerID string, storageKey string) (File, error)
+		FindByHash(ctx context.Context, ownerID string, sha256 string) ([]File, error)
+		// AddReferences adjusts the reference count of a file by delta.
+		AddReferences(ctx context.Context, id string, delta int64) (File, error)
+		SumSizeByOwner(ctx context.Context, ownerID string) (int64, error)
+		CountByStorageKey(ctx context.Context, storageKey string) (int64, error)
+		// FindUnreferenced returns files without references that have not
+		// changed since before.
+		FindUnreferenced(ctx context.Context, before time.Time) ([]File, error)
+		// DeleteUnreferenced deletes a file unless it has gained a reference,
+		// in which case it reports ErrorFileNotFound. Like every method here,
+		// it reports a missing file as ErrorFileNotFound.
+		DeleteUnreferenced(ctx context.Context, id string) error
+	}
+
+	UploadRepository interface {
+		Create(ctx context.Context, uploadEntity Upload) (Upload, error)
+		GetUploadByID(ctx context.Context, id string) (Upload, error)
+		// AdvanceOffset moves the offset from one value to another and accepts
+		// the part stored for that range. It reports gorm.ErrRecordNotFound
+		// when the stored offset is no longer from.
+		AdvanceOffset(ctx context.Context, id string, from int64, to int64, partKey string) (Upload, error)
+		MarkCompleted(ctx context.Context, id string, fileID identity.ID) (Upload, error)
+		Delete(ctx context.Context, id string) error
+		FindExpired(ctx context.Context, now time.Time) ([]Upload, error)
+	}
+)
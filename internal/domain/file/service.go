@@ -0,0 +1,272 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/samber/do/v2"
+)
+
+const (
+	DefaultQuota = 1 << 30
+	// CleanupGracePeriod is how long a file stays unreferenced before the
+	// cleanup job may delete it, which leaves room for it to be reused.
+	CleanupGracePeriod = time.Hour
+)
+
+// Service keeps the files registry in step with FileStoragePort. Every file it
+// stores is scanned first and recorded with its digest so identical content is
+// stored once, and counted against its owner's quota.
+type Service struct {
+	repository  Repository
+	fileStorage port.FileStoragePort
+	fileScanner port.FileScannerPort
+	clock       port.ClockPort
+	quota       int64
+}
+
+// Quarantined is content held in a temporary file, outside of storage, that
+// has been scanned and found clean. Close removes it.
+type Quarantined struct {
+	spool  *os.File
+	Size   int64
+	SHA256 string
+}
+
+func NewService(injector do.Injector) *Service {
+	repository := do.MustInvoke[Repository](injector)
+	fileStorage := do.MustInvoke[port.FileStoragePort](injector)
+	fileScanner := do.MustInvoke[port.FileScannerPort](injector)
+	clock := do.MustInvoke[port.ClockPort](injector)
+	return &Service{
+		repository:  repository,
+		fileStorage: fileStorage,
+		fileScanner: fileScanner,
+		clock:       clock,
+		quota:       getQuota(),
+	}
+}
+
+func (s *Service) Quota() int64 {
+	return s.quota
+}
+
+func (s *Service) CheckQuota(ctx context.Context, ownerID string, size int64) error {
+	usage, err := s.repository.SumSizeByOwner(ctx, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to compute storage usage: %w", err)
+	}
+	if usage+size > s.quota {
+		return ErrorQuotaExceeded
+	}
+	return nil
+}
+
+// Store records content for ownerID under a key derived from its digest. When
+// the owner already has identical content, that file gains a reference instead
+// of being stored again. The returned file holds one reference for the caller.
+func (s *Service) Store(ctx context.Context, ownerID identity.ID, name string, content io.Reader) (File, error) {
+	quarantined, err := s.Quarantine(ctx, content)
+	if err != nil {
+		return File{}, err
+	}
+	defer quarantined.Close()
+
+	if existing, ok, err := s.reuse(ctx, ownerID, quarantined.SHA256); err != nil || ok {
+		return existing, err
+	}
+
+	head := make([]byte, 512)
+	n, _ := quarantined.spool.ReadAt(head, 0)
+
+	return s.register(ctx, File{
+		OwnerID:     ownerID,
+		StorageKey:  StorageKey(quarantined.SHA256),
+		Name:        name,
+		Size:        quarantined.Size,
+		ContentType: http.DetectContentType(head[:n]),
+		SHA256:      quarantined.SHA256,
+	}, quarantined.Reader())
+}
+
+// Quarantine copies content to a temporary file and scans it there, so nothing
+// reaches storage before it is known to be clean. Infected content is
+// discarded and reported as ErrorFileInfected.
+func (s *Service) Quarantine(ctx context.Context, content io.Reader) (*Quarantined, error) {
+	spool, err := os.CreateTemp("", "quarantine-*")
+	if err != nil {
+		return nil, err
+	}
+	quarantined := &Quarantined{spool: spool}
+
+	hash := sha256.New()
+	if quarantined.Size, err = io.Copy(io.MultiWriter(spool, hash), content); err != nil {
+		_ = quarantined.Close()
+		return nil, err
+	}
+	quarantined.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	result, err := s.fileScanner.Scan(ctx, quarantined.Reader())
+	if err != nil {
+		_ = quarantined.Close()
+		return nil, fmt.Errorf("%w: %v", ErrorScanFile, err)
+	}
+	if result.Infected {
+		_ = quarantined.Close()
+		return nil, fmt.Errorf("%w: %s", ErrorFileInfected, result.Signature)
+	}
+
+	return quarantined, nil
+}
+
+// StoreAt records content at fileEntity.StorageKey, for callers that need to
+// choose the key themselves. It does not look for duplicates; use Duplicates
+// first when the content may already be stored.
+func (s *Service) StoreAt(ctx context.Context, fileEntity File, content []byte) (File, error) {
+	fileEntity.Size = int64(len(content))
+	fileEntity.SHA256 = Digest(content)
+	return s.register(ctx, fileEntity, bytes.NewReader(content))
+}
+
+// Duplicates returns the files of ownerID with exactly this content.
+func (s *Service) Duplicates(ctx context.Context, ownerID identity.ID, content []byte) ([]File, error) {
+	return s.repository.FindByHash(ctx, ownerID.String(), Digest(content))
+}
+
+func (s *Service) Acquire(ctx context.Context, id string) (File, error) {
+	return s.repository.AddReferences(ctx, id, 1)
+}
+
+func (s *Service) Release(ctx context.Context, id string) error {
+	_, err := s.repository.AddReferences(ctx, id, -1)
+	return err
+}
+
+func (s *Service) AcquireByStorageKey(ctx context.Context, ownerID identity.ID, storageKey string) (File, error) {
+	fileEntity, err := s.repository.GetFileByStorageKey(ctx, ownerID.String(), storageKey)
+	if err != nil {
+		return File{}, ErrorFileNotFound
+	}
+	return s.Acquire(ctx, fileEntity.ID.String())
+}
+
+// ReleaseByStorageKey reports ErrorFileNotFound for objects that were stored
+// without being registered.
+func (s *Service) ReleaseByStorageKey(ctx context.Context, ownerID identity.ID, storageKey string) error {
+	fileEntity, err := s.repository.GetFileByStorageKey(ctx, ownerID.String(), storageKey)
+	if err != nil {
+		return ErrorFileNotFound
+	}
+	return s.Release(ctx, fileEntity.ID.String())
+}
+
+// DeleteIfOrphaned removes the object at storageKey once no file refers to it.
+func (s *Service) DeleteIfOrphaned(ctx context.Context, storageKey string) error {
+	count, err := s.repository.CountByStorageKey(ctx, storageKey)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.fileStorage.Delete(ctx, storageKey)
+}
+
+// DeleteUnreferenced removes files that have gone without references for
+// CleanupGracePeriod, along with their objects once nothing else shares them,
+// and returns how many were removed.
+func (s *Service) DeleteUnreferenced(ctx context.Context) (int, error) {
+	unreferenced, err := s.repository.FindUnreferenced(ctx, s.clock.Now().Add(-CleanupGracePeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	deleted := 0
+	for _, fileEntity := range unreferenced {
+		if err = s.repository.DeleteUnreferenced(ctx, fileEntity.ID.String()); err != nil {
+			if !errors.Is(err, ErrorFileNotFound) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		deleted++
+
+		if err = s.DeleteIfOrphaned(ctx, fileEntity.StorageKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+func (s *Service) reuse(ctx context.Context, ownerID identity.ID, digest string) (File, bool, error) {
+	duplicates, err := s.repository.FindByHash(ctx, ownerID.String(), digest)
+	if err != nil || len(duplicates) == 0 {
+		return File{}, false, err
+	}
+
+	acquired, err := s.Acquire(ctx, duplicates[0].ID.String())
+	return acquired, err == nil, err
+}
+
+func (s *Service) register(ctx context.Context, fileEntity File, content io.Reader) (File, error) {
+	if err := s.CheckQuota(ctx, fileEntity.OwnerID.String(), fileEntity.Size); err != nil {
+		return File{}, err
+	}
+
+	count, err := s.repository.CountByStorageKey(ctx, fileEntity.StorageKey)
+	if err != nil {
+		return File{}, err
+	}
+	if count == 0 {
+		if err = s.fileStorage.Put(ctx, fileEntity.StorageKey, content, fileEntity.Size, fileEntity.ContentType); err != nil {
+			// The upload may have failed because ctx was cancelled.
+			_ = s.fileStorage.Delete(context.WithoutCancel(ctx), fileEntity.StorageKey)
+			return File{}, fmt.Errorf("%w: %v", ErrorStoreFile, err)
+		}
+	}
+
+	fileEntity.RefCount = 1
+	created, err := s.repository.Create(ctx, fileEntity)
+	if err != nil {
+		_ = s.DeleteIfOrphaned(ctx, fileEntity.StorageKey)
+		return File{}, ErrorCreateFile
+	}
+
+	return created, nil
+}
+
+// Reader returns a reader over the whole quarantined content, independent of
+// any other reader returned before.
+func (q *Quarantined) Reader() io.Reader {
+	return io.NewSectionReader(q.spool, 0, q.Size)
+}
+
+func (q *Quarantined) Close() error {
+	return errors.Join(q.spool.Close(), os.Remove(q.spool.Name()))
+}
+
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func getQuota() int64 {
+	quota, err := strconv.ParseInt(os.Getenv("FILE_QUOTA_MB"), 10, 64)
+	if err != nil || quota <= 0 {
+		return DefaultQuota
+	}
+	return quota << 20
+}
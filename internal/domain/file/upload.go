@@ -0,0 +1,101 @@
+package file
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+)
+
+const (
+	DefaultMaxUploadSize    = 1 << 30
+	DefaultUploadExpiration = 24 * time.Hour
+
+	// TusContentType is the only content type a chunk may be sent with.
+	TusContentType = "application/offset+octet-stream"
+)
+
+// Upload tracks a resumable upload. Every chunk is stored as its own part
+// keyed by the offset it starts at and the attempt that sent it; Parts lists
+// the keys of the accepted ones, which are joined into a File once Offset
+// reaches Length.
+type Upload struct {
+	ID        identity.ID
+	OwnerID   identity.ID
+	Length    int64
+	Offset    int64
+	Metadata  map[string]string
+	Parts     []string
+	ExpiresAt time.Time
+	FileID    *identity.ID
+	shared.Timestamp
+}
+
+func (u Upload) IsComplete() bool {
+	return u.Offset == u.Length
+}
+
+func (u Upload) IsExpired(now time.Time) bool {
+	return !u.IsComplete() && now.After(u.ExpiresAt)
+}
+
+func (u Upload) PartPrefix() string {
+	return fmt.Sprintf("uploads/%s/", u.ID.String())
+}
+
+// PartKey names the part an attempt stores at offset. Concurrent attempts at
+// the same offset never share an object, so the one that loses the race can
+// discard its part without touching the accepted one.
+func (u Upload) PartKey(offset int64, attempt string) string {
+	return fmt.Sprintf("%s%020d-%s", u.PartPrefix(), offset, attempt)
+}
+
+// ParseMetadata decodes an Upload-Metadata value: comma separated pairs of a
+// key and an optional base64 encoded value.
+func ParseMetadata(value string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrorUploadMetadata
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, ErrorUploadMetadata
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrorUploadMetadata
+		}
+		metadata[key] = string(decoded)
+	}
+
+	return metadata, nil
+}
+
+func EncodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+)
+
+const (
+	MaxKeyLength = 255
+
+	DefaultTTL = 24 * time.Hour
+	// DefaultLockTimeout is how long a request may hold its key before it is
+	// taken for abandoned, such as by a crashed server, and may be retried.
+	DefaultLockTimeout = 5 * time.Minute
+	// DefaultMaxBodySize bounds the request body that is read to fingerprint
+	// a request, and the response body that is stored for replay.
+	DefaultMaxBodySize = 1 << 20
+)
+
+type (
+	// Record is an Idempotency-Key claimed by an unsafe request, with the
+	// fingerprint of that request and, once it has completed, its response.
+	Record struct {
+		ID identity.ID
+		// Scope keeps the keys of different clients apart.
+		Scope       string
+		Key         string
+		Fingerprint string
+		Response
+		ExpiresAt time.Time
+		shared.Timestamp
+	}
+
+	// Response is what a request answered, kept for replay. Its Status is 0
+	// while the request is in flight.
+	Response struct {
+		Status int
+		Header map[string][]string
+		Body   []byte
+	}
+)
+
+func (r Record) InFlight() bool {
+	return r.Status == 0
+}
+
+// Stale reports whether the record no longer holds its key at now: it has
+// expired, or its request has been in flight for longer than lockTimeout.
+func (r Record) Stale(now time.Time, lockTimeout time.Duration) bool {
+	return !now.Before(r.ExpiresAt) || r.InFlight() && !now.Before(r.CreatedAt.Add(lockTimeout))
+}
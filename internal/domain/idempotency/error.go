@@ -0,0 +1,11 @@
+package idempotency
+
+import "github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+var (
+	ErrorKeyInvalid      = shared.NewError(shared.CategoryValidation, "idempotency_key_invalid", "Idempotency-Key must be at most 255 characters")
+	ErrorRequestInFlight = shared.NewError(shared.CategoryConflict, "idempotency_request_in_flight", "a request with this Idempotency-Key is still in progress")
+	ErrorKeyReused       = shared.NewError(shared.CategoryConflict, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	ErrorBodyTooLarge    = shared.NewError(shared.CategoryTooLarge, "idempotency_body_too_large", "request body is too large to be sent with an Idempotency-Key")
+	ErrorClaimKey        = shared.NewError(shared.CategoryInternal, "idempotency_claim_failed", "failed to claim the idempotency key")
+)
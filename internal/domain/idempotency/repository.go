@@ -0,0 +1,21 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	Repository interface {
+		// Create claims record.Key within record.Scope, and fails with
+		// gorm.ErrDuplicatedKey when a record already holds it.
+		Create(ctx context.Context, record Record) (Record, error)
+		GetByKey(ctx context.Context, scope string, key string) (Record, error)
+		// Complete stores the response of the request holding the record.
+		Complete(ctx context.Context, id string, response Response) error
+		Delete(ctx context.Context, id string) error
+		// DeleteExpired deletes the records that expired by now and returns
+		// how many there were.
+		DeleteExpired(ctx context.Context, now time.Time) (int, error)
+	}
+)
@@ -0,0 +1,9 @@
+package port
+
+import "time"
+
+type (
+	ClockPort interface {
+		Now() time.Time
+	}
+)
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+type (
+	ScanResult struct {
+		Infected bool
+		// Signature names the threat found in an infected file.
+		Signature string
+	}
+
+	FileScannerPort interface {
+		Scan(ctx context.Context, content io.Reader) (ScanResult, error)
+	}
+)
@@ -1,10 +1,31 @@
 package port
 
-import "mime/multipart"
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"time"
+)
 
 type (
+	FileInfo struct {
+		Path        string
+		Size        int64
+		ContentType string
+		ModifiedAt  time.Time
+	}
+
 	FileStoragePort interface {
-		UploadFile(file *multipart.FileHeader, path string) error
+		UploadFile(ctx context.Context, file *multipart.FileHeader, path string) error
+		Put(ctx context.Context, path string, content io.Reader, size int64, contentType string) error
+		// Open returns a reader that may stop working once ctx is done.
+		Open(ctx context.Context, path string) (io.ReadSeekCloser, error)
+		Delete(ctx context.Context, path string) error
+		Stat(ctx context.Context, path string) (FileInfo, error)
+		List(ctx context.Context, prefix string) ([]FileInfo, error)
+		// SignedURL returns a fully qualified URL that grants read access to
+		// path until expiresIn has elapsed.
+		SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error)
 		GetExtension(filename string) string
 	}
 )
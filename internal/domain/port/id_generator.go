@@ -0,0 +1,9 @@
+package port
+
+import "github.com/google/uuid"
+
+type (
+	IDGeneratorPort interface {
+		NewID() uuid.UUID
+	}
+)
@@ -0,0 +1,16 @@
+package port
+
+import "context"
+
+type (
+	Mail struct {
+		To      string
+		Subject string
+		// Body is plain text.
+		Body string
+	}
+
+	MailerPort interface {
+		Send(ctx context.Context, mail Mail) error
+	}
+)
@@ -0,0 +1,10 @@
+package port
+
+import "time"
+
+type (
+	URLSignerPort interface {
+		Sign(path string, expiresAt time.Time) string
+		Verify(path string, expiresAt time.Time, signature string) error
+	}
+)
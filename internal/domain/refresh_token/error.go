@@ -1,8 +1,8 @@
 package refresh_token
 
-import "errors"
+import "github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 
 var (
-	ErrorThisUserRefreshTokenNotFound = errors.New("this user's refresh token not found")
-	ErrorPasswordNotMatch             = errors.New("password does not match")
+	ErrorThisUserRefreshTokenNotFound = shared.NewError(shared.CategoryUnauthorized, "refresh_token_not_found", "this user's refresh token not found")
+	ErrorPasswordNotMatch             = shared.NewError(shared.CategoryUnauthorized, "password_not_match", "password does not match")
 )
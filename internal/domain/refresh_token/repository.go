@@ -6,10 +6,13 @@ import (
 
 type (
 	Repository interface {
-		Create(ctx context.Context, tx interface{}, refreshTokenEntity RefreshToken) (RefreshToken, error)
-		FindByUserID(ctx context.Context, tx interface{}, userID string) (RefreshToken, error)
-		DeleteByUserID(ctx context.Context, tx interface{}, userID string) error
-		DeleteByToken(ctx context.Context, tx interface{}, token string) error
-		DeleteExpired(ctx context.Context, tx interface{}) error
+		Create(ctx context.Context, refreshTokenEntity RefreshToken) (RefreshToken, error)
+		FindByUserID(ctx context.Context, userID string) (RefreshToken, error)
+		// FindActiveByUserIDs returns the unexpired tokens of every user in
+		// userIDs, oldest first.
+		FindActiveByUserIDs(ctx context.Context, userIDs []string) ([]RefreshToken, error)
+		DeleteByUserID(ctx context.Context, userID string) error
+		DeleteByToken(ctx context.Context, token string) error
+		DeleteExpired(ctx context.Context) error
 	}
 )
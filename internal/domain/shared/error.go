@@ -0,0 +1,53 @@
+package shared
+
+import "errors"
+
+// Category groups domain errors by how a client should react to them. The
+// presentation layer maps each category to a status code.
+type Category string
+
+const (
+	CategoryValidation    Category = "validation"
+	CategoryUnauthorized  Category = "unauthorized"
+	CategoryForbidden     Category = "forbidden"
+	CategoryNotFound      Category = "not_found"
+	CategoryConflict      Category = "conflict"
+	CategoryGone          Category = "gone"
+	CategoryPrecondition  Category = "precondition"
+	CategoryTooLarge      Category = "too_large"
+	CategoryUnprocessable Category = "unprocessable"
+	CategoryUnavailable   Category = "unavailable"
+	CategoryInternal      Category = "internal"
+)
+
+// ErrorVersionConflict reports a write made against a version of a resource
+// that has since changed.
+var ErrorVersionConflict = NewError(CategoryPrecondition, "version_conflict", "resource has changed since it was read")
+
+// Error is a domain error with a stable, machine-readable code. Errors are
+// declared once per domain in its error.go and compared with errors.Is, so
+// they may be wrapped with more detail.
+type Error struct {
+	Code     string
+	Category Category
+	Message  string
+}
+
+func NewError(category Category, code string, message string) *Error {
+	return &Error{
+		Code:     code,
+		Category: category,
+		Message:  message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// AsError returns the first domain error in err's chain.
+func AsError(err error) (*Error, bool) {
+	var domainError *Error
+	ok := errors.As(err, &domainError)
+	return domainError, ok
+}
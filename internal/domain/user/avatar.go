@@ -0,0 +1,247 @@
+package user
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	AvatarMaxFileSize  = 5 << 20
+	AvatarMinDimension = 64
+	AvatarMaxDimension = 4096
+
+	DefaultImage = "profile/default.png"
+)
+
+// AvatarSizes are the square thumbnail edges generated for every avatar. The
+// largest one is the canonical image stored on the user.
+var AvatarSizes = []int{64, 256, 512}
+
+// avatarFormats maps the accepted upload types to the format thumbnails are
+// encoded in. Anything that may carry transparency becomes a PNG.
+var avatarFormats = map[string]avatarFormat{
+	"image/jpeg": {extension: "jpg", contentType: "image/jpeg"},
+	"image/png":  {extension: "png", contentType: "image/png"},
+	"image/gif":  {extension: "png", contentType: "image/png"},
+	"image/webp": {extension: "png", contentType: "image/png"},
+}
+
+type avatarFormat struct {
+	extension   string
+	contentType string
+}
+
+type AvatarThumbnail struct {
+	Size        int
+	Extension   string
+	ContentType string
+	Content     []byte
+}
+
+// NewAvatarThumbnails validates an uploaded image by its content rather than
+// its name and re-encodes it into square thumbnails. Re-encoding drops every
+// metadata block, EXIF included, after its orientation has been applied.
+func NewAvatarThumbnails(content io.Reader, size int64) ([]AvatarThumbnail, error) {
+	if size > AvatarMaxFileSize {
+		return nil, ErrorAvatarTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, AvatarMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > AvatarMaxFileSize {
+		return nil, ErrorAvatarTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	format, ok := avatarFormats[contentType]
+	if !ok {
+		return nil, ErrorAvatarUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrorAvatarInvalid
+	}
+	if config.Width < AvatarMinDimension || config.Height < AvatarMinDimension ||
+		config.Width > AvatarMaxDimension || config.Height > AvatarMaxDimension {
+		return nil, ErrorAvatarDimensions
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrorAvatarInvalid
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	thumbnails := make([]AvatarThumbnail, 0, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		thumbnail := orient(squareThumbnail(source, size), orientation)
+
+		var buf bytes.Buffer
+		if format.contentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, thumbnail)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+
+		thumbnails = append(thumbnails, AvatarThumbnail{
+			Size:        size,
+			Extension:   format.extension,
+			ContentType: format.contentType,
+			Content:     buf.Bytes(),
+		})
+	}
+
+	return thumbnails, nil
+}
+
+func AvatarPath(imageID string, size int, extension string) string {
+	return fmt.Sprintf("profile/%s/%d.%s", imageID, size, extension)
+}
+
+// AvatarThumbnailPaths returns the path of every thumbnail that belongs to the
+// canonical avatar path, keyed by size. Images that were not produced by
+// NewAvatarThumbnails have no thumbnails.
+func AvatarThumbnailPaths(filename string) map[int]string {
+	dir, base := path.Split(filename)
+	name, extension, ok := strings.Cut(base, ".")
+	if !ok || !strings.HasPrefix(dir, "profile/") || name != strconv.Itoa(AvatarSizes[len(AvatarSizes)-1]) {
+		return nil
+	}
+
+	paths := make(map[int]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		paths[size] = fmt.Sprintf("%s%d.%s", dir, size, extension)
+	}
+	return paths
+}
+
+func squareThumbnail(source image.Image, size int) *image.RGBA {
+	bounds := source.Bounds()
+	edge := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, edge, edge).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-edge)/2,
+		bounds.Min.Y+(bounds.Dy()-edge)/2,
+	))
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, crop, draw.Src, nil)
+	return thumbnail
+}
+
+// orient applies an EXIF orientation to a square image.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	n := src.Bounds().Dx()
+	last := n - 1
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = last-x, y
+			case 3:
+				sx, sy = last-x, last-y
+			case 4:
+				sx, sy = x, last-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, last-x
+			case 7:
+				sx, sy = last-y, last-x
+			case 8:
+				sx, sy = last-y, x
+			}
+			dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, defaulting to 1.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 1
+}
@@ -14,5 +14,7 @@ type User struct {
 	Role        Role
 	ImageUrl    shared.URL
 	IsVerified  bool
+	// Version counts the writes to the user, for optimistic locking.
+	Version int64
 	shared.Timestamp
 }
@@ -1,17 +1,26 @@
 package user
 
-import "errors"
+import "github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 
 var (
-	ErrorCreateUser         = errors.New("failed to create user")
-	ErrorGetAllUsers        = errors.New("failed to get all users")
-	ErrorGetUserById        = errors.New("failed to get user by id")
-	ErrorGetUserByEmail     = errors.New("failed to get user by email")
-	ErrorEmailAlreadyExists = errors.New("email already exist")
-	ErrorUpdateUser         = errors.New("failed to update user")
-	ErrorUserNotFound       = errors.New("user not found")
-	ErrorEmailNotFound      = errors.New("email not found")
-	ErrorDeleteUser         = errors.New("failed to delete user")
-	ErrorTokenInvalid       = errors.New("token invalid")
-	ErrorTokenExpired       = errors.New("token expired")
+	ErrorCreateUser         = shared.NewError(shared.CategoryInternal, "user_create_failed", "failed to create user")
+	ErrorGetAllUsers        = shared.NewError(shared.CategoryInternal, "user_list_failed", "failed to get all users")
+	ErrorGetUserById        = shared.NewError(shared.CategoryInternal, "user_get_failed", "failed to get user by id")
+	ErrorGetUserByEmail     = shared.NewError(shared.CategoryInternal, "user_get_by_email_failed", "failed to get user by email")
+	ErrorEmailAlreadyExists = shared.NewError(shared.CategoryConflict, "email_already_exists", "email already exist")
+	ErrorUpdateUser         = shared.NewError(shared.CategoryInternal, "user_update_failed", "failed to update user")
+	ErrorUserNotFound       = shared.NewError(shared.CategoryNotFound, "user_not_found", "user not found")
+	ErrorEmailNotFound      = shared.NewError(shared.CategoryUnauthorized, "email_not_found", "email not found")
+	ErrorDeleteUser         = shared.NewError(shared.CategoryInternal, "user_delete_failed", "failed to delete user")
+	ErrorGetUserSessions    = shared.NewError(shared.CategoryInternal, "user_sessions_failed", "failed to get user sessions")
+	ErrorTokenInvalid       = shared.NewError(shared.CategoryUnauthorized, "token_invalid", "token invalid")
+	ErrorTokenExpired       = shared.NewError(shared.CategoryUnauthorized, "token_expired", "token expired")
+	ErrorPasswordTooShort   = shared.NewError(shared.CategoryValidation, "password_too_short", "password must be at least 8 characters")
+	ErrorRoleInvalid        = shared.NewError(shared.CategoryValidation, "role_invalid", "invalid role Name")
+
+	ErrorAvatarTooLarge        = shared.NewError(shared.CategoryTooLarge, "avatar_too_large", "avatar exceeds the maximum file size")
+	ErrorAvatarUnsupportedType = shared.NewError(shared.CategoryValidation, "avatar_unsupported_type", "avatar must be a jpeg, png, gif or webp image")
+	ErrorAvatarInvalid         = shared.NewError(shared.CategoryValidation, "avatar_invalid", "avatar is not a valid image")
+	ErrorAvatarDimensions      = shared.NewError(shared.CategoryValidation, "avatar_dimensions", "avatar dimensions are out of range")
+	ErrorAvatarNotFound        = shared.NewError(shared.CategoryNotFound, "avatar_not_found", "avatar not found")
 )
@@ -14,7 +14,7 @@ type Password struct {
 
 func NewPassword(password string) (Password, error) {
 	if len(password) < 8 {
-		return Password{}, fmt.Errorf("password must be at least 8 characters")
+		return Password{}, ErrorPasswordTooShort
 	}
 
 	hashedPassword, err := hashPassword(password)
@@ -2,22 +2,43 @@ package user
 
 import (
 	"context"
+	"time"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
 )
 
 type (
 	Repository interface {
-		Register(ctx context.Context, tx interface{}, userEntity User) (User, error)
+		Register(ctx context.Context, userEntity User) (User, error)
 		GetAllUsersWithPagination(
 			ctx context.Context,
-			tx interface{},
 			req pagination.Request,
 		) (pagination.ResponseWithData, error)
-		GetUserByID(ctx context.Context, tx interface{}, id string) (User, error)
-		GetUserByEmail(ctx context.Context, tx interface{}, email string) (User, error)
-		CheckEmail(ctx context.Context, tx interface{}, email string) (User, bool, error)
-		Update(ctx context.Context, tx interface{}, userEntity User) (User, error)
-		Delete(ctx context.Context, tx interface{}, id string) error
+		GetUserByID(ctx context.Context, id string) (User, error)
+		GetUserByEmail(ctx context.Context, email string) (User, error)
+		CheckEmail(ctx context.Context, email string) (User, bool, error)
+		// Update writes userEntity if the stored user is still at
+		// userEntity.Version, and reports shared.ErrorVersionConflict if not.
+		Update(ctx context.Context, userEntity User) (User, error)
+		UpdateImageUrl(ctx context.Context, id string, version int64, imageUrl shared.URL) (User, error)
+		// Delete deletes the user if it is still at version.
+		Delete(ctx context.Context, id string, version int64) error
 	}
 )
+
+// CreatedAtKeyset pages through users from the oldest to the newest.
+var CreatedAtKeyset = pagination.Keyset[User, time.Time]{
+	Column: "created_at",
+	Key:    func(u User) time.Time { return u.CreatedAt },
+	ID:     func(u User) string { return u.ID.String() },
+}
+
+// Fields lists what the user list may be sorted and filtered by.
+var Fields = pagination.Fields[User]{
+	"name":        {Column: "name", Type: pagination.FieldString, Sort: true, Filter: true, Value: func(u User) any { return u.Name }},
+	"email":       {Column: "email", Type: pagination.FieldString, Sort: true, Filter: true, Value: func(u User) any { return u.Email }},
+	"role":        {Column: "role", Type: pagination.FieldString, Filter: true, Value: func(u User) any { return u.Role.Name }},
+	"is_verified": {Column: "is_verified", Type: pagination.FieldBool, Filter: true, Value: func(u User) any { return u.IsVerified }},
+	"created_at":  {Column: "created_at", Type: pagination.FieldTime, Sort: true, Filter: true, Value: func(u User) any { return u.CreatedAt }},
+}
@@ -1,7 +1,5 @@
 package user
 
-import "fmt"
-
 const (
 	RoleAdmin = "admin"
 	RoleUser  = "user"
@@ -20,7 +18,7 @@ type Role struct {
 
 func NewRole(name string) (Role, error) {
 	if !isValidRole(name) {
-		return Role{}, fmt.Errorf("invalid role Name")
+		return Role{}, ErrorRoleInvalid
 	}
 	return Role{
 		Name: name,
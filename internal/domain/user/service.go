@@ -1,34 +1,218 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"path"
+	"strconv"
+	"time"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 	"github.com/samber/do/v2"
-
-	"github.com/google/uuid"
 )
 
+const ImageURLExpiration = 24 * time.Hour
+
 type Service struct {
-	fileStorage port.FileStoragePort
+	fileStorage       port.FileStoragePort
+	idGenerator       port.IDGeneratorPort
+	fileDomainService *file.Service
 }
 
 func NewService(injector do.Injector) *Service {
 	fileStorage := do.MustInvoke[port.FileStoragePort](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	fileDomainService := do.MustInvoke[*file.Service](injector)
 	return &Service{
-		fileStorage: fileStorage,
+		fileStorage:       fileStorage,
+		idGenerator:       idGenerator,
+		fileDomainService: fileDomainService,
+	}
+}
+
+// UploadImage scans image and stores every thumbnail of it for ownerID, then
+// returns the path of the largest one. When the owner already has an avatar with the same
+// content its thumbnails gain a reference instead, and stored is false.
+func (s *Service) UploadImage(ctx context.Context, ownerID identity.ID, image *multipart.FileHeader) (filename string, stored bool, err error) {
+	opened, err := image.Open()
+	if err != nil {
+		return "", false, err
+	}
+	defer opened.Close()
+
+	return s.uploadImage(ctx, ownerID, opened, image.Size)
+}
+
+// UploadImageFromFile does the same as UploadImage for a file that has already
+// been uploaded, such as a completed resumable upload.
+func (s *Service) UploadImageFromFile(ctx context.Context, ownerID identity.ID, fileEntity file.File) (filename string, stored bool, err error) {
+	opened, err := s.fileStorage.Open(ctx, fileEntity.StorageKey)
+	if err != nil {
+		return "", false, err
+	}
+	defer opened.Close()
+
+	return s.uploadImage(ctx, ownerID, opened, fileEntity.Size)
+}
+
+// ReleaseImage drops the owner's references to every thumbnail of filename.
+// Images stored before files were registered have no references; for those
+// legacy is true and the caller should delete them with DeleteImage.
+func (s *Service) ReleaseImage(ctx context.Context, ownerID identity.ID, filename string) (legacy bool, err error) {
+	if filename == "" || filename == DefaultImage {
+		return false, nil
+	}
+
+	for _, imagePath := range imagePaths(filename) {
+		err = s.fileDomainService.ReleaseByStorageKey(ctx, ownerID, imagePath)
+		if errors.Is(err, file.ErrorFileNotFound) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to release image: %w", err)
+		}
 	}
+
+	return false, nil
 }
 
-func (s *Service) UploadImage(image *multipart.FileHeader) (filename string, err error) {
-	imageId := uuid.New()
-	ext := s.fileStorage.GetExtension(image.Filename)
+func (s *Service) uploadImage(ctx context.Context, ownerID identity.ID, content io.Reader, size int64) (filename string, stored bool, err error) {
+	if size > AvatarMaxFileSize {
+		return "", false, ErrorAvatarTooLarge
+	}
+
+	quarantined, err := s.fileDomainService.Quarantine(ctx, io.LimitReader(content, AvatarMaxFileSize+1))
+	if err != nil {
+		return "", false, err
+	}
+	defer quarantined.Close()
+
+	thumbnails, err := NewAvatarThumbnails(quarantined.Reader(), quarantined.Size)
+	if err != nil {
+		return "", false, err
+	}
+
+	if filename, err = s.reuseImage(ctx, ownerID, thumbnails[len(thumbnails)-1].Content); err != nil || filename != "" {
+		return filename, false, err
+	}
+
+	imageId := s.idGenerator.NewID()
+
+	uploaded := make([]string, 0, len(thumbnails))
+	for _, thumbnail := range thumbnails {
+		filename = AvatarPath(imageId.String(), thumbnail.Size, thumbnail.Extension)
+		_, err = s.fileDomainService.StoreAt(ctx, file.File{
+			OwnerID:     ownerID,
+			StorageKey:  filename,
+			Name:        path.Base(filename),
+			ContentType: thumbnail.ContentType,
+		}, thumbnail.Content)
+		if err != nil {
+			for _, uploadedPath := range uploaded {
+				_ = s.fileStorage.Delete(context.WithoutCancel(ctx), uploadedPath)
+			}
+			if errors.Is(err, file.ErrorQuotaExceeded) {
+				return "", false, err
+			}
+			return "", false, fmt.Errorf("failed to upload image: %w", err)
+		}
+		uploaded = append(uploaded, filename)
+	}
+
+	return filename, true, nil
+}
 
-	filename = fmt.Sprintf("profile/%s.%s", imageId.String(), ext)
-	if err = s.fileStorage.UploadFile(image, filename); err != nil {
-		return "", fmt.Errorf("failed to upload image: %w", err)
+// reuseImage looks for an avatar of the owner whose largest thumbnail is
+// canonical and, when found, references all of its thumbnails again.
+func (s *Service) reuseImage(ctx context.Context, ownerID identity.ID, canonical []byte) (string, error) {
+	duplicates, err := s.fileDomainService.Duplicates(ctx, ownerID, canonical)
+	if err != nil {
+		return "", err
 	}
 
-	return filename, nil
+	for _, duplicate := range duplicates {
+		if AvatarThumbnailPaths(duplicate.StorageKey) == nil {
+			continue
+		}
+
+		for _, thumbnailPath := range imagePaths(duplicate.StorageKey) {
+			if _, err = s.fileDomainService.AcquireByStorageKey(ctx, ownerID, thumbnailPath); err != nil {
+				return "", err
+			}
+		}
+		return duplicate.StorageKey, nil
+	}
+
+	return "", nil
+}
+
+func (s *Service) DeleteImage(ctx context.Context, filename string) error {
+	if filename == "" || filename == DefaultImage {
+		return nil
+	}
+
+	var errs []error
+	for _, imagePath := range imagePaths(filename) {
+		if err := s.fileStorage.Delete(ctx, imagePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) ImageURL(ctx context.Context, filename string) (string, error) {
+	if filename == "" {
+		return "", nil
+	}
+
+	imageURL, err := s.fileStorage.SignedURL(ctx, filename, ImageURLExpiration)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign image url: %w", err)
+	}
+
+	return imageURL, nil
+}
+
+// ThumbnailURLs returns a signed URL per thumbnail size, keyed by the size in
+// pixels. It is nil for images without thumbnails.
+func (s *Service) ThumbnailURLs(ctx context.Context, filename string) (map[string]string, error) {
+	thumbnails := AvatarThumbnailPaths(filename)
+	if thumbnails == nil {
+		return nil, nil
+	}
+
+	urls := make(map[string]string, len(thumbnails))
+	for size, thumbnailPath := range thumbnails {
+		thumbnailURL, err := s.ImageURL(ctx, thumbnailPath)
+		if err != nil {
+			return nil, err
+		}
+		urls[strconv.Itoa(size)] = thumbnailURL
+	}
+
+	return urls, nil
+}
+
+// imagePaths returns every stored path of an image: its thumbnails, or the
+// image itself when it has none.
+func imagePaths(filename string) []string {
+	thumbnails := AvatarThumbnailPaths(filename)
+	if thumbnails == nil {
+		return []string{filename}
+	}
+
+	paths := make([]string, 0, len(thumbnails))
+	for _, size := range AvatarSizes {
+		paths = append(paths, thumbnails[size])
+	}
+	return paths
 }
@@ -0,0 +1,17 @@
+package clock
+
+import (
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+type systemAdapter struct{}
+
+func NewSystemAdapter() port.ClockPort {
+	return &systemAdapter{}
+}
+
+func (s systemAdapter) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,157 @@
+package file_scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+const (
+	DriverNone   = "none"
+	DriverClamAV = "clamav"
+
+	DefaultClamAVAddress = "tcp://localhost:3310"
+	DefaultClamAVTimeout = time.Minute
+
+	clamAVChunkSize = 64 << 10
+)
+
+type (
+	ClamAVConfig struct {
+		// Address is a tcp:// or unix:// URL of a clamd daemon.
+		Address string
+		Timeout time.Duration
+	}
+
+	clamAVAdapter struct {
+		network string
+		address string
+		timeout time.Duration
+	}
+)
+
+func NewClamAVAdapter(config ClamAVConfig) (port.FileScannerPort, error) {
+	if config.Address == "" {
+		config.Address = DefaultClamAVAddress
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultClamAVTimeout
+	}
+
+	address, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamav address: %w", err)
+	}
+
+	adapter := &clamAVAdapter{
+		network: address.Scheme,
+		timeout: config.Timeout,
+	}
+	switch address.Scheme {
+	case "tcp":
+		adapter.address = address.Host
+	case "unix":
+		adapter.address = address.Path
+	default:
+		return nil, fmt.Errorf("unsupported clamav address scheme: %s", address.Scheme)
+	}
+
+	return adapter, nil
+}
+
+func LoadClamAVConfig() ClamAVConfig {
+	timeout, _ := time.ParseDuration(os.Getenv("CLAMAV_TIMEOUT"))
+	return ClamAVConfig{
+		Address: os.Getenv("CLAMAV_ADDRESS"),
+		Timeout: timeout,
+	}
+}
+
+// Scan streams content to clamd with the INSTREAM command. Content over the
+// daemon's StreamMaxLength is reported as an error rather than as clean.
+func (c *clamAVAdapter) Scan(ctx context.Context, content io.Reader) (port.ScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return port.ScanResult{}, fmt.Errorf("failed to connect to clamav: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// clamd replies and hangs up as soon as a stream is over its limit, so
+	// the reply is read even when sending failed.
+	streamErr := c.stream(conn, content)
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if reply == "" {
+		if streamErr != nil {
+			return port.ScanResult{}, fmt.Errorf("failed to send file to clamav: %w", streamErr)
+		}
+		return port.ScanResult{}, fmt.Errorf("failed to read clamav reply: %w", err)
+	}
+
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+func (c *clamAVAdapter) stream(conn net.Conn, content io.Reader) error {
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, clamAVChunkSize)
+	var size [4]byte
+	for {
+		n, err := content.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, writeErr := conn.Write(append(size[:], chunk[:n]...)); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	binary.BigEndian.PutUint32(size[:], 0)
+	_, err := conn.Write(size[:])
+	return err
+}
+
+// parseClamAVReply reads replies such as "stream: OK" and
+// "stream: Eicar-Test-Signature FOUND".
+func parseClamAVReply(reply string) (port.ScanResult, error) {
+	_, status, ok := strings.Cut(reply, ": ")
+	if !ok {
+		return port.ScanResult{}, fmt.Errorf("unexpected clamav reply: %q", reply)
+	}
+
+	switch {
+	case status == "OK":
+		return port.ScanResult{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return port.ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(status, " FOUND"),
+		}, nil
+	default:
+		return port.ScanResult{}, fmt.Errorf("clamav: %s", strings.TrimSpace(status))
+	}
+}
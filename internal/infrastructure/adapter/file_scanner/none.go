@@ -0,0 +1,20 @@
+package file_scanner
+
+import (
+	"context"
+	"io"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+type noneAdapter struct{}
+
+// NewNoneAdapter returns a scanner that reports every file as clean, for
+// deployments without a scanning daemon.
+func NewNoneAdapter() port.FileScannerPort {
+	return &noneAdapter{}
+}
+
+func (n noneAdapter) Scan(_ context.Context, _ io.Reader) (port.ScanResult, error) {
+	return port.ScanResult{}, nil
+}
@@ -1,42 +1,97 @@
 package file_storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"mime/multipart"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 )
 
-const Path = "assets"
+const (
+	Path = "assets"
 
-type localAdapter struct{}
+	// DownloadPath is where the signed download route is mounted.
+	DownloadPath = "/api/v1/storage"
+)
+
+type (
+	LocalConfig struct {
+		Root string
+		// BaseURL is the public URL of the signed download route.
+		BaseURL string
+	}
+
+	localAdapter struct {
+		config LocalConfig
+		signer port.URLSignerPort
+		clock  port.ClockPort
+	}
+)
+
+func NewLocalAdapter(config LocalConfig, signer port.URLSignerPort, clock port.ClockPort) port.FileStoragePort {
+	if config.Root == "" {
+		config.Root = Path
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
 
-func NewLocalAdapter() port.FileStoragePort {
-	return &localAdapter{}
+	return &localAdapter{
+		config: config,
+		signer: signer,
+		clock:  clock,
+	}
 }
 
-func (l localAdapter) UploadFile(file *multipart.FileHeader, path string) error {
-	parts := strings.Split(path, "/")
-	fileID := parts[1]
-	dirPath := fmt.Sprintf("%s/%s", Path, fileID)
+func LoadLocalConfig() LocalConfig {
+	return LocalConfig{
+		Root:    os.Getenv("STORAGE_LOCAL_ROOT"),
+		BaseURL: getDownloadBaseURL(),
+	}
+}
 
-	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
-			return err
+// getDownloadBaseURL reads STORAGE_BASE_URL, the public URL of the signed
+// download route.
+func getDownloadBaseURL() string {
+	baseURL := os.Getenv("STORAGE_BASE_URL")
+	if baseURL == "" {
+		port := os.Getenv("GOLANG_PORT")
+		if port == "" {
+			port = "8888"
 		}
+		baseURL = "http://localhost:" + port + DownloadPath
 	}
+	return baseURL
+}
 
-	filePath := fmt.Sprintf("%s/%s", dirPath, fileID)
-
+func (l localAdapter) UploadFile(ctx context.Context, file *multipart.FileHeader, path string) error {
 	uploadedFile, err := file.Open()
 	if err != nil {
 		return err
 	}
 	defer uploadedFile.Close()
 
+	return l.Put(ctx, path, uploadedFile, file.Size, file.Header.Get("Content-Type"))
+}
+
+func (l localAdapter) Put(_ context.Context, path string, content io.Reader, _ int64, _ string) error {
+	filePath := l.location(path)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return err
+	}
+
 	targetFile, err := os.Create(filePath)
 	if err != nil {
 		return err
@@ -44,7 +99,7 @@ func (l localAdapter) UploadFile(file *multipart.FileHeader, path string) error
 
 	defer targetFile.Close()
 
-	_, err = io.Copy(targetFile, uploadedFile)
+	_, err = io.Copy(targetFile, content)
 	if err != nil {
 		return err
 	}
@@ -52,6 +107,143 @@ func (l localAdapter) UploadFile(file *multipart.FileHeader, path string) error
 	return nil
 }
 
+func (l localAdapter) Open(_ context.Context, path string) (io.ReadSeekCloser, error) {
+	openedFile, err := os.Open(l.resolve(path))
+	if err != nil {
+		return nil, localError(path, err)
+	}
+
+	return openedFile, nil
+}
+
+func (l localAdapter) Delete(_ context.Context, path string) error {
+	filePath := l.resolve(path)
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Remove the directories left empty, stopping at the first one that still
+	// holds other files.
+	root := filepath.Clean(l.config.Root)
+	for dir := filepath.Dir(filePath); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (l localAdapter) Stat(_ context.Context, path string) (port.FileInfo, error) {
+	info, err := os.Stat(l.resolve(path))
+	if err != nil {
+		return port.FileInfo{}, localError(path, err)
+	}
+	if info.IsDir() {
+		return port.FileInfo{}, fmt.Errorf("%s: %w", path, file.ErrorFileNotFound)
+	}
+
+	return l.fileInfo(path, info), nil
+}
+
+func (l localAdapter) List(_ context.Context, prefix string) ([]port.FileInfo, error) {
+	var files []port.FileInfo
+
+	err := filepath.WalkDir(l.config.Root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(l.config.Root, filePath)
+		if err != nil {
+			return err
+		}
+		path := filepath.ToSlash(relative)
+		if !strings.HasPrefix(path, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, l.fileInfo(path, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+func (l localAdapter) SignedURL(_ context.Context, path string, expiresIn time.Duration) (string, error) {
+	return downloadURL(l.config.BaseURL, l.signer, path, l.clock.Now().Add(expiresIn)), nil
+}
+
+// downloadURL links to path on the signed download route at baseURL.
+func downloadURL(baseURL string, signer port.URLSignerPort, path string, expiresAt time.Time) string {
+	path = strings.TrimPrefix(path, "/")
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", signer.Sign(path, expiresAt))
+
+	return fmt.Sprintf("%s/%s?%s", baseURL, strings.Join(segments, "/"), query.Encode())
+}
+
 func (l localAdapter) GetExtension(filename string) string {
 	return strings.Split(filename, ".")[len(strings.Split(filename, "."))-1]
 }
+
+func (l localAdapter) location(path string) string {
+	cleaned := filepath.Clean(string(filepath.Separator) + filepath.FromSlash(path))
+	return filepath.Join(l.config.Root, cleaned)
+}
+
+// resolve falls back to the <root>/<name>/<name> layout that earlier releases
+// wrote files to, so those files stay readable and deletable.
+func (l localAdapter) resolve(path string) string {
+	filePath := l.location(path)
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		return filePath
+	}
+
+	name := filepath.Base(filePath)
+	legacyPath := filepath.Join(l.config.Root, name, name)
+	if info, err := os.Stat(legacyPath); err == nil && !info.IsDir() {
+		return legacyPath
+	}
+	return filePath
+}
+
+func (l localAdapter) fileInfo(path string, info fs.FileInfo) port.FileInfo {
+	return port.FileInfo{
+		Path:        path,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		ModifiedAt:  info.ModTime(),
+	}
+}
+
+func localError(path string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", path, file.ErrorFileNotFound)
+	}
+	return err
+}
@@ -0,0 +1,318 @@
+package file_storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+
+	EncryptionNone = ""
+	EncryptionS3   = "AES256"
+	EncryptionKMS  = "aws:kms"
+	EncryptionSSEC = "SSE-C"
+
+	DefaultS3Endpoint = "s3.amazonaws.com"
+	DefaultPartSize   = 16 << 20
+)
+
+type (
+	S3Config struct {
+		Bucket    string
+		Prefix    string
+		Region    string
+		Endpoint  string
+		AccessKey string
+		SecretKey string
+		UseSSL    bool
+		PathStyle bool
+		// Encryption is one of EncryptionNone, EncryptionS3, EncryptionKMS or EncryptionSSEC.
+		Encryption  string
+		KMSKeyID    string
+		CustomerKey []byte
+		// Files larger than PartSize are sent as a multipart upload.
+		PartSize uint64
+		// DownloadBaseURL is the public URL of the signed download route,
+		// which serves the objects encrypted with EncryptionSSEC.
+		DownloadBaseURL string
+	}
+
+	s3Adapter struct {
+		client *minio.Client
+		config S3Config
+		sse    encrypt.ServerSide
+		signer port.URLSignerPort
+		clock  port.ClockPort
+	}
+)
+
+func NewS3Adapter(config S3Config, signer port.URLSignerPort, clock port.ClockPort) (port.FileStoragePort, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Encryption == EncryptionSSEC && config.DownloadBaseURL == "" {
+		return nil, fmt.Errorf("a download base url is required to serve SSE-C objects")
+	}
+	if config.PartSize == 0 {
+		config.PartSize = DefaultPartSize
+	}
+	config.DownloadBaseURL = strings.TrimSuffix(config.DownloadBaseURL, "/")
+
+	endpoint, secure, err := parseS3Endpoint(config.Endpoint, config.UseSSL)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := minio.BucketLookupAuto
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        s3Credentials(config),
+		Secure:       secure,
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	sse, err := s3ServerSideEncryption(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Adapter{
+		client: client,
+		config: config,
+		sse:    sse,
+		signer: signer,
+		clock:  clock,
+	}, nil
+}
+
+func LoadS3Config() (S3Config, error) {
+	config := S3Config{
+		Bucket:     os.Getenv("S3_BUCKET"),
+		Prefix:     os.Getenv("S3_PREFIX"),
+		Region:     os.Getenv("S3_REGION"),
+		Endpoint:   os.Getenv("S3_ENDPOINT"),
+		AccessKey:  os.Getenv("S3_ACCESS_KEY"),
+		SecretKey:  os.Getenv("S3_SECRET_KEY"),
+		UseSSL:     os.Getenv("S3_USE_SSL") != "false",
+		PathStyle:  os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+		Encryption: os.Getenv("S3_SSE"),
+		KMSKeyID:   os.Getenv("S3_SSE_KMS_KEY_ID"),
+
+		DownloadBaseURL: getDownloadBaseURL(),
+	}
+
+	if key := os.Getenv("S3_SSE_CUSTOMER_KEY"); key != "" {
+		customerKey, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return S3Config{}, fmt.Errorf("invalid S3_SSE_CUSTOMER_KEY: %w", err)
+		}
+		config.CustomerKey = customerKey
+	}
+
+	if partSize := os.Getenv("S3_PART_SIZE_MB"); partSize != "" {
+		size, err := strconv.ParseUint(partSize, 10, 64)
+		if err != nil {
+			return S3Config{}, fmt.Errorf("invalid S3_PART_SIZE_MB: %w", err)
+		}
+		config.PartSize = size << 20
+	}
+
+	return config, nil
+}
+
+func (s s3Adapter) UploadFile(ctx context.Context, file *multipart.FileHeader, path string) error {
+	uploadedFile, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer uploadedFile.Close()
+
+	return s.Put(ctx, path, uploadedFile, file.Size, file.Header.Get("Content-Type"))
+}
+
+func (s s3Adapter) Put(ctx context.Context, path string, content io.Reader, size int64, contentType string) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	_, err := s.client.PutObject(ctx, s.config.Bucket, s.key(path), content, size, minio.PutObjectOptions{
+		ContentType:          contentType,
+		PartSize:             s.config.PartSize,
+		ServerSideEncryption: s.sse,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to s3: %w", path, err)
+	}
+
+	return nil
+}
+
+func (s s3Adapter) Open(ctx context.Context, path string) (io.ReadSeekCloser, error) {
+	options := minio.GetObjectOptions{ServerSideEncryption: s.readEncryption()}
+
+	object, err := s.client.GetObject(ctx, s.config.Bucket, s.key(path), options)
+	if err != nil {
+		return nil, s3Error(path, err)
+	}
+
+	if _, err = object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, s3Error(path, err)
+	}
+
+	return object, nil
+}
+
+func (s s3Adapter) Delete(ctx context.Context, path string) error {
+	err := s.client.RemoveObject(ctx, s.config.Bucket, s.key(path), minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from s3: %w", path, err)
+	}
+
+	return nil
+}
+
+func (s s3Adapter) Stat(ctx context.Context, path string) (port.FileInfo, error) {
+	options := minio.StatObjectOptions{ServerSideEncryption: s.readEncryption()}
+
+	info, err := s.client.StatObject(ctx, s.config.Bucket, s.key(path), options)
+	if err != nil {
+		return port.FileInfo{}, s3Error(path, err)
+	}
+
+	return s.fileInfo(info), nil
+}
+
+func (s s3Adapter) List(ctx context.Context, prefix string) ([]port.FileInfo, error) {
+	var files []port.FileInfo
+
+	objects := s.client.ListObjects(ctx, s.config.Bucket, minio.ListObjectsOptions{
+		Prefix:    s.key(prefix),
+		Recursive: true,
+	})
+	for object := range objects {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list %s in s3: %w", prefix, object.Err)
+		}
+		files = append(files, s.fileInfo(object))
+	}
+
+	return files, nil
+}
+
+// SignedURL presigns a GET of the object. A presigned GET cannot carry the
+// customer key SSE-C objects are read with, so those are linked to the signed
+// download route instead, which reads them through Open.
+func (s s3Adapter) SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	if s.config.Encryption == EncryptionSSEC {
+		return downloadURL(s.config.DownloadBaseURL, s.signer, path, s.clock.Now().Add(expiresIn)), nil
+	}
+
+	signedURL, err := s.client.PresignedGetObject(ctx, s.config.Bucket, s.key(path), expiresIn, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign %s: %w", path, err)
+	}
+
+	return signedURL.String(), nil
+}
+
+func (s s3Adapter) GetExtension(filename string) string {
+	return strings.Split(filename, ".")[len(strings.Split(filename, "."))-1]
+}
+
+func (s s3Adapter) key(name string) string {
+	return path.Join(s.config.Prefix, strings.TrimPrefix(name, "/"))
+}
+
+func (s s3Adapter) fileInfo(info minio.ObjectInfo) port.FileInfo {
+	name := strings.TrimPrefix(info.Key, s.config.Prefix)
+	return port.FileInfo{
+		Path:        strings.TrimPrefix(name, "/"),
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		ModifiedAt:  info.LastModified,
+	}
+}
+
+// readEncryption returns the customer key that SSE-C objects must be read
+// with. Other encryption modes are transparent to readers.
+func (s s3Adapter) readEncryption() encrypt.ServerSide {
+	if s.config.Encryption == EncryptionSSEC {
+		return s.sse
+	}
+	return nil
+}
+
+func s3Error(path string, err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return fmt.Errorf("%s: %w", path, file.ErrorFileNotFound)
+	}
+	return fmt.Errorf("failed to read %s from s3: %w", path, err)
+}
+
+func parseS3Endpoint(endpoint string, useSSL bool) (string, bool, error) {
+	if endpoint == "" {
+		return DefaultS3Endpoint, true, nil
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, useSSL, nil
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid s3 endpoint %q: %w", endpoint, err)
+	}
+	return parsed.Host, parsed.Scheme == "https", nil
+}
+
+func s3Credentials(config S3Config) *credentials.Credentials {
+	if config.AccessKey != "" {
+		return credentials.NewStaticV4(config.AccessKey, config.SecretKey, "")
+	}
+
+	return credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+		&credentials.IAM{},
+	})
+}
+
+func s3ServerSideEncryption(config S3Config) (encrypt.ServerSide, error) {
+	switch config.Encryption {
+	case EncryptionNone:
+		return nil, nil
+	case EncryptionS3:
+		return encrypt.NewSSE(), nil
+	case EncryptionKMS:
+		return encrypt.NewSSEKMS(config.KMSKeyID, nil)
+	case EncryptionSSEC:
+		return encrypt.NewSSEC(config.CustomerKey)
+	default:
+		return nil, fmt.Errorf("unsupported s3 encryption: %s", config.Encryption)
+	}
+}
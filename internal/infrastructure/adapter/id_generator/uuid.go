@@ -0,0 +1,17 @@
+package id_generator
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+
+	"github.com/google/uuid"
+)
+
+type uuidAdapter struct{}
+
+func NewUUIDAdapter() port.IDGeneratorPort {
+	return &uuidAdapter{}
+}
+
+func (u uuidAdapter) NewID() uuid.UUID {
+	return uuid.New()
+}
@@ -0,0 +1,21 @@
+package mailer
+
+import (
+	"context"
+	"log"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+type logAdapter struct{}
+
+// NewLogAdapter returns a mailer that writes every mail to the log instead of
+// sending it, for development without a mail server.
+func NewLogAdapter() port.MailerPort {
+	return &logAdapter{}
+}
+
+func (l logAdapter) Send(_ context.Context, mail port.Mail) error {
+	log.Printf("mail to %s: %s\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
@@ -0,0 +1,59 @@
+package url_signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+type hmacAdapter struct {
+	secret []byte
+	clock  port.ClockPort
+}
+
+func NewHMACAdapter(secret []byte, clock port.ClockPort) port.URLSignerPort {
+	return &hmacAdapter{
+		secret: secret,
+		clock:  clock,
+	}
+}
+
+func GetSigningKey() []byte {
+	secretKey := os.Getenv("STORAGE_SIGNING_KEY")
+	if secretKey == "" {
+		secretKey = "kpl-base-storage-secret"
+	}
+	return []byte(secretKey)
+}
+
+func (h hmacAdapter) Sign(path string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(strconv.FormatInt(expiresAt.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h hmacAdapter) Verify(path string, expiresAt time.Time, signature string) error {
+	expected, err := hex.DecodeString(h.Sign(path, expiresAt))
+	if err != nil {
+		return err
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, given) {
+		return file.ErrorSignatureInvalid
+	}
+
+	if h.clock.Now().After(expiresAt) {
+		return file.ErrorSignatureExpired
+	}
+
+	return nil
+}
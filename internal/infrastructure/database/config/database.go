@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/glebarez/sqlite"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -12,34 +15,53 @@ import (
 const (
 	RunProduction = "production"
 	RunTesting    = "testing"
+
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	SQLiteMemory = ":memory:"
 )
 
 func RunExtension(db *gorm.DB) {
+	if db.Dialector.Name() != DriverPostgres {
+		return
+	}
+
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\";")
 }
 
-func SetUpDatabaseConnection() *gorm.DB {
+// LoadEnv loads .env outside production, where the environment is set by
+// the deployment instead.
+func LoadEnv() {
 	if os.Getenv("APP_ENV") != RunProduction {
 		err := godotenv.Load(".env")
 		if err != nil {
 			panic(err)
 		}
 	}
+}
 
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASS")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
+func SetUpDatabaseConnection(clock port.ClockPort) *gorm.DB {
+	LoadEnv()
+
+	config := &gorm.Config{
+		Logger:         SetupLogger(clock),
+		TranslateError: true,
+	}
 
-	dsn := fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v", dbHost, dbUser, dbPass, dbName, dbPort)
+	var (
+		db  *gorm.DB
+		err error
+	)
 
-	db, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  dsn,
-		PreferSimpleProtocol: true,
-	}), &gorm.Config{
-		Logger: SetupLogger(),
-	})
+	switch driver := getDriver(); driver {
+	case DriverPostgres:
+		db, err = OpenPostgres(postgresDSN(), config)
+	case DriverSQLite:
+		db, err = OpenSQLite(os.Getenv("DB_NAME"), config)
+	default:
+		err = fmt.Errorf("unsupported database driver: %s", driver)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -49,6 +71,34 @@ func SetUpDatabaseConnection() *gorm.DB {
 	return db
 }
 
+func OpenPostgres(dsn string, config *gorm.Config) (*gorm.DB, error) {
+	return gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: true,
+	}), config)
+}
+
+func OpenSQLite(name string, config *gorm.Config) (*gorm.DB, error) {
+	if name == "" {
+		name = SQLiteMemory
+	}
+
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(name)), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every connection to ":memory:" opens a separate database, and SQLite only
+	// allows a single writer anyway, so keep the pool to one connection.
+	dbSQL, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	dbSQL.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
 func CloseDatabaseConnection(db *gorm.DB) {
 	dbSQL, err := db.DB()
 	if err != nil {
@@ -56,3 +106,29 @@ func CloseDatabaseConnection(db *gorm.DB) {
 	}
 	dbSQL.Close()
 }
+
+func getDriver() string {
+	driver := strings.ToLower(os.Getenv("DB_DRIVER"))
+	if driver == "" {
+		driver = DriverPostgres
+	}
+	return driver
+}
+
+func postgresDSN() string {
+	dbUser := os.Getenv("DB_USER")
+	dbPass := os.Getenv("DB_PASS")
+	dbHost := os.Getenv("DB_HOST")
+	dbName := os.Getenv("DB_NAME")
+	dbPort := os.Getenv("DB_PORT")
+
+	return fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v", dbHost, dbUser, dbPass, dbName, dbPort)
+}
+
+func sqliteDSN(name string) string {
+	separator := "?"
+	if strings.Contains(name, "?") {
+		separator = "&"
+	}
+	return name + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+
 	"gorm.io/gorm/logger"
 )
 
@@ -13,13 +15,13 @@ const (
 	LogDirectory = "./logs/query_log"
 )
 
-func SetupLogger() logger.Interface {
+func SetupLogger(clock port.ClockPort) logger.Interface {
 	err := os.MkdirAll(LogDirectory, os.ModePerm)
 	if err != nil {
 		log.Fatalf("failed to create log directory: %v", err)
 	}
 
-	currentMonth := time.Now().Format("January")
+	currentMonth := clock.Now().Format("January")
 	currentMonth = strings.ToLower(currentMonth)
 	logFileName := currentMonth + "_query.log"
 
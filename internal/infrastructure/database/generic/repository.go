@@ -0,0 +1,216 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+
+	"gorm.io/gorm"
+)
+
+type Repository[E any, T any] struct {
+	db       *transaction.Repository
+	toTable  func(entity E) T
+	toEntity func(table T) E
+}
+
+func NewRepository[E any, T any](
+	db *transaction.Repository,
+	toTable func(entity E) T,
+	toEntity func(table T) E,
+) *Repository[E, T] {
+	return &Repository[E, T]{
+		db:       db,
+		toTable:  toTable,
+		toEntity: toEntity,
+	}
+}
+
+func (r *Repository[E, T]) DB(ctx context.Context) *gorm.DB {
+	return r.db.Conn(ctx)
+}
+
+func (r *Repository[E, T]) Query(ctx context.Context, scopes ...Scope) *gorm.DB {
+	return r.DB(ctx).Model(new(T)).Scopes(toGormScopes(scopes)...)
+}
+
+func (r *Repository[E, T]) ToTable(entity E) T {
+	return r.toTable(entity)
+}
+
+func (r *Repository[E, T]) ToEntity(table T) E {
+	return r.toEntity(table)
+}
+
+func (r *Repository[E, T]) ToEntities(tables []T) []E {
+	entities := make([]E, len(tables))
+	for i, table := range tables {
+		entities[i] = r.toEntity(table)
+	}
+	return entities
+}
+
+func (r *Repository[E, T]) Create(ctx context.Context, entity E) (E, error) {
+	table := r.toTable(entity)
+	if err := r.DB(ctx).Create(&table).Error; err != nil {
+		var zero E
+		return zero, err
+	}
+
+	return r.toEntity(table), nil
+}
+
+func (r *Repository[E, T]) Update(ctx context.Context, entity E) (E, error) {
+	var zero E
+
+	table := r.toTable(entity)
+	result := r.DB(ctx).Updates(&table)
+	if result.Error != nil {
+		return zero, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return zero, gorm.ErrRecordNotFound
+	}
+
+	// Updates only writes non-zero fields, so reload the row to return the full entity.
+	if err := r.DB(ctx).Take(&table).Error; err != nil {
+		return zero, err
+	}
+
+	return r.toEntity(table), nil
+}
+
+// UpdateColumns writes columns as given, zero values included, to the rows
+// matched by scopes.
+func (r *Repository[E, T]) UpdateColumns(ctx context.Context, columns map[string]any, scopes ...Scope) error {
+	result := r.Query(ctx, scopes...).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository[E, T]) FindOne(ctx context.Context, scopes ...Scope) (E, error) {
+	var table T
+	if err := r.Query(ctx, scopes...).Take(&table).Error; err != nil {
+		var zero E
+		return zero, err
+	}
+
+	return r.toEntity(table), nil
+}
+
+func (r *Repository[E, T]) FindAll(ctx context.Context, scopes ...Scope) ([]E, error) {
+	var tables []T
+	if err := r.Query(ctx, scopes...).Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	return r.ToEntities(tables), nil
+}
+
+func (r *Repository[E, T]) Exists(ctx context.Context, scopes ...Scope) (bool, error) {
+	var table T
+	err := r.Query(ctx, scopes...).Take(&table).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Repository[E, T]) Count(ctx context.Context, scopes ...Scope) (int64, error) {
+	var count int64
+	if err := r.Query(ctx, scopes...).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Repository[E, T]) Delete(ctx context.Context, scopes ...Scope) error {
+	return r.DB(ctx).Scopes(toGormScopes(scopes)...).Delete(new(T)).Error
+}
+
+func (r *Repository[E, T]) Paginate(
+	ctx context.Context,
+	req pagination.Request,
+	scopes ...Scope,
+) ([]E, pagination.Response, error) {
+	req.Default()
+
+	var tables []T
+	if err := r.Query(ctx, scopes...).Scopes(pagination.Paginate(req)).Find(&tables).Error; err != nil {
+		return nil, pagination.Response{}, err
+	}
+
+	if req.SkipCount {
+		return r.ToEntities(tables), pagination.Response{Page: req.Page, PerPage: req.PerPage}, nil
+	}
+
+	count, err := r.Count(ctx, scopes...)
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+
+	return r.ToEntities(tables), pagination.Counted(req, count), nil
+}
+
+// PaginateKeyset returns the page after or before the request's cursor in the
+// order of keyset, fetching one row more than the page to tell whether
+// another follows.
+func PaginateKeyset[E any, T any, K any](
+	ctx context.Context,
+	r *Repository[E, T],
+	codec *pagination.CursorCodec,
+	req pagination.Request,
+	keyset pagination.Keyset[E, K],
+	scopes ...Scope,
+) ([]E, pagination.Response, error) {
+	req.Default()
+
+	cursor, key, err := keyset.Decode(codec, req)
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+
+	query := r.Query(ctx, scopes...)
+	direction, comparison := "ASC", ">"
+	if cursor != nil && cursor.Backward {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor != nil {
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", keyset.Column, comparison),
+			key, key, cursor.ID,
+		)
+	}
+
+	var tables []T
+	err = query.Order(keyset.Column + " " + direction).Order("id " + direction).Limit(req.PerPage + 1).Find(&tables).Error
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+
+	entities, meta, err := keyset.Page(codec, req, cursor, r.ToEntities(tables))
+	if err != nil || req.SkipCount {
+		return entities, meta, err
+	}
+
+	count, err := r.Count(ctx, scopes...)
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+	meta.Count = &count
+	return entities, meta, nil
+}
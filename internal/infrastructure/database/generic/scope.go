@@ -0,0 +1,102 @@
+package generic
+
+import (
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Scope func(db *gorm.DB) *gorm.DB
+
+func Where(query any, args ...any) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+func ByID(id any) Scope {
+	return Where("id = ?", id)
+}
+
+func OrderBy(order string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(order)
+	}
+}
+
+// Search matches rows where any of columns contains term, ignoring case. On
+// PostgreSQL it uses ILIKE, which the pg_trgm indexes created by the
+// migrations serve; other drivers compare lowered values.
+func Search(term string, columns ...string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if term == "" || len(columns) == 0 {
+			return db
+		}
+
+		pattern := "%" + escapeLike(term) + "%"
+		conditions := make([]string, len(columns))
+		args := make([]any, len(columns))
+		for i, column := range columns {
+			if isPostgres(db) {
+				conditions[i] = column + " ILIKE ? ESCAPE '\\'"
+				args[i] = pattern
+			} else {
+				conditions[i] = "LOWER(" + column + ") LIKE ? ESCAPE '\\'"
+				args[i] = strings.ToLower(pattern)
+			}
+		}
+
+		return db.Where(strings.Join(conditions, " OR "), args...)
+	}
+}
+
+// SearchRank orders rows by how closely their best matching column resembles
+// term, then by id. PostgreSQL ranks by trigram similarity; other drivers put
+// exact matches before prefixes before the rest. It replaces any other order.
+func SearchRank(term string, columns ...string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if term == "" || len(columns) == 0 {
+			return db
+		}
+
+		ranks := make([]string, len(columns))
+		var args []any
+		for i, column := range columns {
+			if isPostgres(db) {
+				ranks[i] = "similarity(" + column + ", ?)"
+				args = append(args, term)
+			} else {
+				ranks[i] = "CASE WHEN LOWER(" + column + ") = ? THEN 0 WHEN LOWER(" + column + ") LIKE ? ESCAPE '\\' THEN 1 ELSE 2 END"
+				args = append(args, strings.ToLower(term), strings.ToLower(escapeLike(term))+"%")
+			}
+		}
+
+		rank := ranks[0] + " ASC"
+		switch {
+		case isPostgres(db):
+			rank = "GREATEST(" + strings.Join(ranks, ", ") + ") DESC"
+		case len(ranks) > 1:
+			rank = "MIN(" + strings.Join(ranks, ", ") + ") ASC"
+		}
+
+		return db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: rank + ", id", Vars: args, WithoutParentheses: true}})
+	}
+}
+
+func escapeLike(term string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(term)
+}
+
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == config.DriverPostgres
+}
+
+func toGormScopes(scopes []Scope) []func(*gorm.DB) *gorm.DB {
+	gormScopes := make([]func(*gorm.DB) *gorm.DB, len(scopes))
+	for i, scope := range scopes {
+		gormScopes[i] = scope
+	}
+	return gormScopes
+}
@@ -0,0 +1,87 @@
+package generic
+
+import (
+	"context"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+	"gorm.io/gorm"
+)
+
+// Versioned is implemented by tables with a version column that every write
+// bumps, so writes can be made conditional on the version a client read.
+type Versioned interface {
+	SetVersion(version int64)
+}
+
+// UpdateVersioned writes the non-zero fields of entity, like Update, only if
+// its row is still at version, and moves the row to the next version. A row
+// that has moved on reports shared.ErrorVersionConflict.
+func UpdateVersioned[E any, T any, PT interface {
+	*T
+	Versioned
+}](ctx context.Context, r *Repository[E, T], entity E, version int64) (E, error) {
+	var zero E
+
+	table := r.toTable(entity)
+	PT(&table).SetVersion(version + 1)
+	result := r.DB(ctx).Model(&table).Where("version = ?", version).Updates(&table)
+	if result.Error != nil {
+		return zero, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Tell a missing row, reported as gorm.ErrRecordNotFound, from one
+		// at another version.
+		if err := r.DB(ctx).Take(&table).Error; err != nil {
+			return zero, err
+		}
+		return zero, shared.ErrorVersionConflict
+	}
+
+	if err := r.DB(ctx).Take(&table).Error; err != nil {
+		return zero, err
+	}
+
+	return r.toEntity(table), nil
+}
+
+// UpdateColumnsVersioned writes columns, like UpdateColumns, to the row
+// matched by scopes only if it is still at version, and bumps its version. A
+// row that has moved on reports shared.ErrorVersionConflict.
+func (r *Repository[E, T]) UpdateColumnsVersioned(ctx context.Context, version int64, columns map[string]any, scopes ...Scope) error {
+	columns["version"] = gorm.Expr("version + 1")
+	result := r.Query(ctx, scopes...).Where("version = ?", version).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		exists, err := r.Exists(ctx, scopes...)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+		return shared.ErrorVersionConflict
+	}
+
+	return nil
+}
+
+// DeleteVersioned deletes the row matched by scopes only if it is still at
+// version. Like Delete, it does nothing when no row matches.
+func (r *Repository[E, T]) DeleteVersioned(ctx context.Context, version int64, scopes ...Scope) error {
+	result := r.DB(ctx).Scopes(toGormScopes(scopes)...).Where("version = ?", version).Delete(new(T))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		exists, err := r.Exists(ctx, scopes...)
+		if err != nil || !exists {
+			return err
+		}
+		return shared.ErrorVersionConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+
+	"github.com/google/uuid"
+)
+
+type fileRepository struct {
+	mu          sync.RWMutex
+	files       []file.File
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
+}
+
+func NewFileRepository(clock port.ClockPort, idGenerator port.IDGeneratorPort) file.Repository {
+	return &fileRepository{
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *fileRepository) Create(_ context.Context, fileEntity file.File) (file.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fileEntity.ID.ID == uuid.Nil {
+		fileEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+
+	now := r.clock.Now()
+	fileEntity.CreatedAt = now
+	fileEntity.UpdatedAt = now
+	fileEntity.DeletedAt = nil
+
+	r.files = append(r.files, fileEntity)
+	return fileEntity, nil
+}
+
+func (r *fileRepository) GetFileByID(_ context.Context, id string) (file.File, error) {
+	return r.findOne(func(f file.File) bool { return f.ID.String() == id })
+}
+
+func (r *fileRepository) GetFileByStorageKey(_ context.Context, ownerID string, storageKey string) (file.File, error) {
+	return r.findOne(func(f file.File) bool { return f.OwnerID.String() == ownerID && f.StorageKey == storageKey })
+}
+
+func (r *fileRepository) FindByHash(_ context.Context, ownerID string, sha256 string) ([]file.File, error) {
+	files := r.findAll(func(f file.File) bool { return f.OwnerID.String() == ownerID && f.SHA256 == sha256 })
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].CreatedAt.Before(files[j].CreatedAt)
+	})
+	return files, nil
+}
+
+func (r *fileRepository) AddReferences(_ context.Context, id string, delta int64) (file.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, f := range r.files {
+		if f.DeletedAt == nil && f.ID.String() == id {
+			r.files[i].RefCount += delta
+			r.files[i].UpdatedAt = r.clock.Now()
+			return r.files[i], nil
+		}
+	}
+	return file.File{}, file.ErrorFileNotFound
+}
+
+func (r *fileRepository) SumSizeByOwner(_ context.Context, ownerID string) (int64, error) {
+	var total int64
+	for _, f := range r.findAll(func(f file.File) bool { return f.OwnerID.String() == ownerID }) {
+		total += f.Size
+	}
+	return total, nil
+}
+
+func (r *fileRepository) CountByStorageKey(_ context.Context, storageKey string) (int64, error) {
+	return int64(len(r.findAll(func(f file.File) bool { return f.StorageKey == storageKey }))), nil
+}
+
+func (r *fileRepository) FindUnreferenced(_ context.Context, before time.Time) ([]file.File, error) {
+	return r.findAll(func(f file.File) bool { return f.RefCount <= 0 && f.UpdatedAt.Before(before) }), nil
+}
+
+func (r *fileRepository) DeleteUnreferenced(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, f := range r.files {
+		if f.DeletedAt == nil && f.ID.String() == id && f.RefCount <= 0 {
+			now := r.clock.Now()
+			r.files[i].DeletedAt = &now
+			return nil
+		}
+	}
+	return file.ErrorFileNotFound
+}
+
+func (r *fileRepository) findOne(match func(f file.File) bool) (file.File, error) {
+	if files := r.findAll(match); len(files) > 0 {
+		return files[0], nil
+	}
+	return file.File{}, file.ErrorFileNotFound
+}
+
+func (r *fileRepository) findAll(match func(f file.File) bool) []file.File {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var files []file.File
+	for _, f := range r.files {
+		if f.DeletedAt == nil && match(f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type idempotencyRepository struct {
+	mu          sync.RWMutex
+	records     []idempotency.Record
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
+}
+
+func NewIdempotencyRepository(clock port.ClockPort, idGenerator port.IDGeneratorPort) idempotency.Repository {
+	return &idempotencyRepository{
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *idempotencyRepository) Create(_ context.Context, record idempotency.Record) (idempotency.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.index(func(stored idempotency.Record) bool {
+		return stored.Scope == record.Scope && stored.Key == record.Key
+	}); ok {
+		return idempotency.Record{}, gorm.ErrDuplicatedKey
+	}
+
+	if record.ID.ID == uuid.Nil {
+		record.ID = identity.NewID(r.idGenerator.NewID())
+	}
+	now := r.clock.Now()
+	record.CreatedAt = now
+	record.UpdatedAt = now
+
+	record = cloneRecord(record)
+	r.records = append(r.records, record)
+	return cloneRecord(record), nil
+}
+
+func (r *idempotencyRepository) GetByKey(_ context.Context, scope string, key string) (idempotency.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.index(func(stored idempotency.Record) bool {
+		return stored.Scope == scope && stored.Key == key
+	})
+	if !ok {
+		return idempotency.Record{}, gorm.ErrRecordNotFound
+	}
+	return cloneRecord(r.records[i]), nil
+}
+
+func (r *idempotencyRepository) Complete(_ context.Context, id string, response idempotency.Response) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index(func(stored idempotency.Record) bool { return stored.ID.String() == id })
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	r.records[i].Response = cloneRecord(idempotency.Record{Response: response}).Response
+	r.records[i].UpdatedAt = r.clock.Now()
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = slices.DeleteFunc(r.records, func(stored idempotency.Record) bool {
+		return stored.ID.String() == id
+	})
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before := len(r.records)
+	r.records = slices.DeleteFunc(r.records, func(stored idempotency.Record) bool {
+		return !now.Before(stored.ExpiresAt)
+	})
+	return before - len(r.records), nil
+}
+
+func (r *idempotencyRepository) index(match func(stored idempotency.Record) bool) (int, bool) {
+	i := slices.IndexFunc(r.records, match)
+	return i, i >= 0
+}
+
+func cloneRecord(record idempotency.Record) idempotency.Record {
+	if record.Header != nil {
+		record.Header = maps.Clone(record.Header)
+		for name, values := range record.Header {
+			record.Header[name] = slices.Clone(values)
+		}
+	}
+	record.Body = bytes.Clone(record.Body)
+	return record
+}
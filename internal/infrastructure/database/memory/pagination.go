@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+)
+
+func paginate[E any](entities []E, req pagination.Request) []E {
+	offset := req.GetOffset()
+	if offset >= len(entities) || offset < 0 {
+		return nil
+	}
+
+	end := offset + req.GetLimit()
+	if end > len(entities) {
+		end = len(entities)
+	}
+	return entities[offset:end]
+}
+
+// paginateKeyset mirrors generic.PaginateKeyset, with compare ordering the
+// sort keys the way the database would.
+func paginateKeyset[E any, K any](
+	entities []E,
+	codec *pagination.CursorCodec,
+	req pagination.Request,
+	keyset pagination.Keyset[E, K],
+	compare func(a, b K) int,
+) ([]E, pagination.Response, error) {
+	cursor, key, err := keyset.Decode(codec, req)
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+
+	order := func(a, b E) int {
+		if c := compare(keyset.Key(a), keyset.Key(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(keyset.ID(a), keyset.ID(b))
+	}
+	sorted := slices.SortedFunc(slices.Values(entities), order)
+	if cursor != nil && cursor.Backward {
+		slices.Reverse(sorted)
+	}
+
+	rows := make([]E, 0, req.PerPage+1)
+	for _, entity := range sorted {
+		if cursor != nil {
+			c := compare(keyset.Key(entity), key)
+			if c == 0 {
+				c = strings.Compare(keyset.ID(entity), cursor.ID)
+			}
+			if cursor.Backward && c >= 0 || !cursor.Backward && c <= 0 {
+				continue
+			}
+		}
+		if rows = append(rows, entity); len(rows) > req.PerPage {
+			break
+		}
+	}
+
+	page, meta, err := keyset.Page(codec, req, cursor, rows)
+	if err != nil {
+		return nil, pagination.Response{}, err
+	}
+	if !req.SkipCount {
+		count := int64(len(entities))
+		meta.Count = &count
+	}
+	return page, meta, nil
+}
+
+// matches reports whether entity passes every condition of query.
+func matches[E any](query pagination.Query[E], entity E) bool {
+	for _, condition := range query.Conditions {
+		value := condition.Value(entity)
+		if condition.Operator == pagination.OperatorIn {
+			if !slices.ContainsFunc(condition.Values, func(want any) bool { return compareValues(value, want) == 0 }) {
+				return false
+			}
+			continue
+		}
+
+		c := compareValues(value, condition.Values[0])
+		switch condition.Operator {
+		case pagination.OperatorEqual:
+			if c != 0 {
+				return false
+			}
+		case pagination.OperatorNotEqual:
+			if c == 0 {
+				return false
+			}
+		case pagination.OperatorGreater:
+			if c <= 0 {
+				return false
+			}
+		case pagination.OperatorGreaterOrEqual:
+			if c < 0 {
+				return false
+			}
+		case pagination.OperatorLess:
+			if c >= 0 {
+				return false
+			}
+		case pagination.OperatorLessOrEqual:
+			if c > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortBy orders entities by the orders of query and then by id, like
+// pagination.Query.Scope, and leaves them as they are when there are none.
+func sortBy[E any](entities []E, query pagination.Query[E], id func(E) string) {
+	if len(query.Orders) == 0 {
+		return
+	}
+	slices.SortStableFunc(entities, func(a, b E) int {
+		for _, order := range query.Orders {
+			c := compareValues(order.Value(a), order.Value(b))
+			if order.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(id(a), id(b))
+	})
+}
+
+// rankBySearch orders entities like generic.SearchRank does on drivers other
+// than PostgreSQL: exact matches of the lowered term before prefixes before
+// the rest, then by id.
+func rankBySearch[E any](entities []E, term string, id func(E) string, columns ...func(E) string) {
+	rank := func(entity E) int {
+		best := 2
+		for _, column := range columns {
+			value := strings.ToLower(column(entity))
+			switch {
+			case value == term:
+				best = min(best, 0)
+			case strings.HasPrefix(value, term):
+				best = min(best, 1)
+			}
+		}
+		return best
+	}
+	slices.SortFunc(entities, func(a, b E) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(id(a), id(b))
+	})
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case a:
+			return 1
+		default:
+			return -1
+		}
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	mu          sync.RWMutex
+	tokens      []refresh_token.RefreshToken
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
+}
+
+func NewRefreshTokenRepository(clock port.ClockPort, idGenerator port.IDGeneratorPort) refresh_token.Repository {
+	return &refreshTokenRepository{
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *refreshTokenRepository) Create(_ context.Context, refreshTokenEntity refresh_token.RefreshToken) (refresh_token.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.DeletedAt == nil && t.Token == refreshTokenEntity.Token {
+			return refresh_token.RefreshToken{}, gorm.ErrDuplicatedKey
+		}
+	}
+
+	if refreshTokenEntity.ID.ID == uuid.Nil {
+		refreshTokenEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+
+	now := r.clock.Now()
+	refreshTokenEntity.CreatedAt = now
+	refreshTokenEntity.UpdatedAt = now
+	refreshTokenEntity.DeletedAt = nil
+
+	r.tokens = append(r.tokens, refreshTokenEntity)
+	return refreshTokenEntity, nil
+}
+
+func (r *refreshTokenRepository) FindByUserID(_ context.Context, userID string) (refresh_token.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tokens {
+		if t.DeletedAt == nil && t.UserID.String() == userID {
+			return t, nil
+		}
+	}
+	return refresh_token.RefreshToken{}, gorm.ErrRecordNotFound
+}
+
+func (r *refreshTokenRepository) FindActiveByUserIDs(_ context.Context, userIDs []string) ([]refresh_token.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.clock.Now()
+	var tokens []refresh_token.RefreshToken
+	for _, t := range r.tokens {
+		if t.DeletedAt == nil && !t.ExpiresAt.Before(now) && slices.Contains(userIDs, t.UserID.String()) {
+			tokens = append(tokens, t)
+		}
+	}
+	slices.SortStableFunc(tokens, func(a, b refresh_token.RefreshToken) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return tokens, nil
+}
+
+func (r *refreshTokenRepository) DeleteByUserID(_ context.Context, userID string) error {
+	r.softDelete(func(t refresh_token.RefreshToken) bool { return t.UserID.String() == userID })
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteByToken(_ context.Context, token string) error {
+	r.softDelete(func(t refresh_token.RefreshToken) bool { return t.Token == token })
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteExpired(_ context.Context) error {
+	now := r.clock.Now()
+	r.softDelete(func(t refresh_token.RefreshToken) bool { return t.ExpiresAt.Before(now) })
+	return nil
+}
+
+func (r *refreshTokenRepository) softDelete(match func(t refresh_token.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	for i, t := range r.tokens {
+		if t.DeletedAt == nil && match(t) {
+			r.tokens[i].DeletedAt = &now
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"log"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+)
+
+type (
+	contextKey struct{}
+
+	session struct {
+		onCommit   []application.Hook
+		onRollback []application.Hook
+	}
+
+	// unitOfWork runs hooks like the GORM implementation but cannot undo writes
+	// made to the in-memory repositories when fn fails.
+	unitOfWork struct{}
+)
+
+func NewUnitOfWork() application.UnitOfWork {
+	return &unitOfWork{}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	parent, nested := ctx.Value(contextKey{}).(*session)
+	s := &session{}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = application.RecoveredFromPanic(r)
+		}
+
+		if err != nil {
+			runHooks(ctx, s.onRollback)
+			return
+		}
+
+		if nested {
+			parent.onCommit = append(parent.onCommit, s.onCommit...)
+			parent.onRollback = append(parent.onRollback, s.onRollback...)
+			return
+		}
+		runHooks(ctx, s.onCommit)
+	}()
+
+	return fn(context.WithValue(ctx, contextKey{}, s))
+}
+
+func (u *unitOfWork) OnCommit(ctx context.Context, hook application.Hook) {
+	s, ok := ctx.Value(contextKey{}).(*session)
+	if !ok {
+		runHooks(ctx, []application.Hook{hook})
+		return
+	}
+	s.onCommit = append(s.onCommit, hook)
+}
+
+func (u *unitOfWork) OnRollback(ctx context.Context, hook application.Hook) {
+	s, ok := ctx.Value(contextKey{}).(*session)
+	if !ok {
+		return
+	}
+	s.onRollback = append(s.onRollback, hook)
+}
+
+func runHooks(ctx context.Context, hooks []application.Hook) {
+	for _, hook := range hooks {
+		if err := hook(context.WithoutCancel(ctx)); err != nil {
+			log.Println("Error running transaction hook:", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type uploadRepository struct {
+	mu          sync.RWMutex
+	uploads     []file.Upload
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
+}
+
+func NewUploadRepository(clock port.ClockPort, idGenerator port.IDGeneratorPort) file.UploadRepository {
+	return &uploadRepository{
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *uploadRepository) Create(_ context.Context, uploadEntity file.Upload) (file.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if uploadEntity.ID.ID == uuid.Nil {
+		uploadEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+
+	now := r.clock.Now()
+	uploadEntity.CreatedAt = now
+	uploadEntity.UpdatedAt = now
+	uploadEntity.DeletedAt = nil
+	uploadEntity.Metadata = maps.Clone(uploadEntity.Metadata)
+	uploadEntity.Parts = slices.Clone(uploadEntity.Parts)
+
+	r.uploads = append(r.uploads, uploadEntity)
+	return clone(uploadEntity), nil
+}
+
+func (r *uploadRepository) GetUploadByID(_ context.Context, id string) (file.Upload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.index(id)
+	if !ok {
+		return file.Upload{}, gorm.ErrRecordNotFound
+	}
+	return clone(r.uploads[i]), nil
+}
+
+func (r *uploadRepository) AdvanceOffset(_ context.Context, id string, from int64, to int64, partKey string) (file.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index(id)
+	if !ok || r.uploads[i].Offset != from {
+		return file.Upload{}, gorm.ErrRecordNotFound
+	}
+
+	r.uploads[i].Offset = to
+	r.uploads[i].Parts = append(slices.Clone(r.uploads[i].Parts), partKey)
+	r.uploads[i].UpdatedAt = r.clock.Now()
+	return clone(r.uploads[i]), nil
+}
+
+func (r *uploadRepository) MarkCompleted(_ context.Context, id string, fileID identity.ID) (file.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index(id)
+	if !ok {
+		return file.Upload{}, gorm.ErrRecordNotFound
+	}
+
+	r.uploads[i].FileID = &fileID
+	r.uploads[i].UpdatedAt = r.clock.Now()
+	return clone(r.uploads[i]), nil
+}
+
+func (r *uploadRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.index(id); ok {
+		now := r.clock.Now()
+		r.uploads[i].DeletedAt = &now
+	}
+	return nil
+}
+
+func (r *uploadRepository) FindExpired(_ context.Context, now time.Time) ([]file.Upload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var expired []file.Upload
+	for _, u := range r.uploads {
+		if u.DeletedAt == nil && u.FileID == nil && u.ExpiresAt.Before(now) {
+			expired = append(expired, clone(u))
+		}
+	}
+	return expired, nil
+}
+
+func (r *uploadRepository) index(id string) (int, bool) {
+	for i, u := range r.uploads {
+		if u.DeletedAt == nil && u.ID.String() == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func clone(uploadEntity file.Upload) file.Upload {
+	uploadEntity.Metadata = maps.Clone(uploadEntity.Metadata)
+	uploadEntity.Parts = slices.Clone(uploadEntity.Parts)
+	return uploadEntity
+}
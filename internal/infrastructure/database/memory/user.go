@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	mu          sync.RWMutex
+	users       []user.User
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
+	cursors     *pagination.CursorCodec
+}
+
+func NewUserRepository(clock port.ClockPort, idGenerator port.IDGeneratorPort, cursors *pagination.CursorCodec) user.Repository {
+	return &userRepository{
+		clock:       clock,
+		idGenerator: idGenerator,
+		cursors:     cursors,
+	}
+}
+
+func (r *userRepository) Register(_ context.Context, userEntity user.User) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.find(func(u user.User) bool { return u.Email == userEntity.Email }); ok {
+		return user.User{}, gorm.ErrDuplicatedKey
+	}
+
+	if userEntity.ID.ID == uuid.Nil {
+		userEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+	if _, ok := r.index(userEntity.ID); ok {
+		return user.User{}, gorm.ErrDuplicatedKey
+	}
+
+	now := r.clock.Now()
+	if userEntity.Role.Name == "" {
+		userEntity.Role = user.NewRoleFromTable(user.RoleUser)
+	}
+	if userEntity.Version == 0 {
+		userEntity.Version = 1
+	}
+	userEntity.CreatedAt = now
+	userEntity.UpdatedAt = now
+	userEntity.DeletedAt = nil
+
+	r.users = append(r.users, userEntity)
+	return userEntity, nil
+}
+
+func (r *userRepository) GetAllUsersWithPagination(_ context.Context, req pagination.Request) (pagination.ResponseWithData, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req.Default()
+
+	query, err := user.Fields.Parse(req)
+	if err != nil {
+		return pagination.ResponseWithData{}, err
+	}
+
+	search := strings.ToLower(req.Search)
+	matched := make([]user.User, 0, len(r.users))
+	for _, u := range r.users {
+		if u.DeletedAt != nil {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(u.Name), search) &&
+			!strings.Contains(strings.ToLower(u.Email), search) {
+			continue
+		}
+		if !matches(query, u) {
+			continue
+		}
+		matched = append(matched, u)
+	}
+	sortBy(matched, query, func(u user.User) string { return u.ID.String() })
+	if search != "" && len(query.Orders) == 0 && !req.IsKeyset() {
+		rankBySearch(matched, search, func(u user.User) string { return u.ID.String() },
+			func(u user.User) string { return u.Name }, func(u user.User) string { return u.Email })
+	}
+
+	var (
+		page []user.User
+		meta pagination.Response
+	)
+	switch {
+	case req.IsKeyset():
+		page, meta, err = paginateKeyset(matched, r.cursors, req, user.CreatedAtKeyset, time.Time.Compare)
+		if err != nil {
+			return pagination.ResponseWithData{}, err
+		}
+	case req.SkipCount:
+		page, meta = paginate(matched, req), pagination.Response{Page: req.Page, PerPage: req.PerPage}
+	default:
+		page, meta = paginate(matched, req), pagination.Counted(req, int64(len(matched)))
+	}
+
+	data := make([]any, 0, len(page))
+	for _, u := range page {
+		data = append(data, u)
+	}
+
+	return pagination.ResponseWithData{
+		Data:     data,
+		Response: meta,
+	}, nil
+}
+
+func (r *userRepository) GetUserByID(_ context.Context, id string) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.find(func(u user.User) bool { return u.ID.String() == id })
+	if !ok {
+		return user.User{}, gorm.ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func (r *userRepository) GetUserByEmail(_ context.Context, email string) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.find(func(u user.User) bool { return u.Email == email })
+	if !ok {
+		return user.User{}, gorm.ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func (r *userRepository) CheckEmail(ctx context.Context, email string) (user.User, bool, error) {
+	u, err := r.GetUserByEmail(ctx, email)
+	if err != nil {
+		return user.User{}, false, err
+	}
+	return u, true, nil
+}
+
+func (r *userRepository) Update(_ context.Context, userEntity user.User) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index(userEntity.ID)
+	if !ok {
+		return user.User{}, gorm.ErrRecordNotFound
+	}
+
+	stored := r.users[i]
+	if stored.Version != userEntity.Version {
+		return user.User{}, shared.ErrorVersionConflict
+	}
+	if userEntity.Email != "" && userEntity.Email != stored.Email {
+		if _, taken := r.find(func(u user.User) bool { return u.Email == userEntity.Email }); taken {
+			return user.User{}, gorm.ErrDuplicatedKey
+		}
+	}
+
+	// Mirror GORM's Updates, which only writes non-zero fields.
+	if userEntity.Name != "" {
+		stored.Name = userEntity.Name
+	}
+	if userEntity.Email != "" {
+		stored.Email = userEntity.Email
+	}
+	if userEntity.PhoneNumber != "" {
+		stored.PhoneNumber = userEntity.PhoneNumber
+	}
+	if userEntity.Password.Password != "" {
+		stored.Password = userEntity.Password
+	}
+	if userEntity.Role.Name != "" {
+		stored.Role = userEntity.Role
+	}
+	if userEntity.ImageUrl.Path != "" {
+		stored.ImageUrl = userEntity.ImageUrl
+	}
+	if userEntity.IsVerified {
+		stored.IsVerified = true
+	}
+	stored.Version++
+	stored.UpdatedAt = r.clock.Now()
+
+	r.users[i] = stored
+	return stored, nil
+}
+
+func (r *userRepository) UpdateImageUrl(_ context.Context, id string, version int64, imageUrl shared.URL) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, u := range r.users {
+		if u.DeletedAt == nil && u.ID.String() == id {
+			if u.Version != version {
+				return user.User{}, shared.ErrorVersionConflict
+			}
+			r.users[i].ImageUrl = imageUrl
+			r.users[i].Version++
+			r.users[i].UpdatedAt = r.clock.Now()
+			return r.users[i], nil
+		}
+	}
+	return user.User{}, gorm.ErrRecordNotFound
+}
+
+func (r *userRepository) Delete(_ context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, u := range r.users {
+		if u.DeletedAt == nil && u.ID.String() == id {
+			if u.Version != version {
+				return shared.ErrorVersionConflict
+			}
+			now := r.clock.Now()
+			r.users[i].DeletedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *userRepository) find(match func(u user.User) bool) (user.User, bool) {
+	for _, u := range r.users {
+		if u.DeletedAt == nil && match(u) {
+			return u, true
+		}
+	}
+	return user.User{}, false
+}
+
+func (r *userRepository) index(id identity.ID) (int, bool) {
+	for i, u := range r.users {
+		if u.DeletedAt == nil && u.ID.ID == id.ID {
+			return i, true
+		}
+	}
+	return -1, false
+}
@@ -5,30 +5,84 @@ import (
 	"os"
 
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var entities = []interface{}{
-	&table.User{},
-	&table.RefreshToken{},
-}
-
+// Migrate creates or updates the tables of every registered module, then runs
+// their migration steps.
 func Migrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(entities...); err != nil {
+	if err := db.AutoMigrate(module.Tables()...); err != nil {
 		return err
 	}
 
+	for _, migration := range module.Migrations() {
+		if err := migration.Run(db); err != nil {
+			return fmt.Errorf("migration %s: %w", migration.Name, err)
+		}
+	}
+
 	return nil
 }
 
+// TrigramIndexes creates pg_trgm GIN indexes on the columns of entity's table,
+// which back generic.Search on PostgreSQL, where ILIKE with a leading wildcard
+// cannot use a B-tree index. It does nothing on other drivers.
+func TrigramIndexes(entity any, columns ...string) module.Migration {
+	return module.Migration{
+		Name: "trigram indexes",
+		Run: func(db *gorm.DB) error {
+			if db.Dialector.Name() != config.DriverPostgres {
+				return nil
+			}
+			if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+				return err
+			}
+
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(entity); err != nil {
+				return err
+			}
+			for _, column := range columns {
+				index := fmt.Sprintf("idx_%s_%s_trgm", stmt.Schema.Table, column)
+				err := db.Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING gin (? gin_trgm_ops)",
+					clause.Column{Name: index}, clause.Table{Name: stmt.Schema.Table}, clause.Column{Name: column}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// DropIndexes drops the named indexes of entity's table where they still
+// exist, for indexes a table no longer declares.
+func DropIndexes(entity any, indexes ...string) module.Migration {
+	return module.Migration{
+		Name: "drop indexes",
+		Run: func(db *gorm.DB) error {
+			for _, index := range indexes {
+				if !db.Migrator().HasIndex(entity, index) {
+					continue
+				}
+				if err := db.Migrator().DropIndex(entity, index); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
 func Rollback(db *gorm.DB) error {
 	if os.Getenv("APP_ENV") == config.RunProduction {
 		return fmt.Errorf("rollback is not allowed for production environment")
 	}
 
-	if err := db.Migrator().DropTable(entities...); err != nil {
+	if err := db.Migrator().DropTable(module.Tables()...); err != nil {
 		return err
 	}
 
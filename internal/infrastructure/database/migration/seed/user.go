@@ -3,8 +3,10 @@ package seed
 import (
 	"log"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/id_generator"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration/data"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
 
@@ -12,6 +14,7 @@ import (
 )
 
 func User(db *gorm.DB) error {
+	idGenerator := id_generator.NewUUIDAdapter()
 	for _, userData := range data.UserSeedData() {
 		var existingUser table.User
 		if err := db.Where("email = ?", userData.Email).First(&existingUser).Error; err == nil {
@@ -37,6 +40,7 @@ func User(db *gorm.DB) error {
 		}
 
 		userEntity := user.User{
+			ID:          identity.NewID(idGenerator.NewID()),
 			Name:        userData.Name,
 			Email:       userData.Email,
 			PhoneNumber: userData.PhoneNumber,
@@ -1,14 +1,16 @@
 package migration
 
 import (
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration/seed"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
 
 	"gorm.io/gorm"
 )
 
 func Seeder(db *gorm.DB) error {
-	if err := seed.User(db); err != nil {
-		return err
+	for _, m := range module.Modules() {
+		if err := m.Seed(db); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/generic"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+
+	"gorm.io/gorm"
+)
+
+type fileRepository struct {
+	base        *generic.Repository[file.File, table.File]
+	idGenerator port.IDGeneratorPort
+}
+
+func NewFileRepository(injector do.Injector) file.Repository {
+	db := do.MustInvoke[*transaction.Repository](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &fileRepository{
+		base:        generic.NewRepository(db, table.FileEntityToTable, table.FileTableToEntity),
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *fileRepository) Create(ctx context.Context, fileEntity file.File) (file.File, error) {
+	if fileEntity.ID.ID == uuid.Nil {
+		fileEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+	return r.base.Create(ctx, fileEntity)
+}
+
+func (r *fileRepository) GetFileByID(ctx context.Context, id string) (file.File, error) {
+	fileEntity, err := r.base.FindOne(ctx, generic.ByID(id))
+	return fileEntity, fileNotFound(err)
+}
+
+func (r *fileRepository) GetFileByStorageKey(ctx context.Context, ownerID string, storageKey string) (file.File, error) {
+	fileEntity, err := r.base.FindOne(ctx, generic.Where("owner_id = ? AND storage_key = ?", ownerID, storageKey))
+	return fileEntity, fileNotFound(err)
+}
+
+func (r *fileRepository) FindByHash(ctx context.Context, ownerID string, sha256 string) ([]file.File, error) {
+	return r.base.FindAll(ctx, generic.Where("owner_id = ? AND sha256 = ?", ownerID, sha256), generic.OrderBy("created_at ASC"))
+}
+
+func (r *fileRepository) AddReferences(ctx context.Context, id string, delta int64) (file.File, error) {
+	err := r.base.UpdateColumns(ctx, map[string]any{"ref_count": gorm.Expr("ref_count + ?", delta)}, generic.ByID(id))
+	if err != nil {
+		return file.File{}, fileNotFound(err)
+	}
+
+	return r.GetFileByID(ctx, id)
+}
+
+func (r *fileRepository) SumSizeByOwner(ctx context.Context, ownerID string) (int64, error) {
+	var total int64
+	err := r.base.Query(ctx, generic.Where("owner_id = ?", ownerID)).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *fileRepository) CountByStorageKey(ctx context.Context, storageKey string) (int64, error) {
+	return r.base.Count(ctx, generic.Where("storage_key = ?", storageKey))
+}
+
+func (r *fileRepository) FindUnreferenced(ctx context.Context, before time.Time) ([]file.File, error) {
+	return r.base.FindAll(ctx, generic.Where("ref_count <= 0 AND updated_at < ?", before))
+}
+
+func (r *fileRepository) DeleteUnreferenced(ctx context.Context, id string) error {
+	result := r.base.Query(ctx, generic.ByID(id), generic.Where("ref_count <= 0")).Delete(&table.File{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return file.ErrorFileNotFound
+	}
+
+	return nil
+}
+
+// fileNotFound reports a missing row as file.ErrorFileNotFound, so the domain
+// does not depend on GORM's errors.
+func fileNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return file.ErrorFileNotFound
+	}
+	return err
+}
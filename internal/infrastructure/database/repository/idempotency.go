@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/generic"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+)
+
+type idempotencyRepository struct {
+	base        *generic.Repository[idempotency.Record, table.IdempotencyKey]
+	idGenerator port.IDGeneratorPort
+}
+
+func NewIdempotencyRepository(injector do.Injector) idempotency.Repository {
+	db := do.MustInvoke[*transaction.Repository](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &idempotencyRepository{
+		base:        generic.NewRepository(db, table.IdempotencyKeyEntityToTable, table.IdempotencyKeyTableToEntity),
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *idempotencyRepository) Create(ctx context.Context, record idempotency.Record) (idempotency.Record, error) {
+	if record.ID.ID == uuid.Nil {
+		record.ID = identity.NewID(r.idGenerator.NewID())
+	}
+	return r.base.Create(ctx, record)
+}
+
+func (r *idempotencyRepository) GetByKey(ctx context.Context, scope string, key string) (idempotency.Record, error) {
+	return r.base.FindOne(ctx, generic.Where("scope = ? AND key = ?", scope, key))
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, id string, response idempotency.Response) error {
+	row := r.base.ToTable(idempotency.Record{Response: response})
+	return r.base.UpdateColumns(ctx, map[string]any{
+		"status": row.Status,
+		"header": row.Header,
+		"body":   row.Body,
+	}, generic.ByID(id))
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, id string) error {
+	return r.base.Delete(ctx, generic.ByID(id))
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result := r.base.DB(ctx).Where("expires_at <= ?", now).Delete(&table.IdempotencyKey{})
+	return int(result.RowsAffected), result.Error
+}
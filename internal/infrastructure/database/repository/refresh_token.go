@@ -2,114 +2,62 @@ package repository
 
 import (
 	"context"
-	"time"
-
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/generic"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/validation"
+	"github.com/google/uuid"
 	"github.com/samber/do/v2"
 )
 
 type refreshTokenRepository struct {
-	db *transaction.Repository
+	base        *generic.Repository[refresh_token.RefreshToken, table.RefreshToken]
+	clock       port.ClockPort
+	idGenerator port.IDGeneratorPort
 }
 
 func NewRefreshTokenRepository(injector do.Injector) refresh_token.Repository {
 	db := do.MustInvoke[*transaction.Repository](injector)
-	return &refreshTokenRepository{db: db}
-}
-
-func (r refreshTokenRepository) Create(ctx context.Context, tx interface{}, refreshTokenEntity refresh_token.RefreshToken) (refresh_token.RefreshToken, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return refresh_token.RefreshToken{}, err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	refreshTokenTable := table.RefreshTokenEntityToTable(refreshTokenEntity)
-	if err = db.WithContext(ctx).Create(&refreshTokenTable).Error; err != nil {
-		return refresh_token.RefreshToken{}, err
+	clock := do.MustInvoke[port.ClockPort](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &refreshTokenRepository{
+		base:        generic.NewRepository(db, table.RefreshTokenEntityToTable, table.RefreshTokenTableToEntity),
+		clock:       clock,
+		idGenerator: idGenerator,
 	}
-
-	refreshTokenEntity = table.RefreshTokenTableToEntity(refreshTokenTable)
-	return refreshTokenEntity, nil
 }
 
-func (r refreshTokenRepository) FindByUserID(ctx context.Context, tx interface{}, userID string) (refresh_token.RefreshToken, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return refresh_token.RefreshToken{}, err
+func (r refreshTokenRepository) Create(ctx context.Context, refreshTokenEntity refresh_token.RefreshToken) (refresh_token.RefreshToken, error) {
+	if refreshTokenEntity.ID.ID == uuid.Nil {
+		refreshTokenEntity.ID = identity.NewID(r.idGenerator.NewID())
 	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	var refreshTokenTable table.RefreshToken
-	if err = db.WithContext(ctx).Where("user_id = ?", userID).Take(&refreshTokenTable).Error; err != nil {
-		return refresh_token.RefreshToken{}, err
-	}
-
-	refreshTokenEntity := table.RefreshTokenTableToEntity(refreshTokenTable)
-	return refreshTokenEntity, nil
+	return r.base.Create(ctx, refreshTokenEntity)
 }
 
-func (r refreshTokenRepository) DeleteByUserID(ctx context.Context, tx interface{}, userID string) error {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	if err = db.WithContext(ctx).Where("user_id = ?", userID).Delete(&table.RefreshToken{}).Error; err != nil {
-		return err
-	}
-
-	return nil
+func (r refreshTokenRepository) FindByUserID(ctx context.Context, userID string) (refresh_token.RefreshToken, error) {
+	return r.base.FindOne(ctx, generic.Where("user_id = ?", userID))
 }
 
-func (r refreshTokenRepository) DeleteByToken(ctx context.Context, tx interface{}, token string) error {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	if err = db.WithContext(ctx).Where("token = ?", token).Delete(&table.RefreshToken{}).Error; err != nil {
-		return err
+func (r refreshTokenRepository) FindActiveByUserIDs(ctx context.Context, userIDs []string) ([]refresh_token.RefreshToken, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
 	}
-
-	return nil
+	return r.base.FindAll(ctx,
+		generic.Where("user_id IN ? AND expires_at >= ?", userIDs, r.clock.Now()),
+		generic.OrderBy("created_at, id"),
+	)
 }
 
-func (r refreshTokenRepository) DeleteExpired(ctx context.Context, tx interface{}) error {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
+func (r refreshTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.base.Delete(ctx, generic.Where("user_id = ?", userID))
+}
 
-	if err = db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&table.RefreshToken{}).Error; err != nil {
-		return err
-	}
+func (r refreshTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	return r.base.Delete(ctx, generic.Where("token = ?", token))
+}
 
-	return nil
+func (r refreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	return r.base.Delete(ctx, generic.Where("expires_at < ?", r.clock.Now()))
 }
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/generic"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+)
+
+type uploadRepository struct {
+	base        *generic.Repository[file.Upload, table.Upload]
+	idGenerator port.IDGeneratorPort
+}
+
+func NewUploadRepository(injector do.Injector) file.UploadRepository {
+	db := do.MustInvoke[*transaction.Repository](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &uploadRepository{
+		base:        generic.NewRepository(db, table.UploadEntityToTable, table.UploadTableToEntity),
+		idGenerator: idGenerator,
+	}
+}
+
+func (r *uploadRepository) Create(ctx context.Context, uploadEntity file.Upload) (file.Upload, error) {
+	if uploadEntity.ID.ID == uuid.Nil {
+		uploadEntity.ID = identity.NewID(r.idGenerator.NewID())
+	}
+	return r.base.Create(ctx, uploadEntity)
+}
+
+func (r *uploadRepository) GetUploadByID(ctx context.Context, id string) (file.Upload, error) {
+	return r.base.FindOne(ctx, generic.ByID(id))
+}
+
+func (r *uploadRepository) AdvanceOffset(ctx context.Context, id string, from int64, to int64, partKey string) (file.Upload, error) {
+	current, err := r.base.FindOne(ctx, generic.ByID(id), generic.Where("upload_offset = ?", from))
+	if err != nil {
+		return file.Upload{}, err
+	}
+
+	// The parts only change along with the offset, so the condition on the
+	// offset also keeps the parts read above current.
+	parts := table.UploadEntityToTable(file.Upload{Parts: append(current.Parts, partKey)}).Parts
+	err = r.base.UpdateColumns(ctx, map[string]any{"upload_offset": to, "parts": parts}, generic.ByID(id), generic.Where("upload_offset = ?", from))
+	if err != nil {
+		return file.Upload{}, err
+	}
+
+	return r.base.FindOne(ctx, generic.ByID(id))
+}
+
+func (r *uploadRepository) MarkCompleted(ctx context.Context, id string, fileID identity.ID) (file.Upload, error) {
+	if err := r.base.UpdateColumns(ctx, map[string]any{"file_id": fileID.ID}, generic.ByID(id)); err != nil {
+		return file.Upload{}, err
+	}
+
+	return r.base.FindOne(ctx, generic.ByID(id))
+}
+
+func (r *uploadRepository) Delete(ctx context.Context, id string) error {
+	return r.base.Delete(ctx, generic.ByID(id))
+}
+
+func (r *uploadRepository) FindExpired(ctx context.Context, now time.Time) ([]file.Upload, error) {
+	return r.base.FindAll(ctx, generic.Where("file_id IS NULL AND expires_at < ?", now))
+}
@@ -3,183 +3,104 @@ package repository
 import (
 	"context"
 
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/generic"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/validation"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/google/uuid"
 	"github.com/samber/do/v2"
 )
 
 type userRepository struct {
-	db *transaction.Repository
+	base        *generic.Repository[user.User, table.User]
+	cursors     *pagination.CursorCodec
+	idGenerator port.IDGeneratorPort
 }
 
 func NewUserRepository(injector do.Injector) user.Repository {
 	db := do.MustInvoke[*transaction.Repository](injector)
-	return &userRepository{db: db}
-}
-
-func (r *userRepository) Register(ctx context.Context, tx interface{}, userEntity user.User) (user.User, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return user.User{}, err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
+	cursors := do.MustInvoke[*pagination.CursorCodec](injector)
+	idGenerator := do.MustInvoke[port.IDGeneratorPort](injector)
+	return &userRepository{
+		base:        generic.NewRepository(db, table.UserEntityToTable, table.UserTableToEntity),
+		cursors:     cursors,
+		idGenerator: idGenerator,
 	}
+}
 
-	userTable := table.UserEntityToTable(userEntity)
-	if err = db.WithContext(ctx).Create(&userTable).Error; err != nil {
-		return user.User{}, err
+func (r *userRepository) Register(ctx context.Context, userEntity user.User) (user.User, error) {
+	if userEntity.ID.ID == uuid.Nil {
+		userEntity.ID = identity.NewID(r.idGenerator.NewID())
 	}
-
-	userEntity = table.UserTableToEntity(userTable)
-	return userEntity, nil
+	return r.base.Create(ctx, userEntity)
 }
 
-func (r *userRepository) GetAllUsersWithPagination(ctx context.Context, tx interface{}, req pagination.Request) (pagination.ResponseWithData, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
+func (r *userRepository) GetAllUsersWithPagination(ctx context.Context, req pagination.Request) (pagination.ResponseWithData, error) {
+	query, err := user.Fields.Parse(req)
 	if err != nil {
 		return pagination.ResponseWithData{}, err
 	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
+	scopes := []generic.Scope{generic.Search(req.Search, "name", "email"), query.Scope()}
+	if req.Search != "" && len(query.Orders) == 0 && !req.IsKeyset() {
+		scopes = append(scopes, generic.SearchRank(req.Search, "name", "email"))
 	}
 
-	var userTables []table.User
-	var count int64
-
-	req.Default()
-
-	query := db.WithContext(ctx).Model(&table.User{})
-	if req.Search != "" {
-		query = query.Where("name LIKE ? OR email LIKE ?", "%"+req.Search+"%", "%"+req.Search+"%")
+	var (
+		users []user.User
+		meta  pagination.Response
+	)
+	if req.IsKeyset() {
+		users, meta, err = generic.PaginateKeyset(ctx, r.base, r.cursors, req, user.CreatedAtKeyset, scopes...)
+	} else {
+		users, meta, err = r.base.Paginate(ctx, req, scopes...)
 	}
-
-	if err = query.Count(&count).Error; err != nil {
-		return pagination.ResponseWithData{}, err
-	}
-
-	if err = query.Scopes(pagination.Paginate(req)).Find(&userTables).Error; err != nil {
+	if err != nil {
 		return pagination.ResponseWithData{}, err
 	}
 
-	totalPage := pagination.TotalPage(count, int64(req.PerPage))
-
-	data := make([]any, len(userTables))
-	for i, userTable := range userTables {
-		data[i] = table.UserTableToEntity(userTable)
+	data := make([]any, len(users))
+	for i, userEntity := range users {
+		data[i] = userEntity
 	}
 	return pagination.ResponseWithData{
-		Data: data,
-		Response: pagination.Response{
-			Page:    req.Page,
-			PerPage: req.PerPage,
-			Count:   count,
-			MaxPage: totalPage,
-		},
-	}, err
+		Data:     data,
+		Response: meta,
+	}, nil
 }
 
-func (r *userRepository) GetUserByID(ctx context.Context, tx interface{}, id string) (user.User, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return user.User{}, err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	var userTable table.User
-	if err = db.WithContext(ctx).Where("id = ?", id).Take(&userTable).Error; err != nil {
-		return user.User{}, err
-	}
-
-	userEntity := table.UserTableToEntity(userTable)
-	return userEntity, nil
+func (r *userRepository) GetUserByID(ctx context.Context, id string) (user.User, error) {
+	return r.base.FindOne(ctx, generic.ByID(id))
 }
 
-func (r *userRepository) GetUserByEmail(ctx context.Context, tx interface{}, email string) (user.User, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return user.User{}, err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	var userTable table.User
-	if err = db.WithContext(ctx).Where("email = ?", email).Take(&userTable).Error; err != nil {
-		return user.User{}, err
-	}
-
-	userEntity := table.UserTableToEntity(userTable)
-	return userEntity, nil
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
+	return r.base.FindOne(ctx, generic.Where("email = ?", email))
 }
 
-func (r *userRepository) CheckEmail(ctx context.Context, tx interface{}, email string) (user.User, bool, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
+func (r *userRepository) CheckEmail(ctx context.Context, email string) (user.User, bool, error) {
+	userEntity, err := r.base.FindOne(ctx, generic.Where("email = ?", email))
 	if err != nil {
 		return user.User{}, false, err
 	}
 
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	var userTable table.User
-	if err = db.WithContext(ctx).Where("email = ?", email).Take(&userTable).Error; err != nil {
-		return user.User{}, false, err
-	}
-
-	userEntity := table.UserTableToEntity(userTable)
 	return userEntity, true, nil
 }
 
-func (r *userRepository) Update(ctx context.Context, tx interface{}, userEntity user.User) (user.User, error) {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return user.User{}, err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
+func (r *userRepository) Update(ctx context.Context, userEntity user.User) (user.User, error) {
+	return generic.UpdateVersioned(ctx, r.base, userEntity, userEntity.Version)
+}
 
-	userTable := table.UserEntityToTable(userEntity)
-	if err = db.WithContext(ctx).Updates(&userTable).Error; err != nil {
+func (r *userRepository) UpdateImageUrl(ctx context.Context, id string, version int64, imageUrl shared.URL) (user.User, error) {
+	if err := r.base.UpdateColumnsVersioned(ctx, version, map[string]any{"image_url": imageUrl.Path}, generic.ByID(id)); err != nil {
 		return user.User{}, err
 	}
 
-	userEntity = table.UserTableToEntity(userTable)
-	return userEntity, nil
+	return r.base.FindOne(ctx, generic.ByID(id))
 }
 
-func (r *userRepository) Delete(ctx context.Context, tx interface{}, id string) error {
-	validatedTransaction, err := validation.ValidateTransaction(tx)
-	if err != nil {
-		return err
-	}
-
-	db := validatedTransaction.DB()
-	if db == nil {
-		db = r.db.DB()
-	}
-
-	if err = db.WithContext(ctx).Where("id = ?", id).Delete(&table.User{}).Error; err != nil {
-		return err
-	}
-
-	return nil
+func (r *userRepository) Delete(ctx context.Context, id string, version int64) error {
+	return r.base.DeleteVersioned(ctx, version, generic.ByID(id))
 }
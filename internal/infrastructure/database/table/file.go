@@ -0,0 +1,69 @@
+package table
+
+import (
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type File struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;column:id"`
+	OwnerID     uuid.UUID      `gorm:"type:uuid;not null;index:idx_files_owner_sha256,priority:1;column:owner_id"`
+	StorageKey  string         `gorm:"type:varchar(255);not null;index;column:storage_key"`
+	Name        string         `gorm:"type:varchar(255);column:name"`
+	Size        int64          `gorm:"not null;column:size"`
+	ContentType string         `gorm:"type:varchar(255);column:content_type"`
+	SHA256      string         `gorm:"type:char(64);not null;index:idx_files_owner_sha256,priority:2;column:sha256"`
+	RefCount    int64          `gorm:"not null;default:0;index;column:ref_count"`
+	CreatedAt   time.Time      `gorm:"column:created_at"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
+
+	Owner *User `gorm:"foreignKey:OwnerID"`
+}
+
+func FileEntityToTable(entity file.File) File {
+	var deletedAtTime time.Time
+	if entity.Timestamp.DeletedAt != nil {
+		deletedAtTime = *entity.Timestamp.DeletedAt
+	}
+	return File{
+		ID:          entity.ID.ID,
+		OwnerID:     entity.OwnerID.ID,
+		StorageKey:  entity.StorageKey,
+		Name:        entity.Name,
+		Size:        entity.Size,
+		ContentType: entity.ContentType,
+		SHA256:      entity.SHA256,
+		RefCount:    entity.RefCount,
+		CreatedAt:   entity.Timestamp.CreatedAt,
+		UpdatedAt:   entity.Timestamp.UpdatedAt,
+		DeletedAt: gorm.DeletedAt{
+			Time:  deletedAtTime,
+			Valid: entity.Timestamp.DeletedAt != nil,
+		},
+	}
+}
+
+func FileTableToEntity(table File) file.File {
+	return file.File{
+		ID:          identity.NewIDFromTable(table.ID),
+		OwnerID:     identity.NewIDFromTable(table.OwnerID),
+		StorageKey:  table.StorageKey,
+		Name:        table.Name,
+		Size:        table.Size,
+		ContentType: table.ContentType,
+		SHA256:      table.SHA256,
+		RefCount:    table.RefCount,
+		Timestamp: shared.Timestamp{
+			CreatedAt: table.CreatedAt,
+			UpdatedAt: table.UpdatedAt,
+			DeletedAt: deletedAtFromTable(table.DeletedAt),
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package table
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey rows are deleted outright rather than soft deleted, so an
+// expired key can be claimed again.
+type IdempotencyKey struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;column:id"`
+	Scope       string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_idempotency_keys_scope_key,priority:1;column:scope"`
+	Key         string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key,priority:2;column:key"`
+	Fingerprint string    `gorm:"type:char(64);not null;column:fingerprint"`
+	Status      int       `gorm:"not null;default:0;column:status"`
+	Header      string    `gorm:"type:text;column:header"`
+	Body        []byte    `gorm:"column:body"`
+	ExpiresAt   time.Time `gorm:"not null;index;column:expires_at"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+func IdempotencyKeyEntityToTable(entity idempotency.Record) IdempotencyKey {
+	var header []byte
+	if entity.Header != nil {
+		header, _ = json.Marshal(entity.Header)
+	}
+
+	return IdempotencyKey{
+		ID:          entity.ID.ID,
+		Scope:       entity.Scope,
+		Key:         entity.Key,
+		Fingerprint: entity.Fingerprint,
+		Status:      entity.Status,
+		Header:      string(header),
+		Body:        entity.Body,
+		ExpiresAt:   entity.ExpiresAt,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
+
+func IdempotencyKeyTableToEntity(table IdempotencyKey) idempotency.Record {
+	var header map[string][]string
+	if table.Header != "" {
+		_ = json.Unmarshal([]byte(table.Header), &header)
+	}
+
+	return idempotency.Record{
+		ID:          identity.NewIDFromTable(table.ID),
+		Scope:       table.Scope,
+		Key:         table.Key,
+		Fingerprint: table.Fingerprint,
+		Response: idempotency.Response{
+			Status: table.Status,
+			Header: header,
+			Body:   table.Body,
+		},
+		ExpiresAt: table.ExpiresAt,
+		Timestamp: shared.Timestamp{
+			CreatedAt: table.CreatedAt,
+			UpdatedAt: table.UpdatedAt,
+		},
+	}
+}
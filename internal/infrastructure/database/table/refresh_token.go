@@ -12,13 +12,13 @@ import (
 )
 
 type RefreshToken struct {
-	ID        uuid.UUID      `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id"`
+	ID        uuid.UUID      `gorm:"primaryKey;type:uuid;column:id"`
 	UserID    uuid.UUID      `gorm:"type:uuid;not null;column:user_id"`
-	Token     string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_refresh_tokens_token_deleted_at;column:token"`
-	ExpiresAt time.Time      `gorm:"type:timestamp with time zone;not null;column:expires_at"`
-	CreatedAt time.Time      `gorm:"type:timestamp with time zone;column:created_at"`
-	UpdatedAt time.Time      `gorm:"type:timestamp with time zone;column:updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"type:timestamp with time zone;column:deleted_at;uniqueIndex:idx_refresh_tokens_token_deleted_at"`
+	Token     string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_refresh_tokens_token,where:deleted_at IS NULL;column:token"`
+	ExpiresAt time.Time      `gorm:"not null;column:expires_at"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
 
 	User *User `gorm:"foreignKey:UserID"`
 }
@@ -53,7 +53,7 @@ func RefreshTokenTableToEntity(table RefreshToken) refresh_token.RefreshToken {
 		Timestamp: shared.Timestamp{
 			CreatedAt: table.CreatedAt,
 			UpdatedAt: table.UpdatedAt,
-			DeletedAt: &table.DeletedAt.Time,
+			DeletedAt: deletedAtFromTable(table.DeletedAt),
 		},
 	}
 }
@@ -0,0 +1,14 @@
+package table
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func deletedAtFromTable(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	return &deletedAt.Time
+}
@@ -0,0 +1,92 @@
+package table
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Upload struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;column:id"`
+	OwnerID   uuid.UUID      `gorm:"type:uuid;not null;index;column:owner_id"`
+	Length    int64          `gorm:"not null;column:length"`
+	Offset    int64          `gorm:"not null;default:0;column:upload_offset"`
+	Metadata  string         `gorm:"type:text;column:metadata"`
+	Parts     string         `gorm:"type:text;column:parts"`
+	ExpiresAt time.Time      `gorm:"not null;index;column:expires_at"`
+	FileID    *uuid.UUID     `gorm:"type:uuid;column:file_id"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+
+	Owner *User `gorm:"foreignKey:OwnerID"`
+	File  *File `gorm:"foreignKey:FileID"`
+}
+
+func UploadEntityToTable(entity file.Upload) Upload {
+	var deletedAtTime time.Time
+	if entity.Timestamp.DeletedAt != nil {
+		deletedAtTime = *entity.Timestamp.DeletedAt
+	}
+
+	var fileID *uuid.UUID
+	if entity.FileID != nil {
+		fileID = &entity.FileID.ID
+	}
+
+	metadata, _ := json.Marshal(entity.Metadata)
+	parts, _ := json.Marshal(entity.Parts)
+
+	return Upload{
+		ID:        entity.ID.ID,
+		OwnerID:   entity.OwnerID.ID,
+		Length:    entity.Length,
+		Offset:    entity.Offset,
+		Metadata:  string(metadata),
+		Parts:     string(parts),
+		ExpiresAt: entity.ExpiresAt,
+		FileID:    fileID,
+		CreatedAt: entity.Timestamp.CreatedAt,
+		UpdatedAt: entity.Timestamp.UpdatedAt,
+		DeletedAt: gorm.DeletedAt{
+			Time:  deletedAtTime,
+			Valid: entity.Timestamp.DeletedAt != nil,
+		},
+	}
+}
+
+func UploadTableToEntity(table Upload) file.Upload {
+	var fileID *identity.ID
+	if table.FileID != nil {
+		id := identity.NewIDFromTable(*table.FileID)
+		fileID = &id
+	}
+
+	metadata := make(map[string]string)
+	_ = json.Unmarshal([]byte(table.Metadata), &metadata)
+
+	var parts []string
+	_ = json.Unmarshal([]byte(table.Parts), &parts)
+
+	return file.Upload{
+		ID:        identity.NewIDFromTable(table.ID),
+		OwnerID:   identity.NewIDFromTable(table.OwnerID),
+		Length:    table.Length,
+		Offset:    table.Offset,
+		Metadata:  metadata,
+		Parts:     parts,
+		ExpiresAt: table.ExpiresAt,
+		FileID:    fileID,
+		Timestamp: shared.Timestamp{
+			CreatedAt: table.CreatedAt,
+			UpdatedAt: table.UpdatedAt,
+			DeletedAt: deletedAtFromTable(table.DeletedAt),
+		},
+	}
+}
@@ -12,17 +12,29 @@ import (
 )
 
 type User struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4();column:id"`
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;column:id"`
 	Name        string         `gorm:"type:varchar(100);not null;column:name"`
-	Email       string         `gorm:"type:varchar(255);uniqueIndex:idx_users_email_deleted_at;not null;column:email"`
+	Email       string         `gorm:"type:varchar(255);uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null;column:email"`
 	PhoneNumber string         `gorm:"type:varchar(20);index;column:phone_number"`
 	Password    string         `gorm:"type:varchar(255);not null;column:password"`
 	Role        string         `gorm:"type:varchar(50);not null;default:'user';column:role"`
 	ImageUrl    string         `gorm:"type:varchar(255);column:image_url"`
 	IsVerified  bool           `gorm:"default:false;column:is_verified"`
-	CreatedAt   time.Time      `gorm:"type:timestamp with time zone;column:created_at"`
-	UpdatedAt   time.Time      `gorm:"type:timestamp with time zone;column:updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"type:timestamp with time zone;column:deleted_at;uniqueIndex:idx_users_email_deleted_at"`
+	Version     int64          `gorm:"not null;default:1;column:version"`
+	CreatedAt   time.Time      `gorm:"column:created_at"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (u *User) BeforeCreate(_ *gorm.DB) error {
+	if u.Version == 0 {
+		u.Version = 1
+	}
+	return nil
+}
+
+func (u *User) SetVersion(version int64) {
+	u.Version = version
 }
 
 func UserEntityToTable(entity user.User) User {
@@ -41,6 +53,7 @@ func UserEntityToTable(entity user.User) User {
 		Role:        entity.Role.Name,
 		ImageUrl:    entity.ImageUrl.Path,
 		IsVerified:  entity.IsVerified,
+		Version:     entity.Version,
 		CreatedAt:   entity.Timestamp.CreatedAt,
 		UpdatedAt:   entity.Timestamp.UpdatedAt,
 		DeletedAt: gorm.DeletedAt{
@@ -60,10 +73,11 @@ func UserTableToEntity(table User) user.User {
 		Role:        user.NewRoleFromTable(table.Role),
 		ImageUrl:    shared.NewURLFromTable(table.ImageUrl),
 		IsVerified:  table.IsVerified,
+		Version:     table.Version,
 		Timestamp: shared.Timestamp{
 			CreatedAt: table.CreatedAt,
 			UpdatedAt: table.UpdatedAt,
-			DeletedAt: &table.DeletedAt.Time,
+			DeletedAt: deletedAtFromTable(table.DeletedAt),
 		},
 	}
 }
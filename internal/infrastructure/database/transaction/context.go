@@ -0,0 +1,48 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+
+	"gorm.io/gorm"
+)
+
+type (
+	contextKey struct{}
+
+	session struct {
+		tx         *Repository
+		savepoints int
+		onCommit   []application.Hook
+		onRollback []application.Hook
+	}
+)
+
+func WithContext(ctx context.Context, tx *Repository) context.Context {
+	return withSession(ctx, &session{tx: tx})
+}
+
+func FromContext(ctx context.Context) (*Repository, bool) {
+	s, ok := sessionFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return s.tx, true
+}
+
+func (r Repository) Conn(ctx context.Context) *gorm.DB {
+	if tx, ok := FromContext(ctx); ok {
+		return tx.db.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
+
+func withSession(ctx context.Context, s *session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+func sessionFromContext(ctx context.Context) (*session, bool) {
+	s, ok := ctx.Value(contextKey{}).(*session)
+	return s, ok && s != nil && s.tx != nil
+}
@@ -0,0 +1,162 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+	"github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/samber/do/v2"
+)
+
+const (
+	MaxRetries   = 3
+	RetryBackoff = 50 * time.Millisecond
+
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	// sqliteBusy is the primary code of SQLITE_BUSY_SNAPSHOT, which a write
+	// fails with once another connection has committed since the transaction
+	// read.
+	sqliteBusy = 5
+)
+
+type unitOfWork struct {
+	db         *Repository
+	maxRetries int
+}
+
+func NewUnitOfWork(injector do.Injector) application.UnitOfWork {
+	db := do.MustInvoke[*Repository](injector)
+	return &unitOfWork{
+		db:         db,
+		maxRetries: MaxRetries,
+	}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if parent, ok := sessionFromContext(ctx); ok {
+		return u.nested(ctx, parent, fn)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = u.run(ctx, fn)
+		if err == nil || attempt >= u.maxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		log.Printf("Retrying transaction after serialization failure (attempt %d): %v", attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(RetryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func (u *unitOfWork) OnCommit(ctx context.Context, hook application.Hook) {
+	s, ok := sessionFromContext(ctx)
+	if !ok {
+		runHooks(ctx, []application.Hook{hook})
+		return
+	}
+	s.onCommit = append(s.onCommit, hook)
+}
+
+func (u *unitOfWork) OnRollback(ctx context.Context, hook application.Hook) {
+	s, ok := sessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	s.onRollback = append(s.onRollback, hook)
+}
+
+func (u *unitOfWork) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	s := &session{tx: tx}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = application.RecoveredFromPanic(r)
+		}
+
+		if err != nil {
+			if rollbackErr := tx.db.Rollback().Error; rollbackErr != nil {
+				log.Println("Error rolling back transaction:", rollbackErr)
+			}
+			runHooks(ctx, s.onRollback)
+			return
+		}
+
+		if err = tx.db.Commit().Error; err != nil {
+			runHooks(ctx, s.onRollback)
+			return
+		}
+		runHooks(ctx, s.onCommit)
+	}()
+
+	return fn(withSession(ctx, s))
+}
+
+func (u *unitOfWork) nested(ctx context.Context, parent *session, fn func(ctx context.Context) error) (err error) {
+	parent.savepoints++
+	name := fmt.Sprintf("sp_%d", parent.savepoints)
+	if err = parent.tx.db.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	child := &session{tx: parent.tx, savepoints: parent.savepoints}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = application.RecoveredFromPanic(r)
+		}
+
+		parent.savepoints = child.savepoints
+		if err != nil {
+			if rollbackErr := parent.tx.db.RollbackTo(name).Error; rollbackErr != nil {
+				log.Println("Error rolling back to savepoint:", rollbackErr)
+			}
+			runHooks(ctx, child.onRollback)
+			return
+		}
+
+		parent.onCommit = append(parent.onCommit, child.onCommit...)
+		parent.onRollback = append(parent.onRollback, child.onRollback...)
+	}()
+
+	return fn(withSession(ctx, child))
+}
+
+// IsRetryable reports whether err, or any error it wraps, is a serialization
+// failure or deadlock on PostgreSQL, or a busy database on SQLite, after which
+// the whole transaction may succeed when run again. Services wrap the errors
+// of repositories into their domain errors so that these reach Do.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code()&0xff == sqliteBusy
+	}
+	return false
+}
+
+func runHooks(ctx context.Context, hooks []application.Hook) {
+	for _, hook := range hooks {
+		if err := hook(context.WithoutCancel(ctx)); err != nil {
+			log.Println("Error running transaction hook:", err)
+		}
+	}
+}
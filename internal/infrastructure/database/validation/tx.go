@@ -1,21 +0,0 @@
-package validation
-
-import (
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
-
-	"gorm.io/gorm"
-)
-
-func ValidateTransaction(tx interface{}) (*transaction.Repository, error) {
-	db := &transaction.Repository{}
-	if tx == nil {
-		return db, nil
-	}
-
-	db, ok := tx.(*transaction.Repository)
-	if !ok {
-		return nil, gorm.ErrInvalidTransaction
-	}
-
-	return db, nil
-}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	appresponse "github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+const RelationSessions = "sessions"
+
+var (
+	// UserFields is what GET /user/me lets clients select and include.
+	UserFields = fieldset.Set{
+		Fields:    []string{"id", "name", "email", "phone_number", "role", "image_url", "image_thumbnails", "is_verified"},
+		Relations: []string{RelationSessions},
+	}
+
+	// UserListFields is what GET /user/ lets clients select. Sessions belong
+	// to their own user only, so the list cannot include them.
+	UserListFields = fieldset.Set{
+		Fields: slices.Concat(UserFields.Fields, []string{"highlights"}),
+	}
+)
+
+// BindFieldset reads the fields and include parameters of the request and
+// checks them against set. When they are invalid it adds the error with the
+// message id and reports false.
+func BindFieldset(ctx *gin.Context, set fieldset.Set, id string) (fieldset.Selection, bool) {
+	var req fieldset.Request
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return fieldset.Selection{}, false
+	}
+
+	selection, err := set.Parse(req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(id)
+		return fieldset.Selection{}, false
+	}
+	return selection, true
+}
+
+// ProjectUsers trims users, whose IDs are ids, to selection and embeds the
+// relations it includes. Each relation is loaded for every user at once.
+func ProjectUsers[U any](ctx context.Context, userService service.UserService, selection fieldset.Selection, users []U, ids ...string) ([]any, error) {
+	var sessions map[string][]appresponse.UserSession
+	if selection.Includes(RelationSessions) {
+		var err error
+		if sessions, err = userService.GetSessionsByUserIDs(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	projected := make([]any, len(users))
+	for i, user := range users {
+		relations := map[string]any{}
+		if sessions != nil {
+			relations[RelationSessions] = sessions[ids[i]]
+		}
+
+		var err error
+		if projected[i], err = selection.Project(user, relations); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+)
+
+type (
+	FileController interface {
+		Download(ctx *gin.Context)
+	}
+
+	fileController struct {
+		fileService service.FileService
+	}
+)
+
+func NewFileController(injector do.Injector) FileController {
+	fileService := do.MustInvoke[service.FileService](injector)
+	return &fileController{
+		fileService: fileService,
+	}
+}
+
+func (c *fileController) Download(ctx *gin.Context) {
+	req := request.FileDownload{Path: ctx.Param("path")}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	content, info, err := c.fileService.Download(ctx.Request.Context(), req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedDownloadFile)
+		return
+	}
+	defer content.Close()
+
+	if info.ContentType != "" {
+		ctx.Header("Content-Type", info.ContentType)
+	}
+	ctx.Header("Cache-Control", "private")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(ctx.Writer, ctx.Request, path.Base(info.Path), info.ModifiedAt, content)
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	appresponse "github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+)
+
+const TusExtensions = "creation,termination,checksum,expiration"
+
+type (
+	// UploadController implements the tus 1.0 core protocol with the creation,
+	// termination, checksum and expiration extensions.
+	UploadController interface {
+		Options(ctx *gin.Context)
+		Create(ctx *gin.Context)
+		Head(ctx *gin.Context)
+		Patch(ctx *gin.Context)
+		Terminate(ctx *gin.Context)
+	}
+
+	uploadController struct {
+		uploadService service.UploadService
+	}
+)
+
+func NewUploadController(injector do.Injector) UploadController {
+	uploadService := do.MustInvoke[service.UploadService](injector)
+	return &uploadController{
+		uploadService: uploadService,
+	}
+}
+
+func (c *uploadController) Options(ctx *gin.Context) {
+	ctx.Header("Tus-Version", middleware.TusVersion)
+	ctx.Header("Tus-Extension", TusExtensions)
+	ctx.Header("Tus-Max-Size", strconv.FormatInt(c.uploadService.MaxSize(), 10))
+	ctx.Header("Tus-Checksum-Algorithm", strings.Join(file.ChecksumAlgorithms, ","))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *uploadController) Create(ctx *gin.Context) {
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(file.ErrorUploadLengthInvalid).SetMeta(message.FailedCreateUpload)
+		return
+	}
+
+	req := request.UploadCreate{
+		Length:   length,
+		Metadata: ctx.GetHeader("Upload-Metadata"),
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+
+	result, err := c.uploadService.Create(ctx.Request.Context(), userID, req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedCreateUpload)
+		return
+	}
+
+	ctx.Header("Location", uploadLocation(ctx, result.ID))
+	setUploadHeaders(ctx, result)
+	ctx.Status(http.StatusCreated)
+}
+
+func (c *uploadController) Head(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	result, err := c.uploadService.Get(ctx.Request.Context(), userID, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetUpload)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Upload-Length", strconv.FormatInt(result.Length, 10))
+	if result.Metadata != nil {
+		ctx.Header("Upload-Metadata", file.EncodeMetadata(result.Metadata))
+	}
+	setUploadHeaders(ctx, result)
+	ctx.Status(http.StatusOK)
+}
+
+func (c *uploadController) Patch(ctx *gin.Context) {
+	if ctx.ContentType() != file.TusContentType {
+		_ = ctx.Error(file.ErrorUploadContentType).SetMeta(message.FailedPatchUpload)
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		_ = ctx.Error(file.ErrorUploadOffsetInvalid).SetMeta(message.FailedPatchUpload)
+		return
+	}
+
+	req := request.UploadPatch{
+		ID:            ctx.Param("id"),
+		Offset:        offset,
+		Checksum:      ctx.GetHeader("Upload-Checksum"),
+		ContentLength: ctx.Request.ContentLength,
+		Body:          ctx.Request.Body,
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+
+	result, err := c.uploadService.Patch(ctx.Request.Context(), userID, req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedPatchUpload)
+		return
+	}
+
+	setUploadHeaders(ctx, result)
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *uploadController) Terminate(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	if err := c.uploadService.Terminate(ctx.Request.Context(), userID, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedTerminateUpload)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func setUploadHeaders(ctx *gin.Context, result appresponse.Upload) {
+	ctx.Header("Upload-Offset", strconv.FormatInt(result.Offset, 10))
+	if result.FileID != "" {
+		ctx.Header("File-Id", result.FileID)
+		return
+	}
+	ctx.Header("Upload-Expires", result.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func uploadLocation(ctx *gin.Context, uploadID string) string {
+	return strings.TrimSuffix(ctx.FullPath(), "/") + "/" + uploadID
+}
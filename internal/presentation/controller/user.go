@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	appresponse "github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,8 @@ type (
 		GetAll(ctx *gin.Context)
 		Update(ctx *gin.Context)
 		Delete(ctx *gin.Context)
+		UpdateAvatar(ctx *gin.Context)
+		DeleteAvatar(ctx *gin.Context)
 	}
 
 	userController struct {
@@ -39,70 +43,76 @@ func NewUserController(injector do.Injector) UserController {
 func (c *userController) Register(ctx *gin.Context) {
 	var req request.UserRegister
 	if err := ctx.ShouldBind(&req); err != nil {
-		res := response.BuildResponseFailed(message.FailedGetDataFromBody, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
 	}
 
 	result, err := c.userService.Register(ctx.Request.Context(), req)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedRegister, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedRegister)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessRegister, result)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessRegister), result)
 	ctx.JSON(http.StatusCreated, res)
 }
 
 func (c *userController) Login(ctx *gin.Context) {
 	var req request.UserLogin
 	if err := ctx.ShouldBind(&req); err != nil {
-		res := response.BuildResponseFailed(message.FailedGetDataFromBody, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	result, err := c.userService.Verify(ctx.Request.Context(), req)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedLogin, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedLogin)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessLogin, result)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessLogin), result)
 	ctx.JSON(http.StatusOK, res)
 }
 
 func (c *userController) Me(ctx *gin.Context) {
 	userID := ctx.MustGet("user_id").(string)
 
+	selection, ok := BindFieldset(ctx, UserFields, message.FailedGetUser)
+	if !ok {
+		return
+	}
+
 	result, err := c.userService.GetUserByID(ctx.Request.Context(), userID)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedGetUser, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedGetUser)
+		return
+	}
+
+	users, err := ProjectUsers(ctx.Request.Context(), c.userService, selection, []appresponse.User{result}, userID)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetUser)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessGetUser, result)
+	middleware.SetVersion(ctx, result.Version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessGetUser), users[0])
 	ctx.JSON(http.StatusOK, res)
 }
 
 func (c *userController) RefreshToken(ctx *gin.Context) {
 	var req request.RefreshToken
 	if err := ctx.ShouldBind(&req); err != nil {
-		res := response.BuildResponseFailed(message.FailedGetDataFromBody, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	result, err := c.userService.RefreshToken(ctx.Request.Context(), req)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedRefreshToken, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+		_ = ctx.Error(err).SetMeta(message.FailedRefreshToken)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessRefreshToken, result)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessRefreshToken), result)
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -110,34 +120,50 @@ func (c *userController) Logout(ctx *gin.Context) {
 	userID := ctx.MustGet("user_id").(string)
 
 	if err := c.userService.RevokeRefreshToken(ctx.Request.Context(), userID); err != nil {
-		res := response.BuildResponseFailed(message.FailedLogout, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedLogout)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessLogout, nil)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessLogout), nil)
 	ctx.JSON(http.StatusOK, res)
 }
 
 func (c *userController) GetAll(ctx *gin.Context) {
 	var req pagination.Request
 	if err := ctx.ShouldBind(&req); err != nil {
-		res := response.BuildResponseFailed(message.FailedGetDataFromBody, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	req.Filters = pagination.ParseFilters(ctx.Request.URL.Query())
+
+	selection, ok := BindFieldset(ctx, UserListFields, message.FailedGetAllUsers)
+	if !ok {
 		return
 	}
 
 	result, err := c.userService.GetAllUsersWithPagination(ctx.Request.Context(), req)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedGetAllUsers, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedGetAllUsers)
+		return
+	}
+
+	users := make([]appresponse.User, 0, len(result.Data))
+	ids := make([]string, 0, len(result.Data))
+	for _, data := range result.Data {
+		user := data.(appresponse.User)
+		users = append(users, user)
+		ids = append(ids, user.ID)
+	}
+	projected, err := ProjectUsers(ctx.Request.Context(), c.userService, selection, users, ids...)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetAllUsers)
 		return
 	}
 
 	res := response.Response{
 		Status:  true,
-		Message: message.SuccessGetAllUsers,
-		Data:    result.Data,
+		Message: message.Localize(ctx, message.SuccessGetAllUsers),
+		Data:    projected,
 		Meta:    result.Response,
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -146,33 +172,65 @@ func (c *userController) GetAll(ctx *gin.Context) {
 func (c *userController) Update(ctx *gin.Context) {
 	var req request.UserUpdate
 	if err := ctx.ShouldBind(&req); err != nil {
-		res := response.BuildResponseFailed(message.FailedGetDataFromBody, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	userID := ctx.MustGet("user_id").(string)
 
-	result, err := c.userService.Update(ctx.Request.Context(), userID, req)
+	result, err := c.userService.Update(ctx.Request.Context(), userID, middleware.IfMatchVersion(ctx), req)
 	if err != nil {
-		res := response.BuildResponseFailed(message.FailedUpdateUser, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		_ = ctx.Error(err).SetMeta(message.FailedUpdateUser)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessUpdateUser, result)
+	middleware.SetVersion(ctx, result.Version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessUpdateUser), result)
 	ctx.JSON(http.StatusOK, res)
 }
 
 func (c *userController) Delete(ctx *gin.Context) {
 	userID := ctx.MustGet("user_id").(string)
 
-	if err := c.userService.Delete(ctx.Request.Context(), userID); err != nil {
-		res := response.BuildResponseFailed(message.FailedDeleteUser, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+	if err := c.userService.Delete(ctx.Request.Context(), userID, middleware.IfMatchVersion(ctx)); err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedDeleteUser)
+		return
+	}
+
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessDeleteUser), nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) UpdateAvatar(ctx *gin.Context) {
+	var req request.UserAvatar
+	if err := ctx.ShouldBind(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+
+	result, err := c.userService.UpdateAvatar(ctx.Request.Context(), userID, middleware.IfMatchVersion(ctx), req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedUpdateAvatar)
+		return
+	}
+
+	middleware.SetVersion(ctx, result.Version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessUpdateAvatar), result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) DeleteAvatar(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	version, err := c.userService.DeleteAvatar(ctx.Request.Context(), userID, middleware.IfMatchVersion(ctx))
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedDeleteAvatar)
 		return
 	}
 
-	res := response.BuildResponseSuccess(message.SuccessDeleteUser, nil)
+	middleware.SetVersion(ctx, version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessDeleteAvatar), nil)
 	ctx.JSON(http.StatusOK, res)
 }
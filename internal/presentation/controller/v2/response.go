@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/fieldset"
+)
+
+var (
+	userFields = fieldset.Set{
+		Fields:    []string{"id", "name", "email", "phone_number", "role", "avatar", "is_verified"},
+		Relations: []string{controller.RelationSessions},
+	}
+	userListFields = fieldset.Set{
+		Fields: []string{"id", "name", "email", "phone_number", "role", "avatar", "is_verified", "highlights"},
+	}
+)
+
+type (
+	// User replaces the image_url and image_thumbnails of version 1 with an
+	// avatar object, which is null for users without an avatar.
+	User struct {
+		ID          string            `json:"id"`
+		Name        string            `json:"name"`
+		Email       string            `json:"email"`
+		PhoneNumber string            `json:"phone_number"`
+		Role        string            `json:"role"`
+		Avatar      *Avatar           `json:"avatar"`
+		IsVerified  bool              `json:"is_verified"`
+		Highlights  map[string]string `json:"highlights,omitempty"`
+	}
+
+	Avatar struct {
+		URL        string            `json:"url"`
+		Thumbnails map[string]string `json:"thumbnails,omitempty"`
+	}
+)
+
+func NewUser(user response.User) User {
+	return User{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
+		Avatar:      newAvatar(user.ImageUrl, user.ImageThumbnails),
+		IsVerified:  user.IsVerified,
+		Highlights:  user.Highlights,
+	}
+}
+
+func NewCreatedUser(user response.UserCreate) User {
+	return NewUser(response.User{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		Role:            user.Role,
+		ImageUrl:        user.ImageUrl,
+		ImageThumbnails: user.ImageThumbnails,
+		IsVerified:      user.IsVerified,
+	})
+}
+
+func newAvatar(url string, thumbnails map[string]string) *Avatar {
+	if url == "" {
+		return nil
+	}
+	return &Avatar{
+		URL:        url,
+		Thumbnails: thumbnails,
+	}
+}
@@ -0,0 +1,136 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	appresponse "github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+)
+
+// userController serves the endpoints whose response changed in version 2
+// and leaves the others to the version 1 controller.
+type userController struct {
+	controller.UserController
+	userService service.UserService
+}
+
+func NewUserController(injector do.Injector) controller.UserController {
+	v1 := do.MustInvokeNamed[controller.UserController](injector, version.V1)
+	userService := do.MustInvoke[service.UserService](injector)
+	return &userController{
+		UserController: v1,
+		userService:    userService,
+	}
+}
+
+func (c *userController) Register(ctx *gin.Context) {
+	var req request.UserRegister
+	if err := ctx.ShouldBind(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	result, err := c.userService.Register(ctx.Request.Context(), req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedRegister)
+		return
+	}
+
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessRegister), NewCreatedUser(result))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *userController) Me(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	selection, ok := controller.BindFieldset(ctx, userFields, message.FailedGetUser)
+	if !ok {
+		return
+	}
+
+	result, err := c.userService.GetUserByID(ctx.Request.Context(), userID)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetUser)
+		return
+	}
+
+	users, err := controller.ProjectUsers(ctx.Request.Context(), c.userService, selection, []User{NewUser(result)}, userID)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetUser)
+		return
+	}
+
+	middleware.SetVersion(ctx, result.Version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessGetUser), users[0])
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) GetAll(ctx *gin.Context) {
+	var req pagination.Request
+	if err := ctx.ShouldBind(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	req.Filters = pagination.ParseFilters(ctx.Request.URL.Query())
+
+	selection, ok := controller.BindFieldset(ctx, userListFields, message.FailedGetAllUsers)
+	if !ok {
+		return
+	}
+
+	result, err := c.userService.GetAllUsersWithPagination(ctx.Request.Context(), req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetAllUsers)
+		return
+	}
+
+	users := make([]User, 0, len(result.Data))
+	ids := make([]string, 0, len(result.Data))
+	for _, data := range result.Data {
+		user := NewUser(data.(appresponse.User))
+		users = append(users, user)
+		ids = append(ids, user.ID)
+	}
+	projected, err := controller.ProjectUsers(ctx.Request.Context(), c.userService, selection, users, ids...)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedGetAllUsers)
+		return
+	}
+
+	res := response.Response{
+		Status:  true,
+		Message: message.Localize(ctx, message.SuccessGetAllUsers),
+		Data:    projected,
+		Meta:    result.Response,
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) UpdateAvatar(ctx *gin.Context) {
+	var req request.UserAvatar
+	if err := ctx.ShouldBind(&req); err != nil {
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+
+	result, err := c.userService.UpdateAvatar(ctx.Request.Context(), userID, middleware.IfMatchVersion(ctx), req)
+	if err != nil {
+		_ = ctx.Error(err).SetMeta(message.FailedUpdateAvatar)
+		return
+	}
+
+	middleware.SetVersion(ctx, result.Version)
+	res := response.BuildResponseSuccess(message.Localize(ctx, message.SuccessUpdateAvatar), NewUser(result))
+	ctx.JSON(http.StatusOK, res)
+}
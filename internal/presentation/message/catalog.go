@@ -0,0 +1,33 @@
+package message
+
+import (
+	"embed"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/i18n"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// LocalizerKey holds the i18n.Localizer negotiated for a request.
+const LocalizerKey = "localizer"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog translates the message IDs of this package. English is the default
+// locale; every ID must be defined in locales/en.json.
+var Catalog = i18n.MustNewBundle(language.English, locales, "locales")
+
+// Localizer returns the localizer negotiated for the request, or the default
+// locale's when there is none.
+func Localizer(ctx *gin.Context) i18n.Localizer {
+	if value, ok := ctx.Get(LocalizerKey); ok {
+		return value.(i18n.Localizer)
+	}
+	return Catalog.Localizer(Catalog.DefaultLanguage())
+}
+
+// Localize translates id into the locale of the request.
+func Localize(ctx *gin.Context, id string, params ...i18n.Params) string {
+	return Localizer(ctx).Translate(id, params...)
+}
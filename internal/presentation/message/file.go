@@ -0,0 +1,10 @@
+package message
+
+const (
+	FailedDownloadFile    = "failed_download_file"
+	FailedCreateUpload    = "failed_create_upload"
+	FailedGetUpload       = "failed_get_upload"
+	FailedPatchUpload     = "failed_patch_upload"
+	FailedTerminateUpload = "failed_terminate_upload"
+	FailedTusVersion      = "failed_tus_version"
+)
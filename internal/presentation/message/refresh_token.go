@@ -1,7 +0,0 @@
-package message
-
-const (
-	FailedTokenNotFound = "Token not found"
-	FailedTokenNotValid = "Token not valid"
-	FailedDeniedAccess  = "Access denied, you don't have permission to access this resource"
-)
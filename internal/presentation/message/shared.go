@@ -1,6 +1,9 @@
 package message
 
 const (
-	FailedGetDataFromBody = "Failed to get data from body"
-	FailedProcessRequest  = "Failed to process request"
+	FailedGetDataFromBody  = "failed_get_data_from_body"
+	FailedProcessRequest   = "failed_process_request"
+	FailedNegotiateVersion = "failed_negotiate_version"
+	FailedPrecondition     = "failed_precondition"
+	FailedIdempotency      = "failed_idempotency"
 )
@@ -1,21 +1,25 @@
 package message
 
 const (
-	FailedRegister     = "Failed to register"
-	FailedLogin        = "Failed to login"
-	FailedGetUser      = "Failed to get user"
-	FailedRefreshToken = "Failed to refresh token"
-	FailedLogout       = "Failed to logout"
-	FailedGetAllUsers  = "Failed to get all users"
-	FailedUpdateUser   = "Failed to update user"
-	FailedDeleteUser   = "Failed to delete user"
+	FailedRegister     = "failed_register"
+	FailedLogin        = "failed_login"
+	FailedGetUser      = "failed_get_user"
+	FailedRefreshToken = "failed_refresh_token"
+	FailedLogout       = "failed_logout"
+	FailedGetAllUsers  = "failed_get_all_users"
+	FailedUpdateUser   = "failed_update_user"
+	FailedDeleteUser   = "failed_delete_user"
+	FailedUpdateAvatar = "failed_update_avatar"
+	FailedDeleteAvatar = "failed_delete_avatar"
 
-	SuccessRegister     = "Successfully registered"
-	SuccessLogin        = "Successfully logged in"
-	SuccessGetUser      = "Successfully retrieved user data"
-	SuccessRefreshToken = "Successfully refreshed token"
-	SuccessLogout       = "Successfully logged out"
-	SuccessGetAllUsers  = "Successfully retrieved all users"
-	SuccessUpdateUser   = "Successfully updated user"
-	SuccessDeleteUser   = "Successfully deleted user"
+	SuccessRegister     = "success_register"
+	SuccessLogin        = "success_login"
+	SuccessGetUser      = "success_get_user"
+	SuccessRefreshToken = "success_refresh_token"
+	SuccessLogout       = "success_logout"
+	SuccessGetAllUsers  = "success_get_all_users"
+	SuccessUpdateUser   = "success_update_user"
+	SuccessDeleteUser   = "success_delete_user"
+	SuccessUpdateAvatar = "success_update_avatar"
+	SuccessDeleteAvatar = "success_delete_avatar"
 )
@@ -0,0 +1,19 @@
+package message
+
+// Validation messages describe why a request field was rejected. Rule
+// messages take the rule's parameter as {param}; length rules take it as
+// {count}.
+const (
+	ValidationInvalidFields = "validation.invalid_fields"
+	ValidationMalformedBody = "validation.malformed_body"
+	ValidationType          = "validation.type"
+	ValidationRule          = "validation.rule"
+	ValidationMinLength     = "validation.min_length"
+	ValidationMaxLength     = "validation.max_length"
+)
+
+// ValidationRuleID is the ID of the message for a validation rule such as
+// required or email.
+func ValidationRuleID(rule string) string {
+	return "validation." + rule
+}
@@ -1,49 +1,49 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
-	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	ErrorTokenNotFound = shared.NewError(shared.CategoryUnauthorized, "token_not_found", "Token not found")
+	ErrorTokenNotValid = shared.NewError(shared.CategoryUnauthorized, "token_not_valid", "Token not valid")
+	ErrorDeniedAccess  = shared.NewError(shared.CategoryUnauthorized, "access_denied", "Access denied, you don't have permission to access this resource")
+)
+
 func Authenticate(jwtService service.JWTService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 
 		if authHeader == "" {
-			res := response.BuildResponseFailed(message.FailedProcessRequest, message.FailedTokenNotFound, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			abortWithError(ctx, ErrorTokenNotFound, message.FailedProcessRequest)
 			return
 		}
 
 		if !strings.Contains(authHeader, "Bearer ") {
-			res := response.BuildResponseFailed(message.FailedProcessRequest, message.FailedTokenNotValid, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			abortWithError(ctx, ErrorTokenNotValid, message.FailedProcessRequest)
 			return
 		}
 
 		authHeader = strings.Replace(authHeader, "Bearer ", "", -1)
 		token, err := jwtService.ValidateToken(authHeader)
 		if err != nil {
-			res := response.BuildResponseFailed(message.FailedProcessRequest, message.FailedTokenNotValid, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			abortWithError(ctx, ErrorTokenNotValid, message.FailedProcessRequest)
 			return
 		}
 
 		if !token.Valid {
-			res := response.BuildResponseFailed(message.FailedProcessRequest, message.FailedDeniedAccess, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			abortWithError(ctx, ErrorDeniedAccess, message.FailedProcessRequest)
 			return
 		}
 
 		userId, err := jwtService.GetUserIDByToken(authHeader)
 		if err != nil {
-			res := response.BuildResponseFailed(message.FailedProcessRequest, err.Error(), nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			abortWithError(ctx, ErrorTokenNotValid, message.FailedProcessRequest)
 			return
 		}
 
@@ -11,10 +11,13 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Tus-Resumable, Upload-Length, Upload-Metadata, Upload-Offset, Upload-Checksum, X-Request-Id, traceparent, If-Match, If-None-Match, Idempotency-Key")
 		c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT, DELETE")
+		c.Header("Access-Control-Expose-Headers", "Location, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Tus-Checksum-Algorithm, Upload-Length, Upload-Metadata, Upload-Offset, Upload-Expires, File-Id, X-Request-Id, ETag, Idempotent-Replayed")
 
-		if c.Request.Method == http.MethodOptions {
+		// Preflights and unrouted OPTIONS requests end here; routes that
+		// answer OPTIONS themselves, such as tus discovery, are let through.
+		if c.Request.Method == http.MethodOptions && (c.GetHeader("Access-Control-Request-Method") != "" || c.FullPath() == "") {
 			c.AbortWithStatus(204)
 			return
 		}
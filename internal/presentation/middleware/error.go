@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeInternal       = "internal_error"
+
+	// ErrorFormatProblem answers with RFC 7807 problem details;
+	// ErrorFormatEnvelope keeps the response.Response envelope for clients
+	// written against it.
+	ErrorFormatProblem  = "problem"
+	ErrorFormatEnvelope = "envelope"
+)
+
+var categoryStatus = map[shared.Category]int{
+	shared.CategoryValidation:    http.StatusBadRequest,
+	shared.CategoryUnauthorized:  http.StatusUnauthorized,
+	shared.CategoryForbidden:     http.StatusForbidden,
+	shared.CategoryNotFound:      http.StatusNotFound,
+	shared.CategoryConflict:      http.StatusConflict,
+	shared.CategoryGone:          http.StatusGone,
+	shared.CategoryPrecondition:  http.StatusPreconditionFailed,
+	shared.CategoryTooLarge:      http.StatusRequestEntityTooLarge,
+	shared.CategoryUnprocessable: http.StatusUnprocessableEntity,
+	shared.CategoryUnavailable:   http.StatusServiceUnavailable,
+	shared.CategoryInternal:      http.StatusInternalServerError,
+}
+
+// codeStatus holds the errors whose status is set by a protocol rather than
+// by their category.
+var codeStatus = map[string]int{
+	file.ErrorChecksumMismatch.Code:  StatusChecksumMismatch,
+	file.ErrorUploadContentType.Code: http.StatusUnsupportedMediaType,
+	ErrorTusVersion.Code:             http.StatusPreconditionFailed,
+	ErrorVersionNotAcceptable.Code:   http.StatusNotAcceptable,
+	ErrorIfMatchRequired.Code:        http.StatusPreconditionRequired,
+}
+
+// ErrorHandler answers for the last error a handler added with ctx.Error,
+// unless the handler has already written a response. Domain errors get the
+// status of their category along with their code; errors added with
+// gin.ErrorTypeBind are reported as invalid requests, field by field. Anything
+// else, and the detail of internal errors, is logged instead of being sent.
+// Domain errors are described by their declared message, without the detail
+// they were wrapped with, unless they are validation errors. A
+// message ID set as the error's meta is translated into the problem title, or
+// the envelope message when ERROR_FORMAT is envelope.
+func ErrorHandler() gin.HandlerFunc {
+	renderError := errorRenderer()
+
+	return func(ctx *gin.Context) {
+		ctx.Next()
+		renderError(ctx)
+	}
+}
+
+// errorRenderer writes the last error of a request the way ErrorHandler does,
+// for middleware that runs before ErrorHandler in the chain.
+func errorRenderer() func(ctx *gin.Context) {
+	useTagNames()
+	format := getErrorFormat()
+	typeBaseURL := os.Getenv("PROBLEM_TYPE_BASE_URL")
+
+	return func(ctx *gin.Context) {
+		ginError := ctx.Errors.Last()
+		if ginError == nil || ctx.Writer.Written() {
+			return
+		}
+
+		status, code, detail := describeError(ginError)
+		if status == http.StatusInternalServerError {
+			log.Printf("%s %s [%s]: %v", ctx.Request.Method, ctx.Request.URL.Path, ctx.GetString(TraceIDKey), ginError.Err)
+		}
+
+		if ctx.Request.Method == http.MethodHead {
+			ctx.AbortWithStatus(status)
+			return
+		}
+
+		id, ok := ginError.Meta.(string)
+		if !ok {
+			id = message.FailedProcessRequest
+			if ginError.IsType(gin.ErrorTypeBind) {
+				id = message.FailedGetDataFromBody
+			}
+		}
+		localizer := message.Localizer(ctx)
+		msg := localizer.Translate(id)
+
+		if format == ErrorFormatEnvelope {
+			res := response.BuildResponseFailed(msg, detail, nil)
+			res.Code = code
+			ctx.AbortWithStatusJSON(status, res)
+			return
+		}
+
+		problem := response.Problem{
+			Type:     problemType(typeBaseURL, code),
+			Title:    msg,
+			Status:   status,
+			Detail:   detail,
+			Instance: ctx.Request.URL.Path,
+			Code:     code,
+			TraceID:  ctx.GetString(TraceIDKey),
+		}
+		if ginError.IsType(gin.ErrorTypeBind) {
+			problem.Detail, problem.Errors = bindingProblem(localizer, ginError.Err)
+		}
+
+		ctx.Abort()
+		ctx.Render(status, problemRender{problem})
+	}
+}
+
+// abortWithError stops the chain and leaves err for ErrorHandler, for
+// middleware that rejects a request before it reaches its handler.
+func abortWithError(ctx *gin.Context, err error, id string) {
+	_ = ctx.Error(err).SetMeta(id)
+	ctx.Abort()
+}
+
+func describeError(ginError *gin.Error) (status int, code string, detail string) {
+	if ginError.IsType(gin.ErrorTypeBind) {
+		return http.StatusBadRequest, CodeInvalidRequest, ginError.Err.Error()
+	}
+
+	domainError, ok := shared.AsError(ginError.Err)
+	if !ok {
+		return http.StatusInternalServerError, CodeInternal, http.StatusText(http.StatusInternalServerError)
+	}
+
+	status, ok = codeStatus[domainError.Code]
+	if !ok {
+		status, ok = categoryStatus[domainError.Category]
+	}
+	if !ok || domainError.Category == shared.CategoryInternal {
+		return http.StatusInternalServerError, domainError.Code, domainError.Message
+	}
+
+	// Errors wrapped with detail, such as the path of a missing file, are
+	// reported by their declared message. Only validation errors say more,
+	// since their detail is about the request.
+	if domainError.Category != shared.CategoryValidation {
+		return status, domainError.Code, domainError.Message
+	}
+	return status, domainError.Code, ginError.Err.Error()
+}
+
+// problemType is a URI under PROBLEM_TYPE_BASE_URL naming the code, or
+// about:blank when no base is configured.
+func problemType(base string, code string) string {
+	if base == "" {
+		return "about:blank"
+	}
+	return strings.TrimSuffix(base, "/") + "/" + code
+}
+
+func getErrorFormat() string {
+	if os.Getenv("ERROR_FORMAT") == ErrorFormatEnvelope {
+		return ErrorFormatEnvelope
+	}
+	return ErrorFormatProblem
+}
+
+// problemRender writes a problem as JSON under its own content type.
+type problemRender struct {
+	problem response.Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", response.ProblemContentType)
+}
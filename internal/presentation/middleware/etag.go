@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// versionKey holds the version of the resource a handler answered with,
+	// and ifMatchKey the version the client sent in If-Match.
+	versionKey = "resource_version"
+	ifMatchKey = "if_match_version"
+)
+
+// signedQueryPattern matches the query of a URL in a JSON body, which carries
+// the expiry and signature of signed storage URLs.
+var signedQueryPattern = regexp.MustCompile(`("(?:https?:)?/[^"?]*)\?[^"]*"`)
+
+var (
+	ErrorIfMatchRequired = shared.NewError(shared.CategoryPrecondition, "if_match_required", "If-Match is required to change this resource")
+	ErrorIfMatchInvalid  = shared.NewError(shared.CategoryPrecondition, "if_match_invalid", "If-Match does not name a version of this resource")
+)
+
+// ETag makes the routes of a versioned resource conditional. Handlers report
+// the version they answer with through SetVersion, and the response is tagged
+// with a weak ETag made of that version and a digest of the body, so
+// representations that differ by query or language get different tags. The
+// queries of URLs in the body are left out of the digest, since signed URLs
+// expire and are signed again on every response. A GET
+// whose If-None-Match lists the tag is answered with 304 Not Modified.
+//
+// PUT, PATCH and DELETE must send If-Match with a tag from an earlier response, or
+// *, and handlers read the version it names with IfMatchVersion to make the
+// write conditional on it.
+func ETag() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method == http.MethodPut || ctx.Request.Method == http.MethodPatch || ctx.Request.Method == http.MethodDelete {
+			version, err := parseIfMatch(ctx.GetHeader("If-Match"))
+			if err != nil {
+				abortWithError(ctx, err, message.FailedPrecondition)
+				return
+			}
+			ctx.Set(ifMatchKey, version)
+		}
+
+		writer := &bufferedWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		// Nothing written means the handler left an error for ErrorHandler.
+		if !writer.written {
+			return
+		}
+
+		status := ctx.Writer.Status()
+		version, ok := ctx.Get(versionKey)
+		if ok && status >= http.StatusOK && status < http.StatusMultipleChoices {
+			etag := weakETag(version.(int64), writer.body.Bytes())
+			ctx.Header("ETag", etag)
+
+			if ctx.Request.Method == http.MethodGet && noneMatch(ctx.GetHeader("If-None-Match"), etag) {
+				ctx.Writer.WriteHeader(http.StatusNotModified)
+				ctx.Writer.WriteHeaderNow()
+				return
+			}
+		}
+
+		ctx.Writer.WriteHeaderNow()
+		_, _ = ctx.Writer.Write(writer.body.Bytes())
+	}
+}
+
+// SetVersion reports the version of the resource the handler answers with.
+func SetVersion(ctx *gin.Context, version int64) {
+	ctx.Set(versionKey, version)
+}
+
+// IfMatchVersion is the version named by If-Match, or 0 when the client sent
+// * and accepts any version.
+func IfMatchVersion(ctx *gin.Context) int64 {
+	return ctx.GetInt64(ifMatchKey)
+}
+
+// parseIfMatch reads the version from a single tag made by weakETag. Weak tags
+// are accepted, since they are the only ones handed out.
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	switch header {
+	case "":
+		return 0, ErrorIfMatchRequired
+	case "*":
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, ErrorIfMatchInvalid
+	}
+	prefix, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version < 1 {
+		return 0, ErrorIfMatchInvalid
+	}
+	return version, nil
+}
+
+func weakETag(version int64, body []byte) string {
+	digest := sha256.Sum256(signedQueryPattern.ReplaceAll(body, []byte(`$1"`)))
+	return `W/"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(digest[:8]) + `"`
+}
+
+// noneMatch reports whether an If-None-Match header lists etag, comparing
+// tags weakly as RFC 9110 asks.
+func noneMatch(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds the body back until the ETag of the response is known.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from the request
+	// that first used its key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency honors an Idempotency-Key header on POST, PATCH and DELETE
+// requests. The first request with a key runs and its response is stored; a
+// retry with the same key and payload gets that response replayed, and one
+// sent while the first is still running, or with a different payload, is
+// rejected with 409. Keys are scoped to the Authorization header, so clients
+// never see each other's responses.
+//
+// Request bodies larger than IDEMPOTENCY_MAX_BODY_MB are rejected with 413
+// rather than read into memory, and streamed bodies, such as tus chunks, which
+// carry their own offsets, are passed through untouched.
+//
+// It must run before ErrorHandler to store the errors it renders, and so
+// renders its own rejections. Responses with a 5xx status, or larger than the
+// limit, are not stored, so a retry runs the request again.
+func Idempotency(idempotencyService service.IdempotencyService) gin.HandlerFunc {
+	renderError := errorRenderer()
+	maxBodySize := getIdempotencyMaxBodySize()
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isUnsafe(ctx.Request.Method) || isStreamed(ctx.Request) {
+			ctx.Next()
+			return
+		}
+
+		body, err := readBody(ctx, maxBodySize)
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				abortWithError(ctx, idempotency.ErrorBodyTooLarge, message.FailedIdempotency)
+			} else {
+				_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+				ctx.Abort()
+			}
+			renderError(ctx)
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		claim, err := idempotencyService.Begin(ctx.Request.Context(), digest(ctx.GetHeader("Authorization")), key, fingerprint(ctx.Request, body))
+		if err != nil {
+			abortWithError(ctx, err, message.FailedIdempotency)
+			renderError(ctx)
+			return
+		}
+		if claim.Replay != nil {
+			for name, values := range claim.Replay.Header {
+				ctx.Writer.Header()[name] = values
+			}
+			ctx.Header(IdempotentReplayedHeader, "true")
+			ctx.Writer.WriteHeader(claim.Replay.Status)
+			_, _ = ctx.Writer.Write(claim.Replay.Body)
+			ctx.Abort()
+			return
+		}
+
+		// The outcome is stored even if the client has gone away meanwhile.
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = idempotencyService.Release(storeCtx, claim.ID)
+				panic(recovered)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: ctx.Writer, limit: maxBodySize}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if status := ctx.Writer.Status(); status >= http.StatusInternalServerError || writer.overflowed {
+			err = idempotencyService.Release(storeCtx, claim.ID)
+		} else {
+			err = idempotencyService.Complete(storeCtx, claim.ID, response.IdempotentResponse{
+				Status: status,
+				Header: replayableHeader(ctx.Writer.Header()),
+				Body:   writer.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Printf("%s %s [%s]: failed to store the idempotent response: %v", ctx.Request.Method, ctx.Request.URL.Path, ctx.GetString(TraceIDKey), err)
+		}
+	}
+}
+
+func isUnsafe(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// isStreamed reports whether req carries a body that is meant to be streamed
+// to storage rather than held in memory.
+func isStreamed(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return mediaType == file.TusContentType
+}
+
+// readBody reads the request body up to limit bytes and puts it back for the
+// handlers. A larger body fails with *http.MaxBytesError.
+func readBody(ctx *gin.Context, limit int64) ([]byte, error) {
+	if ctx.Request.ContentLength > limit {
+		return nil, &http.MaxBytesError{Limit: limit}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// fingerprint identifies a request by its method, target and body.
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func digest(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayableHeader leaves out the headers that belong to a single exchange
+// rather than to the response.
+func replayableHeader(header http.Header) map[string][]string {
+	replayable := header.Clone()
+	replayable.Del(RequestIDHeader)
+	replayable.Del("Set-Cookie")
+	return replayable
+}
+
+func getIdempotencyMaxBodySize() int64 {
+	size, err := strconv.ParseInt(os.Getenv("IDEMPOTENCY_MAX_BODY_MB"), 10, 64)
+	if err != nil || size <= 0 {
+		return idempotency.DefaultMaxBodySize
+	}
+	return size << 20
+}
+
+// recordingWriter keeps a copy of the body it writes, until the body grows
+// past limit.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body       bytes.Buffer
+	limit      int64
+	overflowed bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.overflowed {
+		return
+	}
+	if int64(w.body.Len()+len(data)) > w.limit {
+		w.overflowed = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
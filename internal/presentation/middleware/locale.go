@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale negotiates the locale of the response from the Accept-Language
+// header and announces it in Content-Language.
+func Locale() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tag := message.Catalog.Match(ctx.GetHeader("Accept-Language"))
+
+		ctx.Set(message.LocalizerKey, message.Catalog.Localizer(tag))
+		ctx.Header("Content-Language", tag.String())
+		ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	TraceIDKey      = "trace_id"
+	RequestIDHeader = "X-Request-Id"
+)
+
+// traceparentPattern matches a W3C traceparent header and captures its trace id.
+var traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// Trace gives every request a trace id, taken from its traceparent or
+// X-Request-Id header when present, and echoes it as X-Request-Id.
+func Trace() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		traceID := ctx.GetHeader(RequestIDHeader)
+		if match := traceparentPattern.FindStringSubmatch(ctx.GetHeader("traceparent")); match != nil {
+			traceID = match[1]
+		}
+		if traceID == "" || len(traceID) > 128 {
+			id := uuid.New()
+			traceID = hex.EncodeToString(id[:])
+		}
+
+		ctx.Set(TraceIDKey, traceID)
+		ctx.Header(RequestIDHeader, traceID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TusVersion = "1.0.0"
+
+	// StatusChecksumMismatch is the status tus uses for a chunk whose
+	// Upload-Checksum does not match its content.
+	StatusChecksumMismatch = 460
+)
+
+var ErrorTusVersion = shared.NewError(shared.CategoryValidation, "tus_version_unsupported", "Tus-Resumable must be "+TusVersion)
+
+// TusResumable rejects tus requests made with a protocol version other than
+// TusVersion. OPTIONS is exempt because clients use it to discover the version.
+func TusResumable() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Tus-Resumable", TusVersion)
+
+		if ctx.Request.Method != http.MethodOptions && ctx.GetHeader("Tus-Resumable") != TusVersion {
+			ctx.Header("Tus-Version", TusVersion)
+			abortWithError(ctx, ErrorTusVersion, message.FailedTusVersion)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/i18n"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerTagNames sync.Once
+
+// useTagNames makes validation errors name fields by their JSON key, or their
+// form key for fields that are not part of a JSON body.
+func useTagNames() {
+	registerTagNames.Do(func() {
+		validate, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	})
+}
+
+// bindingProblem describes why a request could not be bound, with one entry
+// per invalid field when they are known, in the language of localizer.
+func bindingProblem(localizer i18n.Localizer, err error) (string, []response.FieldError) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]response.FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fieldErrors = append(fieldErrors, response.FieldError{
+				Field:   fieldPath(fieldError.Namespace()),
+				Rule:    fieldError.Tag(),
+				Message: ruleMessage(localizer, fieldError),
+			})
+		}
+		return invalidFields(localizer, len(fieldErrors)), fieldErrors
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return invalidFields(localizer, 1), []response.FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: localizer.Translate(message.ValidationType, i18n.Params{"param": typeError.Type.Kind().String()}),
+		}}
+	}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return localizer.Translate(message.ValidationMalformedBody), nil
+	}
+
+	return err.Error(), nil
+}
+
+func invalidFields(localizer i18n.Localizer, count int) string {
+	return localizer.Translate(message.ValidationInvalidFields, i18n.Params{"count": count})
+}
+
+// fieldPath drops the struct name validator puts in front of every field.
+func fieldPath(namespace string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+	return path
+}
+
+// ruleMessage explains a failed rule, counting characters for the length
+// rules of strings.
+func ruleMessage(localizer i18n.Localizer, fieldError validator.FieldError) string {
+	if fieldError.Kind() == reflect.String {
+		switch fieldError.Tag() {
+		case "min":
+			return localizer.Translate(message.ValidationMinLength, i18n.Params{"count": fieldError.Param()})
+		case "max":
+			return localizer.Translate(message.ValidationMaxLength, i18n.Params{"count": fieldError.Param()})
+		}
+	}
+
+	id := message.ValidationRuleID(fieldError.Tag())
+	if !message.Catalog.Has(id) {
+		return localizer.Translate(message.ValidationRule, i18n.Params{"rule": fieldError.Tag()})
+	}
+	return localizer.Translate(id, i18n.Params{"param": fieldError.Param()})
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIVersionHeader = "API-Version"
+
+	// VersionMediaTypePrefix and VersionMediaTypeSuffix surround the version
+	// in a media type that selects it, such as
+	// application/vnd.gin-clean-architecture.v2+json.
+	VersionMediaTypePrefix = "application/vnd.gin-clean-architecture."
+	VersionMediaTypeSuffix = "+json"
+)
+
+var ErrorVersionNotAcceptable = shared.NewError(shared.CategoryValidation, "api_version_not_acceptable", "requested API version is not supported")
+
+// Deprecation describes when a version or endpoint was deprecated and, when
+// known, when it stops being served and what replaces it.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+	// Successor is the path that replaces the deprecated one.
+	Successor string
+}
+
+// APIVersion announces the version that served the request.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header(APIVersionHeader, version)
+		ctx.Next()
+	}
+}
+
+// Deprecated announces a deprecation with the Deprecation header of RFC 9745
+// and the Sunset header of RFC 8594.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+		if !deprecation.Sunset.IsZero() {
+			ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if deprecation.Successor != "" {
+			ctx.Writer.Header().Add("Link", "<"+deprecation.Successor+`>; rel="successor-version"`)
+		}
+		ctx.Next()
+	}
+}
+
+// RouteVersion serves requests to the unversioned prefix whose Accept header
+// asks for a supported version, such as
+// application/vnd.gin-clean-architecture.v2+json, from that version's routes
+// under prefix/<version>. The path is rewritten before handler routes the
+// request, so it passes through the global middleware once.
+func RouteVersion(handler http.Handler, prefix string, versions []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rest, ok := unversionedPath(req.URL.Path, prefix, versions)
+		if !ok {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		requested, ok := acceptedVersion(req.Header.Get("Accept"))
+		if !ok || !slices.Contains(versions, requested) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept")
+		req.URL.Path = prefix + "/" + requested + rest
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = prefix + "/" + requested + strings.TrimPrefix(req.URL.RawPath, prefix)
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// NegotiateVersion guards the routes of the unversioned prefix, which
+// RouteVersion leaves to requests that ask for no version or for one that is
+// not supported. The latter are rejected with 406.
+func NegotiateVersion(versions []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Writer.Header().Add("Vary", "Accept")
+
+		requested, ok := acceptedVersion(ctx.GetHeader("Accept"))
+		if ok && !slices.Contains(versions, requested) {
+			abortWithError(ctx, ErrorVersionNotAcceptable, message.FailedNegotiateVersion)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// unversionedPath returns what follows prefix in path, unless path is outside
+// prefix or already names a version.
+func unversionedPath(path string, prefix string, versions []string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	if slices.Contains(versions, segment) {
+		return "", false
+	}
+	return rest, true
+}
+
+// acceptedVersion returns the version named by the first versioned media
+// type in an Accept header.
+func acceptedVersion(accept string) (string, bool) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || !strings.HasPrefix(mediaType, VersionMediaTypePrefix) || !strings.HasSuffix(mediaType, VersionMediaTypeSuffix) {
+			continue
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(mediaType, VersionMediaTypePrefix), VersionMediaTypeSuffix), true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+)
+
+const (
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs"
+)
+
+//go:embed swagger.html
+var swaggerPage []byte
+
+// Route serves the OpenAPI document of every route registered so far, so it
+// must be registered last. The document is also provided to the injector.
+func Route(injector do.Injector) {
+	route := do.MustInvoke[*gin.Engine](injector)
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	var content []byte
+	route.GET(SpecPath, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json; charset=utf-8", content)
+	})
+	route.GET(UIPath, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerPage)
+	})
+
+	spec.Document(&route.RouterGroup, http.MethodGet, SpecPath, openapi.Operation{
+		ID:          "getOpenAPIDocument",
+		Summary:     "Get the OpenAPI document of this API",
+		Tags:        []string{"docs"},
+		ContentType: "application/json",
+	})
+	spec.Document(&route.RouterGroup, http.MethodGet, UIPath, openapi.Operation{
+		ID:          "getAPIDocumentation",
+		Summary:     "Browse the API documentation",
+		Tags:        []string{"docs"},
+		ContentType: "text/html",
+	})
+
+	document := spec.MustBuild(route.Routes())
+	encoded, err := document.JSON()
+	if err != nil {
+		panic(err)
+	}
+	content = encoded
+
+	do.ProvideValue(injector, document)
+}
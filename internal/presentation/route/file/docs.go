@@ -0,0 +1,103 @@
+package file
+
+import (
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	storageTags = []string{"storage"}
+	uploadTags  = []string{"upload"}
+
+	tusResumable = openapi.HeaderParameter{Name: "Tus-Resumable", Description: "tus protocol version, 1.0.0", Required: true}
+	uploadOffset = openapi.HeaderParameter{Name: "Upload-Offset", Description: "Bytes received so far", Type: "integer"}
+	uploadExpiry = openapi.HeaderParameter{Name: "Upload-Expires", Description: "When an unfinished upload is deleted"}
+	fileID       = openapi.HeaderParameter{Name: "File-Id", Description: "File created when the upload completed"}
+)
+
+func document(spec *openapi.Spec, api version.API, storageGroup *gin.RouterGroup, filesGroup *gin.RouterGroup) {
+	download := openapi.Operation{
+		ID:          "downloadFile",
+		Summary:     "Download a stored file through a signed URL",
+		Tags:        storageTags,
+		Query:       request.FileDownload{},
+		ContentType: "*/*",
+	}
+	api.Document(spec, storageGroup, http.MethodGet, "/*path", download)
+
+	download.ID = "headFile"
+	download.Summary = "Get the headers of a stored file through a signed URL"
+	download.ContentType = ""
+	api.Document(spec, storageGroup, http.MethodHead, "/*path", download)
+
+	api.Document(spec, filesGroup, http.MethodOptions, "/", openapi.Operation{
+		ID:      "discoverUploads",
+		Summary: "Discover the tus version, extensions and limits",
+		Tags:    uploadTags,
+		Status:  http.StatusNoContent,
+		ResponseHeaders: []openapi.HeaderParameter{
+			{Name: "Tus-Version"},
+			{Name: "Tus-Extension"},
+			{Name: "Tus-Max-Size", Type: "integer"},
+			{Name: "Tus-Checksum-Algorithm"},
+		},
+	})
+	api.Document(spec, filesGroup, http.MethodPost, "/", openapi.Operation{
+		ID:      "createUpload",
+		Summary: "Create a resumable upload",
+		Tags:    uploadTags,
+		Secured: true,
+		Headers: []openapi.HeaderParameter{
+			tusResumable,
+			{Name: "Upload-Length", Description: "Size of the file in bytes", Required: true, Type: "integer"},
+			{Name: "Upload-Metadata", Description: "Comma-separated keys with base64 values, such as filename"},
+		},
+		Status: http.StatusCreated,
+		ResponseHeaders: []openapi.HeaderParameter{
+			{Name: "Location", Description: "URL of the upload"},
+			uploadOffset,
+			uploadExpiry,
+		},
+	})
+	api.Document(spec, filesGroup, http.MethodHead, "/:id", openapi.Operation{
+		ID:      "getUploadOffset",
+		Summary: "Get the offset of an upload",
+		Tags:    uploadTags,
+		Secured: true,
+		Headers: []openapi.HeaderParameter{tusResumable},
+		ResponseHeaders: []openapi.HeaderParameter{
+			{Name: "Upload-Length", Type: "integer"},
+			{Name: "Upload-Metadata"},
+			uploadOffset,
+			uploadExpiry,
+			fileID,
+		},
+	})
+	api.Document(spec, filesGroup, http.MethodPatch, "/:id", openapi.Operation{
+		ID:      "patchUpload",
+		Summary: "Append a chunk to an upload",
+		Tags:    uploadTags,
+		Secured: true,
+		Headers: []openapi.HeaderParameter{
+			tusResumable,
+			{Name: "Upload-Offset", Description: "Offset the chunk starts at", Required: true, Type: "integer"},
+			{Name: "Upload-Checksum", Description: "Algorithm and base64 digest of the chunk"},
+		},
+		BodyContentType: file.TusContentType,
+		Status:          http.StatusNoContent,
+		ResponseHeaders: []openapi.HeaderParameter{uploadOffset, uploadExpiry, fileID},
+	})
+	api.Document(spec, filesGroup, http.MethodDelete, "/:id", openapi.Operation{
+		ID:      "terminateUpload",
+		Summary: "Terminate an upload",
+		Tags:    uploadTags,
+		Secured: true,
+		Headers: []openapi.HeaderParameter{tusResumable},
+		Status:  http.StatusNoContent,
+	})
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/samber/do/v2"
+)
+
+func Route(injector do.Injector, api version.API) {
+	jwtService := do.MustInvoke[service.JWTService](injector)
+	fileController := do.MustInvoke[controller.FileController](injector)
+	uploadController := do.MustInvoke[controller.UploadController](injector)
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	storageGroup := api.Group.Group("/storage")
+	{
+		storageGroup.GET("/*path", fileController.Download)
+		storageGroup.HEAD("/*path", fileController.Download)
+	}
+
+	filesGroup := api.Group.Group("/files", middleware.TusResumable())
+	{
+		filesGroup.OPTIONS("/", uploadController.Options)
+		filesGroup.POST("/", middleware.Authenticate(jwtService), uploadController.Create)
+		filesGroup.HEAD("/:id", middleware.Authenticate(jwtService), uploadController.Head)
+		filesGroup.PATCH("/:id", middleware.Authenticate(jwtService), uploadController.Patch)
+		filesGroup.DELETE("/:id", middleware.Authenticate(jwtService), uploadController.Terminate)
+	}
+
+	document(spec, api, storageGroup, filesGroup)
+}
@@ -1,20 +1,95 @@
 package route
 
 import (
-	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/user"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/docs"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do/v2"
 )
 
+const APIPrefix = "/api"
+
+// LegacyDeprecatedAt is when the unversioned /api routes were deprecated in
+// favour of /api/v1.
+var LegacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// UseGlobalMiddleware adds the middleware every request passes through to
+// engine. ErrorHandler comes last so the others see the rendered errors.
+func UseGlobalMiddleware(engine *gin.Engine, injector do.Injector) {
+	engine.Use(middleware.CORSMiddleware())
+	engine.Use(middleware.Trace())
+	engine.Use(middleware.Locale())
+	engine.Use(middleware.Idempotency(do.MustInvoke[service.IdempotencyService](injector)))
+	engine.Use(middleware.ErrorHandler())
+}
+
+// RegisterBaseRoute provides a version.API for every version under
+// /api/<version>, and for the deprecated unversioned /api. Requests to /api
+// may still pick a version through their Accept header.
 func RegisterBaseRoute(injector do.Injector) {
 	route := do.MustInvoke[*gin.Engine](injector)
 
-	apiGroup := route.Group("/api")
+	apis := make([]version.API, 0, len(version.Versions)+1)
+	for _, name := range version.Versions {
+		apis = append(apis, version.API{
+			Version: name,
+			Group:   route.Group(APIPrefix+"/"+name, middleware.APIVersion(name)),
+		})
+	}
 
-	do.ProvideValue(injector, apiGroup)
+	legacyGroup := route.Group(APIPrefix,
+		middleware.NegotiateVersion(version.Versions),
+		middleware.APIVersion(version.V1),
+		middleware.Deprecated(middleware.Deprecation{
+			Since:     LegacyDeprecatedAt,
+			Sunset:    getLegacySunset(),
+			Successor: APIPrefix + "/" + version.V1,
+		}),
+	)
+	apis = append(apis, version.API{
+		Version:    version.V1,
+		Group:      legacyGroup,
+		Deprecated: true,
+	})
+
+	do.ProvideValue(injector, apis)
+	do.ProvideValue(injector, openapi.NewSpec(openapi.Info{
+		Title:       "Gin Clean Architecture API",
+		Description: "Users, authentication and file storage.",
+		Version:     "1.0.0",
+	}))
+}
+
+// Handler serves engine, routing requests to /api that ask for a version
+// through their Accept header to that version's routes.
+func Handler(engine *gin.Engine) http.Handler {
+	return middleware.RouteVersion(engine, APIPrefix, version.Versions)
 }
 
 func RegisterRoutes(injector do.Injector) {
 	RegisterBaseRoute(injector)
-	user.Route(injector)
+	for _, api := range do.MustInvoke[[]version.API](injector) {
+		for _, m := range module.Modules() {
+			m.Routes(injector, api)
+		}
+	}
+	docs.Route(injector)
+}
+
+// getLegacySunset reads API_LEGACY_SUNSET, the date after which the
+// unversioned routes may be removed.
+func getLegacySunset() time.Time {
+	sunset, err := time.Parse(time.DateOnly, os.Getenv("API_LEGACY_SUNSET"))
+	if err != nil {
+		return time.Time{}
+	}
+	return sunset
 }
@@ -0,0 +1,131 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller/v2"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/fieldset"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+var tags = []string{"user"}
+
+var (
+	etag        = openapi.HeaderParameter{Name: "ETag", Description: "Weak tag of the version of the user"}
+	ifMatch     = openapi.HeaderParameter{Name: "If-Match", Description: "ETag the change is based on, or *", Required: true}
+	ifNoneMatch = openapi.HeaderParameter{Name: "If-None-Match", Description: "ETag of a cached copy, answered with 304 while it is current"}
+)
+
+// listQuery documents the query of the user list, which selects fields like
+// fieldset.Request but cannot include relations.
+type listQuery struct {
+	pagination.Request
+	Fields string `form:"fields"`
+}
+
+func document(spec *openapi.Spec, api version.API, userGroup *gin.RouterGroup) {
+	var user, createdUser, users any = response.User{}, response.UserCreate{}, []response.User{}
+	if api.Version == version.V2 {
+		user, createdUser, users = v2.User{}, v2.User{}, []v2.User{}
+	}
+
+	api.Document(spec, userGroup, http.MethodPost, "/register", openapi.Operation{
+		ID:      "registerUser",
+		Summary: "Register a user",
+		Tags:    tags,
+		Body:    request.UserRegister{},
+		Status:  http.StatusCreated,
+		Data:    createdUser,
+	})
+	api.Document(spec, userGroup, http.MethodPost, "/login", openapi.Operation{
+		ID:      "login",
+		Summary: "Log in with email and password",
+		Tags:    tags,
+		Body:    request.UserLogin{},
+		Data:    response.RefreshToken{},
+	})
+	api.Document(spec, userGroup, http.MethodGet, "/me", openapi.Operation{
+		ID:              "getCurrentUser",
+		Summary:         "Get the current user",
+		Description:     "Select fields with fields=id,name,image_url and embed the active sessions with include=sessions.",
+		Tags:            tags,
+		Secured:         true,
+		Query:           fieldset.Request{},
+		Headers:         []openapi.HeaderParameter{ifNoneMatch},
+		Data:            user,
+		ResponseHeaders: []openapi.HeaderParameter{etag},
+	})
+	api.Document(spec, userGroup, http.MethodPut, "/me/avatar", openapi.Operation{
+		ID:      "updateAvatar",
+		Summary: "Set the avatar of the current user",
+		Description: "Upload an image, or reuse a completed upload by its file_id. " +
+			"Answers 412 when the user has changed since the ETag in If-Match was read.",
+		Tags:            tags,
+		Secured:         true,
+		Body:            request.UserAvatar{},
+		Headers:         []openapi.HeaderParameter{ifMatch},
+		Data:            user,
+		ResponseHeaders: []openapi.HeaderParameter{etag},
+	})
+	api.Document(spec, userGroup, http.MethodDelete, "/me/avatar", openapi.Operation{
+		ID:              "deleteAvatar",
+		Summary:         "Remove the avatar of the current user",
+		Description:     "Answers 412 when the user has changed since the ETag in If-Match was read.",
+		Tags:            tags,
+		Secured:         true,
+		Headers:         []openapi.HeaderParameter{ifMatch},
+		Envelope:        true,
+		ResponseHeaders: []openapi.HeaderParameter{etag},
+	})
+	api.Document(spec, userGroup, http.MethodPost, "/refresh-token", openapi.Operation{
+		ID:      "refreshToken",
+		Summary: "Exchange a refresh token for new tokens",
+		Tags:    tags,
+		Body:    request.RefreshToken{},
+		Data:    response.RefreshToken{},
+	})
+	api.Document(spec, userGroup, http.MethodPost, "/logout", openapi.Operation{
+		ID:       "logout",
+		Summary:  "Revoke the refresh tokens of the current user",
+		Tags:     tags,
+		Secured:  true,
+		Envelope: true,
+	})
+	api.Document(spec, userGroup, http.MethodGet, "/", openapi.Operation{
+		ID:      "listUsers",
+		Summary: "List users",
+		Description: "Sort by name, email or created_at, such as sort=-created_at,name. " +
+			"Filter with filter[field]=value or filter[field][op]=value on name, email, role, is_verified or created_at, " +
+			"where op is one of eq, ne, gt, gte, lt, lte or in. Select fields with fields=id,name,image_url.",
+		Tags:    tags,
+		Secured: true,
+		Query:   listQuery{},
+		Data:    users,
+		Meta:    pagination.Response{},
+	})
+	api.Document(spec, userGroup, http.MethodPatch, "/", openapi.Operation{
+		ID:              "updateCurrentUser",
+		Summary:         "Update the current user",
+		Description:     "Answers 412 when the user has changed since the ETag in If-Match was read.",
+		Tags:            tags,
+		Secured:         true,
+		Body:            request.UserUpdate{},
+		Headers:         []openapi.HeaderParameter{ifMatch},
+		Data:            response.UserUpdate{},
+		ResponseHeaders: []openapi.HeaderParameter{etag},
+	})
+	api.Document(spec, userGroup, http.MethodDelete, "/", openapi.Operation{
+		ID:          "deleteCurrentUser",
+		Summary:     "Delete the current user",
+		Description: "Answers 412 when the user has changed since the ETag in If-Match was read.",
+		Tags:        tags,
+		Secured:     true,
+		Headers:     []openapi.HeaderParameter{ifMatch},
+		Envelope:    true,
+	})
+}
@@ -4,24 +4,29 @@ import (
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
-	"github.com/gin-gonic/gin"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
 	"github.com/samber/do/v2"
 )
 
-func Route(injector do.Injector) {
-	baseRoute := do.MustInvoke[*gin.RouterGroup](injector)
+func Route(injector do.Injector, api version.API) {
 	jwtService := do.MustInvoke[service.JWTService](injector)
-	userController := do.MustInvoke[controller.UserController](injector)
+	userController := do.MustInvokeNamed[controller.UserController](injector, api.Version)
+	spec := do.MustInvoke[*openapi.Spec](injector)
 
-	userGroup := baseRoute.Group("/user")
+	userGroup := api.Group.Group("/user")
 	{
 		userGroup.POST("/register", userController.Register)
 		userGroup.POST("/login", userController.Login)
-		userGroup.GET("/me", middleware.Authenticate(jwtService), userController.Me)
+		userGroup.GET("/me", middleware.Authenticate(jwtService), middleware.ETag(), userController.Me)
+		userGroup.PUT("/me/avatar", middleware.Authenticate(jwtService), middleware.ETag(), userController.UpdateAvatar)
+		userGroup.DELETE("/me/avatar", middleware.Authenticate(jwtService), middleware.ETag(), userController.DeleteAvatar)
 		userGroup.POST("/refresh-token", userController.RefreshToken)
 		userGroup.POST("/logout", middleware.Authenticate(jwtService), userController.Logout)
 		userGroup.GET("/", middleware.Authenticate(jwtService), userController.GetAll)
-		userGroup.PATCH("/", middleware.Authenticate(jwtService), userController.Update)
-		userGroup.DELETE("/", middleware.Authenticate(jwtService), userController.Delete)
+		userGroup.PATCH("/", middleware.Authenticate(jwtService), middleware.ETag(), userController.Update)
+		userGroup.DELETE("/", middleware.Authenticate(jwtService), middleware.ETag(), userController.Delete)
 	}
+
+	document(spec, api, userGroup)
 }
@@ -0,0 +1,50 @@
+package version
+
+import (
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	V1 = "v1"
+	V2 = "v2"
+
+	// Latest is the version new clients should use.
+	Latest = V2
+)
+
+// Versions lists the supported versions, oldest first.
+var Versions = []string{V1, V2}
+
+// API is a route group serving one version of the API. The unversioned /api
+// group is a deprecated alias of V1 kept for existing clients.
+type API struct {
+	Version    string
+	Group      *gin.RouterGroup
+	Deprecated bool
+}
+
+// Name identifies the group in operation IDs and tags.
+func (a API) Name() string {
+	if a.Deprecated {
+		return "legacy"
+	}
+	return a.Version
+}
+
+// Document describes a route registered on group, a group of this API.
+// Operation IDs and tags are prefixed with the API's name to keep them apart
+// across versions, and operations of a deprecated API are marked as such.
+func (a API) Document(spec *openapi.Spec, group *gin.RouterGroup, method string, relativePath string, operation openapi.Operation) {
+	operation.ID = a.Name() + strings.ToUpper(operation.ID[:1]) + operation.ID[1:]
+	tags := make([]string, len(operation.Tags))
+	for i, tag := range operation.Tags {
+		tags[i] = a.Name() + "/" + tag
+	}
+	operation.Tags = tags
+	operation.Deprecated = operation.Deprecated || a.Deprecated
+
+	spec.Document(group, method, relativePath, operation)
+}
@@ -1,31 +1,56 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/fawwasaldy/gin-clean-architecture/command"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
 	"github.com/fawwasaldy/gin-clean-architecture/platform/provider"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do/v2"
 	"gorm.io/gorm"
 )
 
-func args(db *gorm.DB) bool {
-	if len(os.Args) > 1 {
-		flag := command.Commands(db)
+func args(injector do.Injector, commands []string) bool {
+	if len(commands) > 0 {
+		flag := command.Commands(injector, commands)
 		return flag
 	}
 
 	return true
 }
 
-func run(server *gin.Engine) {
-	server.Static("/assets", "./assets")
+// runJobs starts the background jobs of every registered module.
+func runJobs(injector do.Injector) {
+	for _, m := range module.Modules() {
+		for _, job := range m.Jobs(injector) {
+			go runPeriodically(job)
+		}
+	}
+}
 
+func runPeriodically(job module.Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		processed, err := job.Run(context.Background())
+		if err != nil {
+			log.Printf("error running %s: %v", job.Name, err)
+		}
+		if processed > 0 {
+			log.Printf("%s: processed %d", job.Name, processed)
+		}
+	}
+}
+
+func run(server *gin.Engine) {
 	if os.Getenv("IS_LOGGER") == "true" {
 		route.LoggerRoute(server)
 	}
@@ -42,12 +67,28 @@ func run(server *gin.Engine) {
 		serve = ":" + port
 	}
 
-	if err := server.Run(serve); err != nil {
+	log.Printf("listening on %s", serve)
+	if err := http.ListenAndServe(serve, route.Handler(server)); err != nil {
 		log.Fatalf("error running server: %v", err)
 	}
 }
 
 func main() {
+	// The generator only writes source files, so it runs before anything
+	// connects to the database.
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := command.Generate(".", os.Args[2:]); err != nil {
+			log.Fatalf("error generating: %v", err)
+		}
+		return
+	}
+
+	// So does the OpenAPI document, which only needs the routes.
+	commands := command.OpenAPI(os.Args[1:])
+	if len(os.Args) > 1 && len(commands) == 0 {
+		return
+	}
+
 	var (
 		injector = do.New()
 	)
@@ -58,16 +99,18 @@ func main() {
 
 	defer config.CloseDatabaseConnection(db)
 
-	if !args(db) {
+	if !args(injector, commands) {
 		return
 	}
 
 	server := gin.Default()
-	server.Use(middleware.CORSMiddleware())
+	route.UseGlobalMiddleware(server, injector)
 
 	do.ProvideValue(injector, server)
 
 	route.RegisterRoutes(injector)
 
+	runJobs(injector)
+
 	run(server)
 }
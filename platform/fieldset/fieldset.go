@@ -0,0 +1,101 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+)
+
+var (
+	ErrorFieldUnknown    = shared.NewError(shared.CategoryValidation, "field_unknown", "cannot select this field")
+	ErrorRelationUnknown = shared.NewError(shared.CategoryValidation, "relation_unknown", "cannot include this relation")
+)
+
+type (
+	// Request is the query of a read endpoint that trims its response:
+	// fields=id,name selects fields and include=sessions embeds relations.
+	Request struct {
+		Fields  string `form:"fields"`
+		Include string `form:"include"`
+	}
+
+	// Set whitelists, by their JSON names, the fields of a resource clients
+	// may select and the relations they may include. Fields that are not
+	// listed, such as anything sensitive, can never be selected.
+	Set struct {
+		Fields    []string
+		Relations []string
+	}
+
+	// Selection is a Request checked against a Set.
+	Selection struct {
+		fields    []string
+		relations []string
+	}
+)
+
+// Parse checks req against the set. A request without fields selects every
+// field.
+func (s Set) Parse(req Request) (Selection, error) {
+	fields, err := parseList(req.Fields, s.Fields, ErrorFieldUnknown)
+	if err != nil {
+		return Selection{}, err
+	}
+	relations, err := parseList(req.Include, s.Relations, ErrorRelationUnknown)
+	if err != nil {
+		return Selection{}, err
+	}
+	return Selection{fields: fields, relations: relations}, nil
+}
+
+// Includes reports whether the client asked to embed relation.
+func (s Selection) Includes(relation string) bool {
+	return slices.Contains(s.relations, relation)
+}
+
+// Project trims resource, which must marshal to a JSON object, to the selected
+// fields and adds the included relations loaded into relations. Without a
+// selection it returns resource as it is.
+func (s Selection) Project(resource any, relations map[string]any) (any, error) {
+	if s.fields == nil && s.relations == nil {
+		return resource, nil
+	}
+
+	content, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(content, &object); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]any, len(object)+len(s.relations))
+	for name, value := range object {
+		if s.fields == nil || slices.Contains(s.fields, name) {
+			projected[name] = value
+		}
+	}
+	for _, relation := range s.relations {
+		projected[relation] = relations[relation]
+	}
+	return projected, nil
+}
+
+func parseList(list string, allowed []string, unknown error) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(names, name) {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("%w: %s", unknown, name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
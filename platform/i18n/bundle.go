@@ -0,0 +1,122 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Bundle holds the messages of every supported locale, keyed by message ID.
+type Bundle struct {
+	defaultLanguage language.Tag
+	languages       []language.Tag
+	matcher         language.Matcher
+	messages        map[language.Tag]map[string]Message
+}
+
+// NewBundle loads one catalog per JSON file in dir of fsys, named after its
+// locale such as en.json or id.json. The default locale must be among them;
+// it answers for requests no other locale matches and for IDs another locale
+// has not translated.
+func NewBundle(defaultLanguage language.Tag, fsys fs.FS, dir string) (*Bundle, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{
+		defaultLanguage: defaultLanguage,
+		languages:       []language.Tag{defaultLanguage},
+		messages:        make(map[language.Tag]map[string]Message, len(paths)),
+	}
+	for _, filePath := range paths {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(filePath), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", filePath, err)
+		}
+
+		content, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]Message
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", filePath, err)
+		}
+
+		bundle.messages[tag] = messages
+		if tag != defaultLanguage {
+			bundle.languages = append(bundle.languages, tag)
+		}
+	}
+
+	if _, ok := bundle.messages[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("no catalog for the default locale %s", defaultLanguage)
+	}
+	bundle.matcher = language.NewMatcher(bundle.languages)
+
+	return bundle, nil
+}
+
+func MustNewBundle(defaultLanguage language.Tag, fsys fs.FS, dir string) *Bundle {
+	bundle, err := NewBundle(defaultLanguage, fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return bundle
+}
+
+func (b *Bundle) DefaultLanguage() language.Tag {
+	return b.defaultLanguage
+}
+
+func (b *Bundle) Languages() []language.Tag {
+	return b.languages
+}
+
+// Match picks the supported locale that best fits an Accept-Language header,
+// or the default locale when none does.
+func (b *Bundle) Match(acceptLanguage string) language.Tag {
+	_, index := language.MatchStrings(b.matcher, acceptLanguage)
+	return b.languages[index]
+}
+
+func (b *Bundle) Localizer(tag language.Tag) Localizer {
+	return Localizer{
+		bundle:   b,
+		language: tag,
+	}
+}
+
+// Has reports whether the default locale defines id.
+func (b *Bundle) Has(id string) bool {
+	_, ok := b.messages[b.defaultLanguage][id]
+	return ok
+}
+
+// Missing lists the IDs the default locale defines that tag does not
+// translate.
+func (b *Bundle) Missing(tag language.Tag) []string {
+	var missing []string
+	for id := range b.messages[b.defaultLanguage] {
+		if _, ok := b.messages[tag][id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+func (b *Bundle) lookup(tag language.Tag, id string) (Message, language.Tag, bool) {
+	if msg, ok := b.messages[tag][id]; ok {
+		return msg, tag, true
+	}
+	msg, ok := b.messages[b.defaultLanguage][id]
+	return msg, b.defaultLanguage, ok
+}
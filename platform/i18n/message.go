@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Params are interpolated into a message wherever {name} appears. A "count"
+// parameter also selects the plural form.
+type Params map[string]any
+
+const countParam = "count"
+
+// Message is a catalog entry. In a catalog file it is either a string, or an
+// object with a form per plural category for messages that take a count.
+type Message struct {
+	Zero  string `json:"zero,omitempty"`
+	One   string `json:"one,omitempty"`
+	Few   string `json:"few,omitempty"`
+	Many  string `json:"many,omitempty"`
+	Other string `json:"other"`
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = Message{Other: text}
+		return nil
+	}
+
+	type plain Message
+	var forms plain
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	if forms.Other == "" {
+		return fmt.Errorf("message %s has no other form", data)
+	}
+	*m = Message(forms)
+	return nil
+}
+
+func (m Message) form(category PluralCategory) string {
+	var text string
+	switch category {
+	case PluralZero:
+		text = m.Zero
+	case PluralOne:
+		text = m.One
+	case PluralFew:
+		text = m.Few
+	case PluralMany:
+		text = m.Many
+	}
+	if text == "" {
+		return m.Other
+	}
+	return text
+}
+
+// Localizer translates messages into one locale.
+type Localizer struct {
+	bundle   *Bundle
+	language language.Tag
+}
+
+func (l Localizer) Language() language.Tag {
+	return l.language
+}
+
+// Translate returns the message id in the localizer's locale, falling back to
+// the default locale and then to the id itself.
+func (l Localizer) Translate(id string, params ...Params) string {
+	if l.bundle == nil {
+		return id
+	}
+
+	msg, tag, ok := l.bundle.lookup(l.language, id)
+	if !ok {
+		return id
+	}
+
+	var merged Params
+	for _, p := range params {
+		if merged == nil {
+			merged = make(Params, len(p))
+		}
+		for key, value := range p {
+			merged[key] = value
+		}
+	}
+
+	text := msg.Other
+	if count, ok := merged[countParam]; ok {
+		text = msg.form(pluralCategory(tag, count))
+	}
+	return interpolate(text, merged)
+}
+
+func interpolate(text string, params Params) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for key, value := range params {
+		replacements = append(replacements, "{"+key+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"strconv"
+
+	"golang.org/x/text/language"
+)
+
+// PluralCategory is a CLDR plural category.
+type PluralCategory string
+
+const (
+	PluralZero  PluralCategory = "zero"
+	PluralOne   PluralCategory = "one"
+	PluralFew   PluralCategory = "few"
+	PluralMany  PluralCategory = "many"
+	PluralOther PluralCategory = "other"
+)
+
+// withoutPlurals lists languages that use the same form for every count.
+var withoutPlurals = map[string]bool{
+	"id": true,
+	"ms": true,
+	"ja": true,
+	"ko": true,
+	"zh": true,
+	"th": true,
+	"vi": true,
+}
+
+// pluralCategory follows the CLDR cardinal rules of the supported languages;
+// any other language gets the English rule of one for exactly 1.
+func pluralCategory(tag language.Tag, count any) PluralCategory {
+	base, _ := tag.Base()
+	if withoutPlurals[base.String()] {
+		return PluralOther
+	}
+
+	if n, ok := toFloat(count); ok && n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,95 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+// Module bundles everything a bounded context contributes to the application,
+// so a new one plugs in by registering itself instead of editing the
+// providers, routes and migrations of every other.
+type Module interface {
+	Name() string
+	Providers(injector do.Injector)
+	Routes(injector do.Injector, api version.API)
+	Tables() []any
+	Migrations() []Migration
+	Seed(db *gorm.DB) error
+	Jobs(injector do.Injector) []Job
+}
+
+// Job is a background task run every Interval. Run returns how many items it
+// processed.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (int, error)
+}
+
+// Migration is a step run after the tables of every module are migrated, for
+// what AutoMigrate cannot express, such as driver specific indexes. Steps run
+// on every migration, so they must be idempotent.
+type Migration struct {
+	Name string
+	Run  func(db *gorm.DB) error
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Module{}
+)
+
+// Register adds modules to the registry, usually from the init function of
+// their package. Registering two modules under the same name panics.
+func Register(modules ...Module) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, m := range modules {
+		if _, ok := registry[m.Name()]; ok {
+			panic(fmt.Sprintf("module %q is already registered", m.Name()))
+		}
+		registry[m.Name()] = m
+	}
+}
+
+// Modules returns the registered modules ordered by name.
+func Modules() []Module {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	modules := make([]Module, 0, len(registry))
+	for _, m := range registry {
+		modules = append(modules, m)
+	}
+	slices.SortFunc(modules, func(a, b Module) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return modules
+}
+
+// Tables returns the tables of every registered module.
+func Tables() []any {
+	var tables []any
+	for _, m := range Modules() {
+		tables = append(tables, m.Tables()...)
+	}
+	return tables
+}
+
+// Migrations returns the migration steps of every registered module.
+func Migrations() []Migration {
+	var migrations []Migration
+	for _, m := range Modules() {
+		migrations = append(migrations, m.Migrations()...)
+	}
+	return migrations
+}
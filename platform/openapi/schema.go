@@ -0,0 +1,215 @@
+package openapi
+
+import (
+	"mime/multipart"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	fileHeaderType = reflect.TypeFor[multipart.FileHeader]()
+)
+
+// schemaGenerator derives schemas from Go types. JSON schemas of named
+// structs become components named after their package and type, such as
+// request.UserRegister; form schemas are inlined.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{schemas: make(map[string]*Schema)}
+}
+
+// jsonSchema describes value as encoded by encoding/json.
+func (g *schemaGenerator) jsonSchema(value any) *Schema {
+	return g.schemaOf(reflect.TypeOf(value), "json")
+}
+
+// formSchema describes value as bound from a form or multipart body.
+func (g *schemaGenerator) formSchema(value any) *Schema {
+	return g.schemaOf(reflect.TypeOf(value), "form")
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type, tag string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem(), tag)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem(), tag)}
+	case reflect.Struct:
+		if t.Name() == "" || tag != "json" {
+			return g.structSchema(t, tag)
+		}
+		return g.component(t)
+	}
+
+	// Interfaces, such as the data of an envelope, accept any value.
+	return &Schema{}
+}
+
+func (g *schemaGenerator) component(t reflect.Type) *Schema {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	if _, ok := g.schemas[name]; !ok {
+		// Reserve the name first so that recursive types terminate.
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(t, "json")
+	}
+	return &Schema{Ref: componentPrefix + name}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type, tag string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t, tag)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type, tag string) {
+	for field := range fields(t, tag) {
+		if field.embedded {
+			g.addFields(schema, field.Type, tag)
+			continue
+		}
+
+		property := g.schemaOf(field.Type, tag)
+		if applyRules(property, field.rules) {
+			schema.Required = append(schema.Required, field.name)
+		}
+		schema.Properties[field.name] = property
+	}
+}
+
+// field is a struct field as seen by an encoder or binder.
+type field struct {
+	reflect.StructField
+	name     string
+	rules    string
+	embedded bool
+}
+
+// fields yields the fields of t that tag names. JSON skips file uploads,
+// which only a form can carry.
+func fields(t reflect.Type, tag string) func(yield func(field) bool) {
+	return func(yield func(field) bool) {
+		for i := range t.NumField() {
+			structField := t.Field(i)
+			name, _, _ := strings.Cut(structField.Tag.Get(tag), ",")
+			if !structField.IsExported() || name == "-" {
+				continue
+			}
+
+			fieldType := structField.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if tag == "json" && fieldType == fileHeaderType {
+				continue
+			}
+
+			f := field{StructField: structField, name: name, rules: structField.Tag.Get("binding")}
+			if name == "" {
+				if structField.Anonymous && fieldType.Kind() == reflect.Struct {
+					f.StructField.Type = fieldType
+					f.embedded = true
+				}
+				f.name = structField.Name
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+}
+
+// applyRules turns validator rules into schema keywords and reports whether
+// the field is required.
+func applyRules(schema *Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		number, numberErr := strconv.ParseFloat(param, 64)
+		isString := schema.Type == "string"
+
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "max", "len":
+			if numberErr != nil {
+				continue
+			}
+			if isString {
+				length := int64(number)
+				if name != "max" {
+					schema.MinLength = &length
+				}
+				if name != "min" {
+					schema.MaxLength = &length
+				}
+				continue
+			}
+			if name != "max" {
+				schema.Minimum = &number
+			}
+			if name != "min" {
+				schema.Maximum = &number
+			}
+		case "gte":
+			if numberErr == nil {
+				schema.Minimum = &number
+			}
+		case "lte":
+			if numberErr == nil {
+				schema.Maximum = &number
+			}
+		case "gt":
+			if numberErr == nil {
+				schema.ExclusiveMinimum = &number
+			}
+		case "lt":
+			if numberErr == nil {
+				schema.ExclusiveMaximum = &number
+			}
+		}
+	}
+	return required
+}
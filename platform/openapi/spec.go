@@ -0,0 +1,307 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	BearerAuth = "bearerAuth"
+
+	contentJSON      = "application/json"
+	contentMultipart = "multipart/form-data"
+)
+
+type (
+	// Operation documents a route next to its registration. Body and Query
+	// are the types the handler binds; Data and Meta are what it puts in the
+	// response.Response envelope.
+	Operation struct {
+		ID          string
+		Summary     string
+		Description string
+		Tags        []string
+		Deprecated  bool
+		// Secured operations require a bearer access token.
+		Secured bool
+		Query   any
+		Body    any
+		// BodyContentType is set for handlers that read the raw body instead
+		// of binding Body.
+		BodyContentType string
+		Headers         []HeaderParameter
+		// Status is the success status, 200 when unset.
+		Status int
+		// Envelope answers with response.Response even when Data is nil.
+		Envelope bool
+		Data     any
+		Meta     any
+		// ContentType is set for handlers that answer with something other
+		// than the envelope, such as */* for a file download.
+		ContentType     string
+		ResponseHeaders []HeaderParameter
+	}
+
+	HeaderParameter struct {
+		Name        string
+		Description string
+		Required    bool
+		Type        string
+	}
+
+	// Spec collects the operations documented for the routes of an engine.
+	Spec struct {
+		info       Info
+		mu         sync.Mutex
+		operations map[string]Operation
+	}
+)
+
+var pathParameter = regexp.MustCompile(`[:*]([^/]+)`)
+
+func NewSpec(info Info) *Spec {
+	return &Spec{
+		info:       info,
+		operations: make(map[string]Operation),
+	}
+}
+
+// Document describes the route registered on group at relativePath.
+func (s *Spec) Document(group *gin.RouterGroup, method string, relativePath string, operation Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operations[routeKey(method, joinPaths(group.BasePath(), relativePath))] = operation
+}
+
+// Build generates the document of routes, which must match the documented
+// operations one to one.
+func (s *Spec) Build(routes gin.RoutesInfo) (*Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registered := make(map[string]bool, len(routes))
+	var undocumented []string
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		registered[key] = true
+		if _, ok := s.operations[key]; !ok {
+			undocumented = append(undocumented, key)
+		}
+	}
+	for key := range s.operations {
+		if !registered[key] {
+			undocumented = append(undocumented, key+" (not registered)")
+		}
+	}
+	if len(undocumented) > 0 {
+		slices.Sort(undocumented)
+		return nil, fmt.Errorf("routes without documentation: %s", strings.Join(undocumented, ", "))
+	}
+
+	generator := newSchemaGenerator()
+	document := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	for _, route := range routes {
+		openAPIPath := pathParameter.ReplaceAllString(route.Path, "{$1}")
+		item, ok := document.Paths[openAPIPath]
+		if !ok {
+			item = make(PathItem)
+			document.Paths[openAPIPath] = item
+		}
+		item[strings.ToLower(route.Method)] = generator.operation(route.Path, s.operations[routeKey(route.Method, route.Path)])
+	}
+	document.Components.Schemas = generator.schemas
+
+	return document, nil
+}
+
+func (s *Spec) MustBuild(routes gin.RoutesInfo) *Document {
+	document, err := s.Build(routes)
+	if err != nil {
+		panic(err)
+	}
+	return document
+}
+
+func (g *schemaGenerator) operation(ginPath string, operation Operation) *OperationObject {
+	object := &OperationObject{
+		OperationID: operation.ID,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Deprecated:  operation.Deprecated,
+		Responses:   make(map[string]Response),
+	}
+
+	for _, match := range pathParameter.FindAllStringSubmatch(ginPath, -1) {
+		object.Parameters = append(object.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	for _, header := range operation.Headers {
+		object.Parameters = append(object.Parameters, Parameter{
+			Name:        header.Name,
+			In:          "header",
+			Description: header.Description,
+			Required:    header.Required,
+			Schema:      headerSchema(header),
+		})
+	}
+	if operation.Query != nil {
+		query := g.formSchema(operation.Query)
+		for _, name := range sortedKeys(query.Properties) {
+			object.Parameters = append(object.Parameters, Parameter{
+				Name:     name,
+				In:       "query",
+				Required: slices.Contains(query.Required, name),
+				Schema:   query.Properties[name],
+			})
+		}
+	}
+
+	switch {
+	case operation.Body != nil:
+		object.RequestBody = g.requestBody(operation.Body)
+	case operation.BodyContentType != "":
+		object.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{operation.BodyContentType: {Schema: rawSchema(operation.BodyContentType)}},
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case operation.ContentType != "":
+		success.Content = map[string]MediaType{operation.ContentType: {Schema: rawSchema(operation.ContentType)}}
+	case operation.Envelope || operation.Data != nil:
+		success.Content = map[string]MediaType{contentJSON: {Schema: g.envelope(operation)}}
+	}
+	for _, header := range operation.ResponseHeaders {
+		if success.Headers == nil {
+			success.Headers = make(map[string]Header)
+		}
+		success.Headers[header.Name] = Header{Description: header.Description, Schema: headerSchema(header)}
+	}
+	object.Responses[strconv.Itoa(status)] = success
+
+	problem := Response{
+		Description: "Problem details",
+		Content: map[string]MediaType{
+			response.ProblemContentType: {Schema: g.jsonSchema(response.Problem{})},
+		},
+	}
+	if operation.Body != nil || operation.Query != nil {
+		object.Responses[strconv.Itoa(http.StatusBadRequest)] = problem
+	}
+	if operation.Secured {
+		object.Security = []map[string][]string{{BearerAuth: {}}}
+		object.Responses[strconv.Itoa(http.StatusUnauthorized)] = problem
+	}
+	object.Responses["default"] = problem
+
+	return object
+}
+
+// requestBody offers JSON for every body, and multipart forms for bodies
+// that carry a file.
+func (g *schemaGenerator) requestBody(body any) *RequestBody {
+	requestBody := &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{contentJSON: {Schema: g.jsonSchema(body)}},
+	}
+
+	form := g.formSchema(body)
+	for _, property := range form.Properties {
+		if property.Format == "binary" {
+			requestBody.Content[contentMultipart] = MediaType{Schema: form}
+			break
+		}
+	}
+	return requestBody
+}
+
+// envelope describes response.Response with the operation's data and meta.
+func (g *schemaGenerator) envelope(operation Operation) *Schema {
+	schema := g.jsonSchema(response.Response{})
+	if operation.Data == nil && operation.Meta == nil {
+		return schema
+	}
+
+	content := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if operation.Data != nil {
+		content.Properties["data"] = g.schemaOf(reflect.TypeOf(operation.Data), "json")
+	}
+	if operation.Meta != nil {
+		content.Properties["meta"] = g.schemaOf(reflect.TypeOf(operation.Meta), "json")
+	}
+	return &Schema{AllOf: []*Schema{schema, content}}
+}
+
+func rawSchema(contentType string) *Schema {
+	switch contentType {
+	case contentJSON:
+		return &Schema{Type: "object"}
+	case "*/*", "application/octet-stream", "application/offset+octet-stream":
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	return &Schema{Type: "string"}
+}
+
+func headerSchema(header HeaderParameter) *Schema {
+	if header.Type == "" {
+		return &Schema{Type: "string"}
+	}
+	return &Schema{Type: header.Type}
+}
+
+func routeKey(method string, routePath string) string {
+	return method + " " + routePath
+}
+
+// joinPaths joins paths the way gin does, keeping a trailing slash.
+func joinPaths(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	joined := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+)
+
+var ErrorCursorInvalid = shared.NewError(shared.CategoryValidation, "cursor_invalid", "cursor is invalid")
+
+// Cursor points between two rows of a keyset page: Key is the sort key and
+// ID the primary key of the row it follows or, when Backward is set,
+// precedes.
+type Cursor struct {
+	Key      json.RawMessage `json:"k"`
+	ID       string          `json:"id"`
+	Backward bool            `json:"b,omitempty"`
+}
+
+// CursorCodec turns cursors into opaque strings signed with HMAC-SHA256, so
+// clients cannot forge a position.
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+func GetCursorKey() []byte {
+	secretKey := os.Getenv("PAGINATION_CURSOR_KEY")
+	if secretKey == "" {
+		secretKey = "kpl-base-cursor-secret"
+	}
+	return []byte(secretKey)
+}
+
+func (c *CursorCodec) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+func (c *CursorCodec) Decode(value string) (Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(value, ".")
+	if !ok {
+		return Cursor{}, ErrorCursorInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Cursor{}, ErrorCursorInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return Cursor{}, ErrorCursorInvalid
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" {
+		return Cursor{}, ErrorCursorInvalid
+	}
+	return cursor, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
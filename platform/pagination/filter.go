@@ -0,0 +1,215 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrorSortField      = shared.NewError(shared.CategoryValidation, "sort_field_invalid", "cannot sort by this field")
+	ErrorSortCursor     = shared.NewError(shared.CategoryValidation, "sort_cursor", "sort cannot be combined with a cursor")
+	ErrorFilterField    = shared.NewError(shared.CategoryValidation, "filter_field_invalid", "cannot filter by this field")
+	ErrorFilterOperator = shared.NewError(shared.CategoryValidation, "filter_operator_invalid", "filter operator is not supported for this field")
+	ErrorFilterValue    = shared.NewError(shared.CategoryValidation, "filter_value_invalid", "filter value does not match the type of the field")
+)
+
+// FieldType is the type filter values of a field are parsed as.
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+	FieldFloat
+	FieldBool
+	FieldTime
+)
+
+type Operator string
+
+const (
+	OperatorEqual          Operator = "eq"
+	OperatorNotEqual       Operator = "ne"
+	OperatorGreater        Operator = "gt"
+	OperatorGreaterOrEqual Operator = "gte"
+	OperatorLess           Operator = "lt"
+	OperatorLessOrEqual    Operator = "lte"
+	// OperatorIn matches any of a comma separated list of values.
+	OperatorIn Operator = "in"
+)
+
+var operatorSQL = map[Operator]string{
+	OperatorEqual:          "=",
+	OperatorNotEqual:       "<>",
+	OperatorGreater:        ">",
+	OperatorGreaterOrEqual: ">=",
+	OperatorLess:           "<",
+	OperatorLessOrEqual:    "<=",
+	OperatorIn:             "IN",
+}
+
+type (
+	// Filter is a filter[field]=value or filter[field][operator]=value query
+	// parameter as the client sent it.
+	Filter struct {
+		Field    string
+		Operator Operator
+		Value    string
+	}
+
+	// Field is a column a list endpoint lets clients sort or filter by. Value
+	// reads it from an entity, as a string, int64, float64, bool or
+	// time.Time according to Type, for repositories that filter in memory.
+	Field[E any] struct {
+		Column string
+		Type   FieldType
+		Sort   bool
+		Filter bool
+		Value  func(E) any
+	}
+
+	// Fields whitelists the fields of a list endpoint by the name clients use.
+	Fields[E any] map[string]Field[E]
+
+	Order[E any] struct {
+		Field[E]
+		Descending bool
+	}
+
+	// Condition is a filter checked against the whitelist, with its values
+	// parsed to the type of the field.
+	Condition[E any] struct {
+		Field[E]
+		Operator Operator
+		Values   []any
+	}
+
+	Query[E any] struct {
+		Orders     []Order[E]
+		Conditions []Condition[E]
+	}
+)
+
+// ParseFilters collects the filter parameters of a query string. Malformed
+// names are kept as they are, so the whitelist rejects them.
+func ParseFilters(query url.Values) []Filter {
+	var filters []Filter
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSuffix(name, "]")
+
+		operator := OperatorEqual
+		if parts := strings.Split(name, "]["); len(parts) == 2 {
+			name, operator = parts[0], Operator(parts[1])
+		}
+		for _, value := range values {
+			filters = append(filters, Filter{Field: name, Operator: operator, Value: value})
+		}
+	}
+
+	slices.SortFunc(filters, func(a, b Filter) int {
+		return strings.Compare(a.Field+"|"+string(a.Operator), b.Field+"|"+string(b.Operator))
+	})
+	return filters
+}
+
+// Parse checks the sort and filters of req against the whitelist.
+func (f Fields[E]) Parse(req Request) (Query[E], error) {
+	var query Query[E]
+
+	for _, name := range strings.Split(req.Sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		descending := strings.HasPrefix(name, "-")
+		name = strings.TrimLeft(name, "+-")
+
+		field, ok := f[name]
+		if !ok || !field.Sort {
+			return Query[E]{}, fmt.Errorf("%w: %s", ErrorSortField, name)
+		}
+		query.Orders = append(query.Orders, Order[E]{Field: field, Descending: descending})
+	}
+	if len(query.Orders) > 0 && req.IsKeyset() {
+		return Query[E]{}, ErrorSortCursor
+	}
+
+	for _, filter := range req.Filters {
+		field, ok := f[filter.Field]
+		if !ok || !field.Filter {
+			return Query[E]{}, fmt.Errorf("%w: %s", ErrorFilterField, filter.Field)
+		}
+		if _, ok = operatorSQL[filter.Operator]; !ok || field.Type == FieldBool && filter.Operator != OperatorEqual && filter.Operator != OperatorNotEqual {
+			return Query[E]{}, fmt.Errorf("%w: %s[%s]", ErrorFilterOperator, filter.Field, filter.Operator)
+		}
+
+		raw := []string{filter.Value}
+		if filter.Operator == OperatorIn {
+			raw = strings.Split(filter.Value, ",")
+		}
+		values := make([]any, len(raw))
+		for i, value := range raw {
+			parsed, err := field.Type.parse(strings.TrimSpace(value))
+			if err != nil {
+				return Query[E]{}, fmt.Errorf("%w: %s=%q", ErrorFilterValue, filter.Field, value)
+			}
+			values[i] = parsed
+		}
+		query.Conditions = append(query.Conditions, Condition[E]{Field: field, Operator: filter.Operator, Values: values})
+	}
+
+	return query, nil
+}
+
+// Scope applies the query to a GORM statement. Columns only ever come from the
+// whitelist and values are bound, and sorted pages fall back to the primary
+// key so rows with equal values keep their order across pages.
+func (q Query[E]) Scope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range q.Conditions {
+			column := clause.Column{Name: condition.Column}
+			if condition.Operator == OperatorIn {
+				db = db.Where("? IN ?", column, condition.Values)
+				continue
+			}
+			db = db.Where("? "+operatorSQL[condition.Operator]+" ?", column, condition.Values[0])
+		}
+
+		for _, order := range q.Orders {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Column}, Desc: order.Descending})
+		}
+		if len(q.Orders) > 0 {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+		}
+		return db
+	}
+}
+
+func (t FieldType) parse(value string) (any, error) {
+	switch t {
+	case FieldInt:
+		return strconv.ParseInt(value, 10, 64)
+	case FieldFloat:
+		return strconv.ParseFloat(value, 64)
+	case FieldBool:
+		return strconv.ParseBool(value)
+	case FieldTime:
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		return time.Parse(time.DateOnly, value)
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,31 @@
+package pagination
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Highlight wraps every occurrence of term in text, ignoring case, in <mark>
+// tags and escapes the rest as HTML. It reports whether term occurred at all.
+func Highlight(text, term string) (string, bool) {
+	if term == "" {
+		return "", false
+	}
+
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	matches := pattern.FindAllStringIndex(text, -1)
+	if matches == nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String(), true
+}
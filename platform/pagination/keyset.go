@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Keyset orders rows by Column, then by ID to break ties, so a cursor holding
+// both picks up exactly where the previous page ended even while rows are
+// inserted.
+type Keyset[E any, K any] struct {
+	Column string
+	Key    func(E) K
+	ID     func(E) string
+}
+
+// Decode returns the cursor of the request and its sort key, or nil for the
+// first page.
+func (k Keyset[E, K]) Decode(codec *CursorCodec, req Request) (*Cursor, K, error) {
+	var key K
+	if req.Cursor == nil || *req.Cursor == "" {
+		return nil, key, nil
+	}
+
+	cursor, err := codec.Decode(*req.Cursor)
+	if err != nil {
+		return nil, key, err
+	}
+	if err = json.Unmarshal(cursor.Key, &key); err != nil {
+		return nil, key, ErrorCursorInvalid
+	}
+	return &cursor, key, nil
+}
+
+// Page turns the rows fetched after (or, going backward, before) cursor,
+// in the direction of travel and at most one more than PerPage, into the page
+// in ascending order with its next and prev cursors.
+func (k Keyset[E, K]) Page(codec *CursorCodec, req Request, cursor *Cursor, rows []E) ([]E, Response, error) {
+	backward := cursor != nil && cursor.Backward
+	more := len(rows) > req.PerPage
+	if more {
+		rows = rows[:req.PerPage]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	meta := Response{PerPage: req.PerPage}
+	if len(rows) == 0 {
+		return rows, meta, nil
+	}
+
+	var err error
+	if more || backward {
+		if meta.Next, err = k.encode(codec, rows[len(rows)-1], false); err != nil {
+			return nil, Response{}, err
+		}
+	}
+	if backward && more || !backward && cursor != nil {
+		if meta.Prev, err = k.encode(codec, rows[0], true); err != nil {
+			return nil, Response{}, err
+		}
+	}
+	return rows, meta, nil
+}
+
+func (k Keyset[E, K]) encode(codec *CursorCodec, row E, backward bool) (string, error) {
+	key, err := json.Marshal(k.Key(row))
+	if err != nil {
+		return "", err
+	}
+	return codec.Encode(Cursor{Key: key, ID: k.ID(row), Backward: backward})
+}
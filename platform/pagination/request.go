@@ -1,10 +1,19 @@
 package pagination
 
 type (
+	// Request selects a page either by number or, when Cursor is present, by
+	// keyset: an empty cursor asks for the first page and the next and prev
+	// cursors of a response for the pages around it. Sort is a comma
+	// separated list of fields, each descending when prefixed with a minus,
+	// and Filters are read from the query string by ParseFilters.
 	Request struct {
-		Search  string `form:"search"`
-		Page    int    `form:"page"`
-		PerPage int    `form:"per_page"`
+		Search    string   `form:"search"`
+		Page      int      `form:"page"`
+		PerPage   int      `form:"per_page"`
+		Cursor    *string  `form:"cursor"`
+		SkipCount bool     `form:"skip_count"`
+		Sort      string   `form:"sort"`
+		Filters   []Filter `form:"-"`
 	}
 )
 
@@ -20,8 +29,13 @@ func (p *Request) GetPage() int {
 	return p.Page
 }
 
+// IsKeyset reports whether the page is selected by cursor.
+func (p *Request) IsKeyset() bool {
+	return p.Cursor != nil
+}
+
 func (p *Request) Default() {
-	if p.Page == 0 {
+	if p.Page == 0 && !p.IsKeyset() {
 		p.Page = 1
 	}
 
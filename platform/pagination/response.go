@@ -1,11 +1,16 @@
 package pagination
 
 type (
+	// Response is the meta of a page. Page and MaxPage are only set for pages
+	// selected by number, Next and Prev only for pages selected by cursor, and
+	// Count unless the request skipped it.
 	Response struct {
-		Page    int   `json:"page"`
-		PerPage int   `json:"per_page"`
-		MaxPage int64 `json:"max_page"`
-		Count   int64 `json:"count"`
+		Page    int    `json:"page,omitempty"`
+		PerPage int    `json:"per_page"`
+		MaxPage int64  `json:"max_page,omitempty"`
+		Count   *int64 `json:"count,omitempty"`
+		Next    string `json:"next,omitempty"`
+		Prev    string `json:"prev,omitempty"`
 	}
 
 	ResponseWithData struct {
@@ -13,3 +18,13 @@ type (
 		Response
 	}
 )
+
+// Counted returns the meta of a page selected by number out of count rows.
+func Counted(req Request, count int64) Response {
+	return Response{
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		MaxPage: TotalPage(count, int64(req.PerPage)),
+		Count:   &count,
+	}
+}
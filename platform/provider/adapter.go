@@ -1,13 +1,67 @@
 package provider
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/clock"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/file_scanner"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/file_storage"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/id_generator"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/mailer"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/url_signer"
 	"github.com/samber/do/v2"
 )
 
 func RegisterAdapterDependencies(injector do.Injector) {
 	do.Provide(injector, func(injector do.Injector) (port.FileStoragePort, error) {
-		return file_storage.NewLocalAdapter(), nil
+		return newFileStorageAdapter(injector)
+	})
+	do.Provide(injector, func(injector do.Injector) (port.FileScannerPort, error) {
+		return newFileScannerAdapter()
+	})
+	do.Provide(injector, func(injector do.Injector) (port.MailerPort, error) {
+		return mailer.NewLogAdapter(), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (port.URLSignerPort, error) {
+		clock := do.MustInvoke[port.ClockPort](injector)
+		return url_signer.NewHMACAdapter(url_signer.GetSigningKey(), clock), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (port.ClockPort, error) {
+		return clock.NewSystemAdapter(), nil
 	})
+	do.Provide(injector, func(injector do.Injector) (port.IDGeneratorPort, error) {
+		return id_generator.NewUUIDAdapter(), nil
+	})
+}
+
+func newFileStorageAdapter(injector do.Injector) (port.FileStoragePort, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", file_storage.DriverLocal:
+		signer := do.MustInvoke[port.URLSignerPort](injector)
+		clock := do.MustInvoke[port.ClockPort](injector)
+		return file_storage.NewLocalAdapter(file_storage.LoadLocalConfig(), signer, clock), nil
+	case file_storage.DriverS3:
+		config, err := file_storage.LoadS3Config()
+		if err != nil {
+			return nil, err
+		}
+		signer := do.MustInvoke[port.URLSignerPort](injector)
+		clock := do.MustInvoke[port.ClockPort](injector)
+		return file_storage.NewS3Adapter(config, signer, clock)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", driver)
+	}
+}
+
+func newFileScannerAdapter() (port.FileScannerPort, error) {
+	switch driver := os.Getenv("SCANNER_DRIVER"); driver {
+	case "", file_scanner.DriverNone:
+		return file_scanner.NewNoneAdapter(), nil
+	case file_scanner.DriverClamAV:
+		return file_scanner.NewClamAVAdapter(file_scanner.LoadClamAVConfig())
+	default:
+		return nil, fmt.Errorf("unsupported scanner driver: %s", driver)
+	}
 }
@@ -0,0 +1,61 @@
+package file
+
+import (
+	"os"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+// Module is the file bounded context: stored files and resumable uploads.
+type Module struct{}
+
+func init() {
+	module.Register(Module{})
+}
+
+func (Module) Name() string {
+	return "file"
+}
+
+func (Module) Routes(injector do.Injector, api version.API) {
+	file.Route(injector, api)
+}
+
+func (Module) Tables() []any {
+	return []any{&table.File{}, &table.Upload{}}
+}
+
+func (Module) Migrations() []module.Migration {
+	return nil
+}
+
+func (Module) Seed(*gorm.DB) error {
+	return nil
+}
+
+// Jobs removes resumable uploads that expired before they were completed and
+// files nothing refers to anymore.
+func (Module) Jobs(injector do.Injector) []module.Job {
+	uploadService := do.MustInvoke[service.UploadService](injector)
+	fileService := do.MustInvoke[service.FileService](injector)
+
+	return []module.Job{
+		{Name: "expired uploads", Interval: cleanupInterval("UPLOAD_CLEANUP_INTERVAL"), Run: uploadService.DeleteExpired},
+		{Name: "unreferenced files", Interval: cleanupInterval("FILE_CLEANUP_INTERVAL"), Run: fileService.DeleteUnreferenced},
+	}
+}
+
+func cleanupInterval(key string) time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+	return interval
+}
@@ -0,0 +1,33 @@
+package file
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/repository"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller"
+	"github.com/samber/do/v2"
+)
+
+func (Module) Providers(injector do.Injector) {
+	do.Provide(injector, func(injector do.Injector) (file.Repository, error) {
+		return repository.NewFileRepository(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (file.UploadRepository, error) {
+		return repository.NewUploadRepository(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (*file.Service, error) {
+		return file.NewService(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (service.FileService, error) {
+		return service.NewFileService(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (service.UploadService, error) {
+		return service.NewUploadService(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (controller.FileController, error) {
+		return controller.NewFileController(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (controller.UploadController, error) {
+		return controller.NewUploadController(injector), nil
+	})
+}
@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"os"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+// Module stores the Idempotency-Key of unsafe requests along with their
+// responses. It has no routes of its own; middleware.Idempotency serves every
+// route.
+type Module struct{}
+
+func init() {
+	module.Register(Module{})
+}
+
+func (Module) Name() string {
+	return "idempotency"
+}
+
+func (Module) Routes(do.Injector, version.API) {}
+
+func (Module) Tables() []any {
+	return []any{&table.IdempotencyKey{}}
+}
+
+func (Module) Migrations() []module.Migration {
+	return nil
+}
+
+func (Module) Seed(*gorm.DB) error {
+	return nil
+}
+
+// Jobs removes the keys past their TTL.
+func (Module) Jobs(injector do.Injector) []module.Job {
+	idempotencyService := do.MustInvoke[service.IdempotencyService](injector)
+
+	return []module.Job{
+		{Name: "expired idempotency keys", Interval: cleanupInterval(), Run: idempotencyService.DeleteExpired},
+	}
+}
+
+func cleanupInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_CLEANUP_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+	return interval
+}
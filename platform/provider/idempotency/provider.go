@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/repository"
+	"github.com/samber/do/v2"
+)
+
+func (Module) Providers(injector do.Injector) {
+	do.Provide(injector, func(injector do.Injector) (idempotency.Repository, error) {
+		return repository.NewIdempotencyRepository(injector), nil
+	})
+	do.Provide(injector, func(injector do.Injector) (service.IdempotencyService, error) {
+		return service.NewIdempotencyService(injector), nil
+	})
+}
@@ -0,0 +1,9 @@
+package provider
+
+// The feature modules register themselves with the module registry when
+// their package is imported.
+import (
+	_ "github.com/fawwasaldy/gin-clean-architecture/platform/provider/file"
+	_ "github.com/fawwasaldy/gin-clean-architecture/platform/provider/idempotency"
+	_ "github.com/fawwasaldy/gin-clean-architecture/platform/provider/user"
+)
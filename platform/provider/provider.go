@@ -1,12 +1,13 @@
 package provider
 
 import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
-	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/repository"
 	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
-	"github.com/fawwasaldy/gin-clean-architecture/platform/provider/user"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
 	"github.com/samber/do/v2"
 	"gorm.io/gorm"
 )
@@ -14,28 +15,26 @@ import (
 func RegisterDependencies(injector do.Injector) {
 	InitDatabase(injector)
 	InitJWTService(injector)
-	InitRefreshTokenRepository(injector)
 	InitTransactionRepository(injector)
+	InitUnitOfWork(injector)
+	InitCursorCodec(injector)
 
 	RegisterAdapterDependencies(injector)
-	user.RegisterDependencies(injector)
+	for _, m := range module.Modules() {
+		m.Providers(injector)
+	}
 }
 
 func InitDatabase(injector do.Injector) {
 	do.Provide(injector, func(injector do.Injector) (*gorm.DB, error) {
-		return config.SetUpDatabaseConnection(), nil
+		clock := do.MustInvoke[port.ClockPort](injector)
+		return config.SetUpDatabaseConnection(clock), nil
 	})
 }
 
 func InitJWTService(injector do.Injector) {
 	do.Provide(injector, func(injector do.Injector) (service.JWTService, error) {
-		return service.NewJWTService(), nil
-	})
-}
-
-func InitRefreshTokenRepository(injector do.Injector) {
-	do.Provide(injector, func(injector do.Injector) (refresh_token.Repository, error) {
-		return repository.NewRefreshTokenRepository(injector), nil
+		return service.NewJWTService(injector), nil
 	})
 }
 
@@ -44,3 +43,15 @@ func InitTransactionRepository(injector do.Injector) {
 		return transaction.NewRepository(injector), nil
 	})
 }
+
+func InitUnitOfWork(injector do.Injector) {
+	do.Provide(injector, func(injector do.Injector) (application.UnitOfWork, error) {
+		return transaction.NewUnitOfWork(injector), nil
+	})
+}
+
+func InitCursorCodec(injector do.Injector) {
+	do.Provide(injector, func(injector do.Injector) (*pagination.CursorCodec, error) {
+		return pagination.NewCursorCodec(pagination.GetCursorKey()), nil
+	})
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration/seed"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/version"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+// Module is the user bounded context: accounts, authentication and avatars.
+type Module struct{}
+
+func init() {
+	module.Register(Module{})
+}
+
+func (Module) Name() string {
+	return "user"
+}
+
+func (Module) Routes(injector do.Injector, api version.API) {
+	user.Route(injector, api)
+}
+
+func (Module) Tables() []any {
+	return []any{&table.User{}, &table.RefreshToken{}}
+}
+
+// Migrations index the searched columns, and drop the unique indexes that
+// included deleted_at, which never matched live rows because NULLs compare as
+// distinct.
+func (Module) Migrations() []module.Migration {
+	return []module.Migration{
+		migration.TrigramIndexes(&table.User{}, "name", "email"),
+		migration.DropIndexes(&table.User{}, "idx_users_email_deleted_at"),
+		migration.DropIndexes(&table.RefreshToken{}, "idx_refresh_tokens_token_deleted_at"),
+	}
+}
+
+func (Module) Seed(db *gorm.DB) error {
+	return seed.User(db)
+}
+
+func (Module) Jobs(do.Injector) []module.Job {
+	return nil
+}
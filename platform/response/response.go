@@ -4,6 +4,7 @@ type Response struct {
 	Status  bool   `json:"status"`
 	Message string `json:"message"`
 	Error   any    `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
 	Data    any    `json:"data,omitempty"`
 	Meta    any    `json:"meta,omitempty"`
 }
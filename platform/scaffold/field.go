@@ -0,0 +1,196 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldType is a type a generated field may have.
+type FieldType string
+
+const (
+	TypeString FieldType = "string"
+	TypeText   FieldType = "text"
+	TypeInt    FieldType = "int"
+	TypeFloat  FieldType = "float"
+	TypeBool   FieldType = "bool"
+	TypeTime   FieldType = "time"
+)
+
+var (
+	ErrorFieldInvalid  = errors.New("field must be written as name:type")
+	ErrorFieldType     = errors.New("unsupported field type, use string, text, int, float, bool or time")
+	ErrorFieldReserved = errors.New("field is added to every module")
+	ErrorFieldRepeated = errors.New("field is listed twice")
+)
+
+var reservedFields = map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true, "timestamp": true}
+
+type Field struct {
+	Name string
+	Type FieldType
+}
+
+// ParseFields parses a comma separated list of name:type pairs, such as
+// "title:string,price:float".
+func ParseFields(spec string) ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, typ, ok := strings.Cut(pair, ":")
+		if !ok || !validName(name) {
+			return nil, fmt.Errorf("%w: %q", ErrorFieldInvalid, pair)
+		}
+		field := Field{Name: name, Type: FieldType(typ)}
+		if field.GoType() == "" {
+			return nil, fmt.Errorf("%w: %q", ErrorFieldType, pair)
+		}
+		if reservedFields[name] {
+			return nil, fmt.Errorf("%w: %q", ErrorFieldReserved, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %q", ErrorFieldRepeated, name)
+		}
+		seen[name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func (f Field) GoName() string {
+	return camel(f.Name)
+}
+
+func (f Field) GoType() string {
+	switch f.Type {
+	case TypeString, TypeText:
+		return "string"
+	case TypeInt:
+		return "int64"
+	case TypeFloat:
+		return "float64"
+	case TypeBool:
+		return "bool"
+	case TypeTime:
+		return "time.Time"
+	default:
+		return ""
+	}
+}
+
+// Column is the gorm tag of the field's column.
+func (f Field) Column() string {
+	switch f.Type {
+	case TypeString:
+		return "type:varchar(255);not null;column:" + f.Name
+	case TypeText:
+		return "type:text;not null;column:" + f.Name
+	case TypeBool:
+		return "not null;default:false;column:" + f.Name
+	default:
+		return "not null;column:" + f.Name
+	}
+}
+
+// CreateBinding is the binding tag of the field when creating a record. Only
+// strings and times can be required, as zero is a valid number or boolean.
+func (f Field) CreateBinding() string {
+	switch f.Type {
+	case TypeString:
+		return "required,max=255"
+	case TypeText, TypeTime:
+		return "required"
+	default:
+		return ""
+	}
+}
+
+// UpdateBinding is the binding tag of the field when updating a record, where
+// every field is optional.
+func (f Field) UpdateBinding() string {
+	if f.Type == TypeString {
+		return "omitempty,max=255"
+	}
+	return ""
+}
+
+func (f Field) Searchable() bool {
+	return f.Type == TypeString || f.Type == TypeText
+}
+
+// Filterable reports whether the list endpoint may filter by the field. Long
+// text is left to search.
+func (f Field) Filterable() bool {
+	return f.Type != TypeText
+}
+
+// Sortable reports whether the list endpoint may sort by the field.
+func (f Field) Sortable() bool {
+	return f.Type != TypeText && f.Type != TypeBool
+}
+
+// FilterType is the pagination.FieldType the field's filter values are
+// parsed as.
+func (f Field) FilterType() string {
+	switch f.Type {
+	case TypeInt:
+		return "pagination.FieldInt"
+	case TypeFloat:
+		return "pagination.FieldFloat"
+	case TypeBool:
+		return "pagination.FieldBool"
+	case TypeTime:
+		return "pagination.FieldTime"
+	default:
+		return "pagination.FieldString"
+	}
+}
+
+// NonZero is the Go condition that expr, a value of the field, is not the zero
+// value GORM's Updates skips.
+func (f Field) NonZero(expr string) string {
+	switch f.Type {
+	case TypeInt, TypeFloat:
+		return expr + " != 0"
+	case TypeBool:
+		return expr
+	case TypeTime:
+		return "!" + expr + ".IsZero()"
+	default:
+		return expr + ` != ""`
+	}
+}
+
+// SampleValue is a Go literal of the field the generated tests create records
+// with.
+func (f Field) SampleValue() string {
+	return f.sample(1)
+}
+
+// UpdatedValue is a Go literal of the field, other than SampleValue, the
+// generated tests update records with. Booleans stay true, as Updates skips
+// false.
+func (f Field) UpdatedValue() string {
+	return f.sample(2)
+}
+
+func (f Field) sample(n int) string {
+	switch f.Type {
+	case TypeInt:
+		return fmt.Sprint(n)
+	case TypeFloat:
+		return fmt.Sprintf("%d.5", n)
+	case TypeBool:
+		return "true"
+	case TypeTime:
+		return fmt.Sprintf("time.Date(2025, time.January, %d, 0, 0, 0, 0, time.UTC)", n)
+	default:
+		return fmt.Sprintf("%q", fmt.Sprintf("%s %d", words(f.Name), n))
+	}
+}
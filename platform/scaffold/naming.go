@@ -0,0 +1,56 @@
+package scaffold
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// camel turns a snake_case name into CamelCase, spelling id as ID the way the
+// rest of the code does.
+func camel(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func lowerCamel(name string) string {
+	c := camel(name)
+	if strings.HasPrefix(c, "ID") {
+		return "id" + c[2:]
+	}
+	return strings.ToLower(c[:1]) + c[1:]
+}
+
+// plural naively pluralizes the last word of a snake_case name.
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
+
+func words(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+func kebab(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+func validName(name string) bool {
+	return identifier.MatchString(name) && !token.IsKeyword(name)
+}
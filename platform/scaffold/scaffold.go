@@ -0,0 +1,263 @@
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var (
+	ErrorModuleName   = errors.New("module name must be snake_case and not a Go keyword or a package the module imports")
+	ErrorModuleExists = errors.New("module already exists")
+	ErrorNoGoModule   = errors.New("go.mod not found, run the generator from the repository root")
+)
+
+// reservedModules are the packages the generated files import, which a module
+// of the same name would shadow.
+var reservedModules = map[string]bool{
+	"application": true, "context": true, "controller": true, "do": true, "errors": true,
+	"fake": true, "generic": true, "gin": true, "gorm": true, "harness": true, "http": true,
+	"identity": true, "memory": true, "message": true, "middleware": true, "module": true,
+	"openapi": true, "pagination": true, "port": true, "repository": true, "request": true,
+	"response": true, "service": true, "shared": true, "strings": true, "sync": true,
+	"table": true, "testing": true, "tests": true, "time": true, "transaction": true, "uuid": true,
+	"version": true,
+}
+
+// Module describes the bounded context to generate.
+type Module struct {
+	// Path is the Go module path the generated imports start with.
+	Path   string
+	Name   string
+	Fields []Field
+}
+
+// outputs maps every template to the file it renders, relative to the
+// repository root. %s is the module name.
+var outputs = map[string]string{
+	"entity.go.tmpl":            "internal/domain/%s/entity.go",
+	"error.go.tmpl":             "internal/domain/%s/error.go",
+	"repository.go.tmpl":        "internal/domain/%s/repository.go",
+	"table.go.tmpl":             "internal/infrastructure/database/table/%s.go",
+	"gorm_repository.go.tmpl":   "internal/infrastructure/database/repository/%s.go",
+	"memory_repository.go.tmpl": "internal/infrastructure/database/memory/%s.go",
+	"request.go.tmpl":           "internal/application/request/%s.go",
+	"response.go.tmpl":          "internal/application/response/%s.go",
+	"service.go.tmpl":           "internal/application/service/%s_service.go",
+	"controller.go.tmpl":        "internal/presentation/controller/%s.go",
+	"message.go.tmpl":           "internal/presentation/message/%s.go",
+	"router.go.tmpl":            "internal/presentation/route/%s/router.go",
+	"docs.go.tmpl":              "internal/presentation/route/%s/docs.go",
+	"provider.go.tmpl":          "platform/provider/%s/provider.go",
+	"module.go.tmpl":            "platform/provider/%s/module.go",
+	"service_test.go.tmpl":      "tests/%s_service_test.go",
+	"api_test.go.tmpl":          "tests/%s_api_test.go",
+}
+
+const (
+	modulesPath = "platform/provider/modules.go"
+	localesDir  = "internal/presentation/message/locales"
+)
+
+// NewModule validates the name and reads the Go module path from the go.mod
+// in root.
+func NewModule(root, name string, fields []Field) (Module, error) {
+	if !validName(name) || reservedModules[name] {
+		return Module{}, fmt.Errorf("%w: %q", ErrorModuleName, name)
+	}
+
+	goMod, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return Module{}, ErrorNoGoModule
+	}
+	defer goMod.Close()
+
+	scanner := bufio.NewScanner(goMod)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return Module{Path: strings.TrimSpace(path), Name: name, Fields: fields}, nil
+		}
+	}
+	return Module{}, ErrorNoGoModule
+}
+
+func (m Module) Type() string       { return camel(m.Name) }
+func (m Module) Var() string        { return lowerCamel(m.Name) }
+func (m Module) Words() string      { return words(m.Name) }
+func (m Module) Plural() string     { return plural(m.Name) }
+func (m Module) PluralType() string { return camel(plural(m.Name)) }
+func (m Module) PluralWords() string {
+	return words(plural(m.Name))
+}
+
+// Route is the path the module's routes are grouped under.
+func (m Module) Route() string { return "/" + kebab(plural(m.Name)) }
+
+func (m Module) HasTime() bool {
+	for _, field := range m.Fields {
+		if field.Type == TypeTime {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchColumns lists the text columns the list endpoint searches.
+func (m Module) SearchColumns() string {
+	var columns []string
+	for _, field := range m.Fields {
+		if field.Searchable() {
+			columns = append(columns, fmt.Sprintf("%q", field.Name))
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Message is a message ID of the module with its English text.
+type Message struct {
+	ID   string
+	Text string
+}
+
+// Messages lists the messages of the module in the order of message.go.
+func (m Module) Messages() []Message {
+	one, many := m.Words(), m.PluralWords()
+	return []Message{
+		{"failed_create_" + m.Name, "Failed to create " + one},
+		{"failed_get_all_" + m.Plural(), "Failed to get all " + many},
+		{"failed_get_" + m.Name, "Failed to get " + one},
+		{"failed_update_" + m.Name, "Failed to update " + one},
+		{"failed_delete_" + m.Name, "Failed to delete " + one},
+		{"success_create_" + m.Name, "Successfully created " + one},
+		{"success_get_all_" + m.Plural(), "Successfully retrieved all " + many},
+		{"success_get_" + m.Name, "Successfully retrieved " + one},
+		{"success_update_" + m.Name, "Successfully updated " + one},
+		{"success_delete_" + m.Name, "Successfully deleted " + one},
+	}
+}
+
+// Plan renders every file of the module and patches the files that list
+// modules and messages, returning the new content of each by its path
+// relative to root. Nothing is written, and a module whose files already
+// exist is rejected.
+func Plan(root string, m Module) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(outputs)+3)
+	for name, output := range outputs {
+		path := fmt.Sprintf(output, m.Name)
+		if _, err := os.Stat(filepath.Join(root, path)); err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrorModuleExists, path)
+		}
+
+		content, err := render(name, m)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = content
+	}
+
+	modules, err := os.ReadFile(filepath.Join(root, modulesPath))
+	if err != nil {
+		return nil, err
+	}
+	if files[modulesPath], err = registerModule(modules, m); err != nil {
+		return nil, err
+	}
+
+	locales, err := filepath.Glob(filepath.Join(root, localesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range locales {
+		content, err := os.ReadFile(locale)
+		if err != nil {
+			return nil, err
+		}
+		path, err := filepath.Rel(root, locale)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(path)] = addMessages(content, m.Messages())
+	}
+
+	return files, nil
+}
+
+// Write writes the planned files under root and returns their paths in order.
+func Write(root string, files map[string][]byte) ([]string, error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		target := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, files[path], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+func render(name string, m Module) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return content, nil
+}
+
+// registerModule adds a blank import of the module's provider package to the
+// import block of modules.go.
+func registerModule(content []byte, m Module) ([]byte, error) {
+	end := bytes.LastIndex(content, []byte("\n)"))
+	if end < 0 {
+		return nil, fmt.Errorf("no import block in %s", modulesPath)
+	}
+
+	line := fmt.Sprintf("\n\t_ %q", m.Path+"/platform/provider/"+m.Name)
+	patched := append(append(append([]byte{}, content[:end]...), line...), content[end:]...)
+	return format.Source(patched)
+}
+
+// addMessages appends the messages as a group of their own ahead of the
+// validation messages, or at the end of the catalog. Every catalog gets the
+// English text until it is translated.
+func addMessages(content []byte, messages []Message) []byte {
+	var group strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&group, "  %q: %q,\n", msg.ID, msg.Text)
+	}
+
+	if at := bytes.Index(content, []byte("\n  \"validation.")); at >= 0 {
+		return append(append(append([]byte{}, content[:at+1]...), group.String()+"\n"...), content[at+1:]...)
+	}
+
+	end := bytes.LastIndex(content, []byte("\n}"))
+	body := strings.TrimRight(group.String(), ",\n")
+	return append(append(append([]byte{}, content[:end]...), ",\n\n"+body...), content[end:]...)
+}
@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/samber/do/v2"
+)
+
+func TestUpdateAvatar(t *testing.T) {
+	t.Run("stores square thumbnails and returns their urls", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := putAvatar(h, session.Token(), "avatar.png", harness.PNG(t, 300, 200)).
+			AssertSuccess(http.StatusOK, message.SuccessUpdateAvatar)
+
+		var updated response.User
+		res.DecodeData(&updated)
+
+		// IDs 1 and 2 went to the user and their refresh token.
+		imageID := fake.Sequential(3).String()
+		if want := harness.BaseURL + "/api/v1/storage/profile/" + imageID + "/512.png?"; !strings.HasPrefix(updated.ImageUrl, want) {
+			t.Errorf("image_url = %q, want a signed url starting with %q", updated.ImageUrl, want)
+		}
+		assertStoredFiles(t, h, "profile/"+imageID+"/256.png", "profile/"+imageID+"/512.png", "profile/"+imageID+"/64.png")
+
+		for _, size := range []string{"64", "256", "512"} {
+			thumbnailURL, ok := updated.ImageThumbnails[size]
+			if !ok {
+				t.Fatalf("image_thumbnails = %v, missing %s", updated.ImageThumbnails, size)
+			}
+
+			body := h.Fetch(thumbnailURL).AssertStatus(http.StatusOK).Body.Bytes()
+			config, format, err := image.DecodeConfig(bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("thumbnail %s is not an image: %v", size, err)
+			}
+			if format != "png" || config.Width != config.Height || size != strconv.Itoa(config.Width) {
+				t.Errorf("thumbnail %s is a %dx%d %s", size, config.Width, config.Height, format)
+			}
+		}
+
+		me := h.Get("/api/user/me", session.Token()).AssertStatus(http.StatusOK)
+		var current response.User
+		me.DecodeData(&current)
+		if len(current.ImageThumbnails) != 3 {
+			t.Errorf("GET /me image_thumbnails = %v, want three sizes", current.ImageThumbnails)
+		}
+	})
+
+	t.Run("releases the previous avatar for the cleanup job", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		putAvatar(h, session.Token(), "first.png", harness.PNG(t, 128, 128)).AssertStatus(http.StatusOK)
+		putAvatar(h, session.Token(), "second.jpg", harness.JPEG(t, 128, 128, 1)).AssertStatus(http.StatusOK)
+
+		// IDs 4 to 6 went to the file records of the first avatar.
+		firstID := fake.Sequential(3).String()
+		imageID := fake.Sequential(7).String()
+		assertStoredFiles(t, h,
+			"profile/"+firstID+"/256.png", "profile/"+firstID+"/512.png", "profile/"+firstID+"/64.png",
+			"profile/"+imageID+"/256.jpg", "profile/"+imageID+"/512.jpg", "profile/"+imageID+"/64.jpg")
+
+		if deleted := cleanUpFiles(t, h); deleted != len(user.AvatarSizes) {
+			t.Errorf("cleanup deleted %d files, want %d", deleted, len(user.AvatarSizes))
+		}
+		assertStoredFiles(t, h, "profile/"+imageID+"/256.jpg", "profile/"+imageID+"/512.jpg", "profile/"+imageID+"/64.jpg")
+	})
+
+	t.Run("reuses the stored thumbnails of an identical avatar", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		image := harness.PNG(t, 128, 128)
+
+		putAvatar(h, session.Token(), "first.png", image).AssertStatus(http.StatusOK)
+		putAvatar(h, session.Token(), "again.png", image).AssertStatus(http.StatusOK)
+
+		imageID := fake.Sequential(3).String()
+		cleanUpFiles(t, h)
+		assertStoredFiles(t, h, "profile/"+imageID+"/256.png", "profile/"+imageID+"/512.png", "profile/"+imageID+"/64.png")
+	})
+
+	t.Run("keeps the current avatar when the upload is rejected", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		putAvatar(h, session.Token(), "avatar.png", harness.PNG(t, 128, 128)).AssertStatus(http.StatusOK)
+		putAvatar(h, session.Token(), "avatar.png", []byte("not an image")).AssertStatus(http.StatusBadRequest)
+
+		imageID := fake.Sequential(3).String()
+		assertStoredFiles(t, h, "profile/"+imageID+"/256.png", "profile/"+imageID+"/512.png", "profile/"+imageID+"/64.png")
+	})
+
+	t.Run("strips exif metadata and applies the orientation", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		// Orientation 6 rotates the image clockwise, turning the red left half
+		// into the top half.
+		res := putAvatar(h, session.Token(), "photo.jpg", harness.JPEG(t, 128, 128, 6)).AssertStatus(http.StatusOK)
+
+		var updated response.User
+		res.DecodeData(&updated)
+		body := h.Fetch(updated.ImageThumbnails["64"]).AssertStatus(http.StatusOK).Body.Bytes()
+
+		if bytes.Contains(body, []byte("Exif")) {
+			t.Error("thumbnail still carries an EXIF block")
+		}
+
+		thumbnail, _, err := image.Decode(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("thumbnail is not an image: %v", err)
+		}
+		top, bottom := thumbnail.At(32, 8), thumbnail.At(32, 56)
+		if r, _, b, _ := top.RGBA(); r < b {
+			t.Errorf("top of the thumbnail = %v, want red", top)
+		}
+		if r, _, b, _ := bottom.RGBA(); b < r {
+			t.Errorf("bottom of the thumbnail = %v, want blue", bottom)
+		}
+	})
+
+	t.Run("rejects invalid uploads", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		tests := map[string]struct {
+			name    string
+			content []byte
+			status  int
+			want    error
+		}{
+			"text with an image extension": {"avatar.png", []byte("just some text"), http.StatusBadRequest, user.ErrorAvatarUnsupportedType},
+			"svg":                          {"avatar.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), http.StatusBadRequest, user.ErrorAvatarUnsupportedType},
+			"truncated png":                {"avatar.png", harness.PNG(t, 128, 128)[:64], http.StatusBadRequest, user.ErrorAvatarInvalid},
+			"too small":                    {"avatar.png", harness.PNG(t, 32, 32), http.StatusBadRequest, user.ErrorAvatarDimensions},
+			"too wide":                     {"avatar.png", harness.PNG(t, user.AvatarMaxDimension+1, 64), http.StatusBadRequest, user.ErrorAvatarDimensions},
+			"too large":                    {"avatar.png", make([]byte, user.AvatarMaxFileSize+1), http.StatusRequestEntityTooLarge, user.ErrorAvatarTooLarge},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				putAvatar(h, session.Token(), tt.name, tt.content).
+					AssertFailure(tt.status, message.FailedUpdateAvatar).
+					AssertError(tt.want.Error())
+			})
+		}
+
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("rejects an infected image before storing it", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		infected := append(harness.PNG(t, 128, 128), fake.EICAR...)
+
+		putAvatar(h, session.Token(), "avatar.png", infected).
+			AssertFailure(http.StatusUnprocessableEntity, message.FailedUpdateAvatar).
+			AssertError(file.ErrorFileInfected.Error())
+
+		assertStoredFiles(t, h)
+		if h.Scanner.Scans() != 1 {
+			t.Errorf("scans = %d, want 1", h.Scanner.Scans())
+		}
+	})
+
+	t.Run("requires an image", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(harness.Request{Method: http.MethodPut, Path: "/api/user/me/avatar", Token: session.Token(), Header: http.Header{"If-Match": {"*"}}, Form: map[string]string{}}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		h := harness.New(t)
+
+		putAvatar(h, "", "avatar.png", harness.PNG(t, 128, 128)).AssertStatus(http.StatusUnauthorized)
+	})
+}
+
+func TestDeleteAvatar(t *testing.T) {
+	t.Run("clears the avatar and releases its files", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		putAvatar(h, session.Token(), "avatar.png", harness.PNG(t, 128, 128)).AssertStatus(http.StatusOK)
+
+		h.DeleteAvatar(session.Token(), "*").AssertSuccess(http.StatusOK, message.SuccessDeleteAvatar)
+
+		var current response.User
+		h.Get("/api/user/me", session.Token()).AssertStatus(http.StatusOK).DecodeData(&current)
+		if current.ImageUrl != "" || current.ImageThumbnails != nil {
+			t.Errorf("user after delete = %+v, want no image", current)
+		}
+
+		cleanUpFiles(t, h)
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("reports a missing avatar", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.DeleteAvatar(session.Token(), "*").
+			AssertFailure(http.StatusNotFound, message.FailedDeleteAvatar).
+			AssertError(user.ErrorAvatarNotFound.Error())
+	})
+}
+
+func putAvatar(h *harness.Harness, token, filename string, content []byte) *harness.Response {
+	return h.Do(harness.Request{
+		Method: http.MethodPut,
+		Path:   "/api/user/me/avatar",
+		Header: http.Header{"If-Match": {"*"}},
+		Token:  token,
+		Files:  map[string]harness.File{"image": {Name: filename, Content: content}},
+	})
+}
+
+func assertStoredFiles(t *testing.T, h *harness.Harness, want ...string) {
+	t.Helper()
+
+	var got []string
+	err := filepath.WalkDir(h.StorageRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(h.StorageRoot, path)
+		got = append(got, filepath.ToSlash(relative))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to walk storage: %v", err)
+	}
+
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("stored files = %v, want %v", got, want)
+	}
+}
+
+// cleanUpFiles runs the file cleanup job once every released file is past its
+// grace period, and returns how many files it deleted.
+func cleanUpFiles(t *testing.T, h *harness.Harness) int {
+	t.Helper()
+
+	h.Clock.Advance(file.CleanupGracePeriod + time.Minute)
+	deleted, err := do.MustInvoke[service.FileService](h.Injector).DeleteUnreferenced(context.Background())
+	if err != nil {
+		t.Fatalf("failed to clean up files: %v", err)
+	}
+	return deleted
+}
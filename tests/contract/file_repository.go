@@ -0,0 +1,148 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+type FileRepositories struct {
+	Users user.Repository
+	Files file.Repository
+}
+
+func FileRepository(t *testing.T, newRepositories func(t *testing.T) FileRepositories) {
+	t.Run("FindByHash only returns files of the owner", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		digest := file.Digest([]byte("hello"))
+		created := mustCreateFile(t, repos.Files, newFile(alice.ID, digest, 5, 1))
+		mustCreateFile(t, repos.Files, newFile(bob.ID, digest, 5, 1))
+
+		got, err := repos.Files.FindByHash(context.Background(), alice.ID.String(), digest)
+		if err != nil {
+			t.Fatalf("FindByHash() error = %v", err)
+		}
+		if len(got) != 1 || got[0].ID != created.ID || got[0].SHA256 != digest {
+			t.Errorf("FindByHash() = %v, want only %s", got, created.ID)
+		}
+
+		byKey, err := repos.Files.GetFileByStorageKey(context.Background(), alice.ID.String(), file.StorageKey(digest))
+		if err != nil {
+			t.Fatalf("GetFileByStorageKey() error = %v", err)
+		}
+		if byKey.ID != created.ID {
+			t.Errorf("GetFileByStorageKey() = %s, want %s", byKey.ID, created.ID)
+		}
+
+		count, err := repos.Files.CountByStorageKey(context.Background(), file.StorageKey(digest))
+		if err != nil {
+			t.Fatalf("CountByStorageKey() error = %v", err)
+		}
+		if count != 2 {
+			t.Errorf("CountByStorageKey() = %d, want 2", count)
+		}
+	})
+
+	t.Run("AddReferences changes the reference count", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		created := mustCreateFile(t, repos.Files, newFile(owner.ID, file.Digest([]byte("hello")), 5, 1))
+
+		acquired, err := repos.Files.AddReferences(context.Background(), created.ID.String(), 2)
+		if err != nil {
+			t.Fatalf("AddReferences() error = %v", err)
+		}
+		if acquired.RefCount != 3 {
+			t.Errorf("RefCount = %d, want 3", acquired.RefCount)
+		}
+
+		_, err = repos.Files.AddReferences(context.Background(), uuid.NewString(), 1)
+		if !errors.Is(err, file.ErrorFileNotFound) {
+			t.Fatalf("AddReferences() of a missing file error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+	})
+
+	t.Run("SumSizeByOwner adds up every file of the owner", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		mustCreateFile(t, repos.Files, newFile(alice.ID, file.Digest([]byte("a")), 5, 1))
+		mustCreateFile(t, repos.Files, newFile(alice.ID, file.Digest([]byte("b")), 7, 0))
+		mustCreateFile(t, repos.Files, newFile(bob.ID, file.Digest([]byte("c")), 11, 1))
+
+		usage, err := repos.Files.SumSizeByOwner(context.Background(), alice.ID.String())
+		if err != nil {
+			t.Fatalf("SumSizeByOwner() error = %v", err)
+		}
+		if usage != 12 {
+			t.Errorf("SumSizeByOwner() = %d, want 12", usage)
+		}
+
+		usage, err = repos.Files.SumSizeByOwner(context.Background(), uuid.NewString())
+		if err != nil || usage != 0 {
+			t.Errorf("SumSizeByOwner() of an owner without files = %d, %v, want 0", usage, err)
+		}
+	})
+
+	t.Run("DeleteUnreferenced keeps referenced files", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		released := mustCreateFile(t, repos.Files, newFile(owner.ID, file.Digest([]byte("a")), 5, 0))
+		referenced := mustCreateFile(t, repos.Files, newFile(owner.ID, file.Digest([]byte("b")), 5, 1))
+
+		got, err := repos.Files.FindUnreferenced(context.Background(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("FindUnreferenced() error = %v", err)
+		}
+		if len(got) != 1 || got[0].ID != released.ID {
+			t.Errorf("FindUnreferenced() = %v, want only %s", got, released.ID)
+		}
+
+		got, err = repos.Files.FindUnreferenced(context.Background(), time.Now().Add(-time.Hour))
+		if err != nil || len(got) != 0 {
+			t.Errorf("FindUnreferenced() before the release = %v, %v, want none", got, err)
+		}
+
+		if err = repos.Files.DeleteUnreferenced(context.Background(), referenced.ID.String()); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Fatalf("DeleteUnreferenced() of a referenced file error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+		if err = repos.Files.DeleteUnreferenced(context.Background(), released.ID.String()); err != nil {
+			t.Fatalf("DeleteUnreferenced() error = %v", err)
+		}
+		if _, err = repos.Files.GetFileByID(context.Background(), released.ID.String()); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Errorf("GetFileByID() after delete error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+	})
+}
+
+func newFile(ownerID identity.ID, digest string, size int64, refCount int64) file.File {
+	return file.File{
+		ID:          identity.NewID(uuid.New()),
+		OwnerID:     ownerID,
+		StorageKey:  file.StorageKey(digest),
+		Name:        "report.pdf",
+		Size:        size,
+		ContentType: "application/pdf",
+		SHA256:      digest,
+		RefCount:    refCount,
+	}
+}
+
+func mustCreateFile(t *testing.T, repo file.Repository, fileEntity file.File) file.File {
+	t.Helper()
+
+	created, err := repo.Create(context.Background(), fileEntity)
+	if err != nil {
+		t.Fatalf("Files.Create() error = %v", err)
+	}
+	return created
+}
@@ -0,0 +1,137 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func IdempotencyRepository(t *testing.T, newRepository func(t *testing.T) idempotency.Repository) {
+	t.Run("Create claims a key in flight", func(t *testing.T) {
+		repo := newRepository(t)
+
+		created, err := repo.Create(context.Background(), newRecord("scope-a", "key-1", time.Hour))
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if created.ID.ID == uuid.Nil || created.CreatedAt.IsZero() {
+			t.Errorf("Create() = %+v, want an ID and timestamps", created)
+		}
+		if !created.InFlight() {
+			t.Errorf("Create() Status = %d, want the record in flight", created.Status)
+		}
+	})
+
+	t.Run("Create rejects a key already claimed in its scope", func(t *testing.T) {
+		repo := newRepository(t)
+		mustCreateRecord(t, repo, newRecord("scope-a", "key-1", time.Hour))
+
+		_, err := repo.Create(context.Background(), newRecord("scope-a", "key-1", time.Hour))
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Fatalf("Create() error = %v, want %v", err, gorm.ErrDuplicatedKey)
+		}
+		if _, err = repo.Create(context.Background(), newRecord("scope-b", "key-1", time.Hour)); err != nil {
+			t.Fatalf("Create() in another scope error = %v", err)
+		}
+	})
+
+	t.Run("Complete stores the response", func(t *testing.T) {
+		repo := newRepository(t)
+		created := mustCreateRecord(t, repo, newRecord("scope-a", "key-1", time.Hour))
+		response := idempotency.Response{
+			Status: http.StatusCreated,
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+			Body:   []byte(`{"status":true}`),
+		}
+
+		if err := repo.Complete(context.Background(), created.ID.String(), response); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		got, err := repo.GetByKey(context.Background(), "scope-a", "key-1")
+		if err != nil {
+			t.Fatalf("GetByKey() error = %v", err)
+		}
+		if got.ID != created.ID || got.Fingerprint != created.Fingerprint || !reflect.DeepEqual(got.Response, response) {
+			t.Errorf("GetByKey() = %+v, want %+v completed with %+v", got, created, response)
+		}
+	})
+
+	t.Run("Complete reports a missing key as not found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		err := repo.Complete(context.Background(), uuid.NewString(), idempotency.Response{Status: http.StatusOK})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Complete() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("GetByKey reports missing keys as not found", func(t *testing.T) {
+		repo := newRepository(t)
+		mustCreateRecord(t, repo, newRecord("scope-a", "key-1", time.Hour))
+
+		_, err := repo.GetByKey(context.Background(), "scope-b", "key-1")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetByKey() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("Delete frees the key", func(t *testing.T) {
+		repo := newRepository(t)
+		created := mustCreateRecord(t, repo, newRecord("scope-a", "key-1", time.Hour))
+
+		if err := repo.Delete(context.Background(), created.ID.String()); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.Create(context.Background(), newRecord("scope-a", "key-1", time.Hour)); err != nil {
+			t.Fatalf("Create() after Delete() error = %v", err)
+		}
+	})
+
+	t.Run("DeleteExpired deletes only the expired keys", func(t *testing.T) {
+		repo := newRepository(t)
+		mustCreateRecord(t, repo, newRecord("scope-a", "expired", -time.Minute))
+		mustCreateRecord(t, repo, newRecord("scope-a", "active", time.Hour))
+
+		deleted, err := repo.DeleteExpired(context.Background(), time.Now())
+		if err != nil {
+			t.Fatalf("DeleteExpired() error = %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("DeleteExpired() = %d, want 1", deleted)
+		}
+		if _, err = repo.GetByKey(context.Background(), "scope-a", "expired"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByKey(expired) error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+		if _, err = repo.GetByKey(context.Background(), "scope-a", "active"); err != nil {
+			t.Errorf("GetByKey(active) error = %v", err)
+		}
+	})
+}
+
+func newRecord(scope string, key string, ttl time.Duration) idempotency.Record {
+	return idempotency.Record{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: "fingerprint-" + key,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+}
+
+func mustCreateRecord(t *testing.T, repo idempotency.Repository, record idempotency.Record) idempotency.Record {
+	t.Helper()
+
+	created, err := repo.Create(context.Background(), record)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return created
+}
@@ -0,0 +1,186 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepositories struct {
+	Users         user.Repository
+	RefreshTokens refresh_token.Repository
+}
+
+func RefreshTokenRepository(t *testing.T, newRepositories func(t *testing.T) RefreshTokenRepositories) {
+	t.Run("Create assigns an ID and timestamps", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+
+		created, err := repos.RefreshTokens.Create(context.Background(), newRefreshToken(owner.ID, "token-a", time.Hour))
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if created.ID.ID == uuid.Nil {
+			t.Error("Create() did not assign an ID")
+		}
+		if created.CreatedAt.IsZero() {
+			t.Error("Create() did not set CreatedAt")
+		}
+	})
+
+	t.Run("Create rejects a duplicate token", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(owner.ID, "token-a", time.Hour))
+
+		_, err := repos.RefreshTokens.Create(context.Background(), newRefreshToken(owner.ID, "token-a", time.Hour))
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Fatalf("Create() error = %v, want %v", err, gorm.ErrDuplicatedKey)
+		}
+	})
+
+	t.Run("FindByUserID returns the user's token", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(bob.ID, "token-b", time.Hour))
+
+		got, err := repos.RefreshTokens.FindByUserID(context.Background(), bob.ID.String())
+		if err != nil {
+			t.Fatalf("FindByUserID() error = %v", err)
+		}
+		if got.Token != "token-b" || got.UserID != bob.ID {
+			t.Errorf("FindByUserID() = %+v, want token-b owned by %s", got, bob.ID)
+		}
+	})
+
+	t.Run("FindByUserID reports missing tokens as not found", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		_, err := repos.RefreshTokens.FindByUserID(context.Background(), uuid.NewString())
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByUserID() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("FindActiveByUserIDs returns the unexpired tokens of the users", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		carol := mustRegister(t, repos.Users, "carol@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a1", time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a2", -time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(bob.ID, "token-b", time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(carol.ID, "token-c", time.Hour))
+
+		got, err := repos.RefreshTokens.FindActiveByUserIDs(context.Background(), []string{alice.ID.String(), bob.ID.String()})
+		if err != nil {
+			t.Fatalf("FindActiveByUserIDs() error = %v", err)
+		}
+		tokens := map[string]bool{}
+		for _, token := range got {
+			tokens[token.Token] = true
+		}
+		if len(got) != 2 || !tokens["token-a1"] || !tokens["token-b"] {
+			t.Errorf("FindActiveByUserIDs() = %+v, want token-a1 and token-b", got)
+		}
+
+		if got, err = repos.RefreshTokens.FindActiveByUserIDs(context.Background(), nil); err != nil || len(got) != 0 {
+			t.Errorf("FindActiveByUserIDs(nil) = %+v, %v, want nothing", got, err)
+		}
+	})
+
+	t.Run("DeleteByUserID removes only that user's tokens", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(bob.ID, "token-b", time.Hour))
+
+		if err := repos.RefreshTokens.DeleteByUserID(context.Background(), alice.ID.String()); err != nil {
+			t.Fatalf("DeleteByUserID() error = %v", err)
+		}
+
+		assertTokenGone(t, repos.RefreshTokens, alice.ID)
+		assertTokenExists(t, repos.RefreshTokens, bob.ID)
+	})
+
+	t.Run("DeleteByToken removes the matching token", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", time.Hour))
+
+		if err := repos.RefreshTokens.DeleteByToken(context.Background(), "token-a"); err != nil {
+			t.Fatalf("DeleteByToken() error = %v", err)
+		}
+
+		assertTokenGone(t, repos.RefreshTokens, alice.ID)
+	})
+
+	t.Run("deleted tokens can be issued again", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", time.Hour))
+
+		if err := repos.RefreshTokens.DeleteByToken(context.Background(), "token-a"); err != nil {
+			t.Fatalf("DeleteByToken() error = %v", err)
+		}
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", time.Hour))
+	})
+
+	t.Run("DeleteExpired keeps tokens that are still valid", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice := mustRegister(t, repos.Users, "alice@example.com")
+		bob := mustRegister(t, repos.Users, "bob@example.com")
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(alice.ID, "token-a", -time.Hour))
+		mustCreateToken(t, repos.RefreshTokens, newRefreshToken(bob.ID, "token-b", time.Hour))
+
+		if err := repos.RefreshTokens.DeleteExpired(context.Background()); err != nil {
+			t.Fatalf("DeleteExpired() error = %v", err)
+		}
+
+		assertTokenGone(t, repos.RefreshTokens, alice.ID)
+		assertTokenExists(t, repos.RefreshTokens, bob.ID)
+	})
+}
+
+func newRefreshToken(userID identity.ID, token string, ttl time.Duration) refresh_token.RefreshToken {
+	return refresh_token.RefreshToken{
+		UserID:    userID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+func mustCreateToken(t *testing.T, repo refresh_token.Repository, token refresh_token.RefreshToken) {
+	t.Helper()
+
+	if _, err := repo.Create(context.Background(), token); err != nil {
+		t.Fatalf("Create(%q) error = %v", token.Token, err)
+	}
+}
+
+func assertTokenExists(t *testing.T, repo refresh_token.Repository, userID identity.ID) {
+	t.Helper()
+
+	if _, err := repo.FindByUserID(context.Background(), userID.String()); err != nil {
+		t.Errorf("FindByUserID(%s) error = %v, want token to exist", userID, err)
+	}
+}
+
+func assertTokenGone(t *testing.T, repo refresh_token.Repository, userID identity.ID) {
+	t.Helper()
+
+	if _, err := repo.FindByUserID(context.Background(), userID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindByUserID(%s) error = %v, want %v", userID, err, gorm.ErrRecordNotFound)
+	}
+}
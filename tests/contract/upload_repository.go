@@ -0,0 +1,175 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UploadRepositories struct {
+	Users   user.Repository
+	Files   file.Repository
+	Uploads file.UploadRepository
+}
+
+func UploadRepository(t *testing.T, newRepositories func(t *testing.T) UploadRepositories) {
+	t.Run("Create stores the upload with its metadata", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+
+		created := mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 10, time.Hour))
+		if created.ID.ID == uuid.Nil {
+			t.Error("Create() did not assign an ID")
+		}
+
+		got, err := repos.Uploads.GetUploadByID(context.Background(), created.ID.String())
+		if err != nil {
+			t.Fatalf("GetUploadByID() error = %v", err)
+		}
+		if got.OwnerID != owner.ID || got.Length != 10 || got.Offset != 0 || got.FileID != nil {
+			t.Errorf("GetUploadByID() = %+v", got)
+		}
+		if got.Metadata["filename"] != "report.pdf" {
+			t.Errorf("Metadata = %v, want filename report.pdf", got.Metadata)
+		}
+	})
+
+	t.Run("GetUploadByID reports a missing upload", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		_, err := repos.Uploads.GetUploadByID(context.Background(), uuid.NewString())
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetUploadByID() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("AdvanceOffset only moves from the expected offset", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		created := mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 10, time.Hour))
+
+		advanced, err := repos.Uploads.AdvanceOffset(context.Background(), created.ID.String(), 0, 4, created.PartKey(0, "a"))
+		if err != nil {
+			t.Fatalf("AdvanceOffset() error = %v", err)
+		}
+		if advanced.Offset != 4 {
+			t.Errorf("Offset = %d, want 4", advanced.Offset)
+		}
+
+		_, err = repos.Uploads.AdvanceOffset(context.Background(), created.ID.String(), 0, 6, created.PartKey(0, "b"))
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("stale AdvanceOffset() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+
+		if _, err = repos.Uploads.AdvanceOffset(context.Background(), created.ID.String(), 4, 10, created.PartKey(4, "c")); err != nil {
+			t.Fatalf("AdvanceOffset() error = %v", err)
+		}
+		stored, err := repos.Uploads.GetUploadByID(context.Background(), created.ID.String())
+		if err != nil {
+			t.Fatalf("GetUploadByID() error = %v", err)
+		}
+		if want := []string{created.PartKey(0, "a"), created.PartKey(4, "c")}; !slices.Equal(stored.Parts, want) {
+			t.Errorf("Parts = %v, want the accepted parts %v", stored.Parts, want)
+		}
+	})
+
+	t.Run("MarkCompleted links the file", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		created := mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 0, time.Hour))
+
+		fileID := identity.NewID(uuid.New())
+		_, err := repos.Files.Create(context.Background(), file.File{
+			ID:          fileID,
+			OwnerID:     owner.ID,
+			StorageKey:  file.StorageKey(fileID.String()),
+			Name:        "report.pdf",
+			ContentType: "application/pdf",
+		})
+		if err != nil {
+			t.Fatalf("Files.Create() error = %v", err)
+		}
+
+		completed, err := repos.Uploads.MarkCompleted(context.Background(), created.ID.String(), fileID)
+		if err != nil {
+			t.Fatalf("MarkCompleted() error = %v", err)
+		}
+		if completed.FileID == nil || *completed.FileID != fileID {
+			t.Errorf("FileID = %v, want %v", completed.FileID, fileID)
+		}
+
+		stored, err := repos.Files.GetFileByID(context.Background(), fileID.String())
+		if err != nil {
+			t.Fatalf("GetFileByID() error = %v", err)
+		}
+		if stored.OwnerID != owner.ID || stored.Name != "report.pdf" {
+			t.Errorf("GetFileByID() = %+v", stored)
+		}
+	})
+
+	t.Run("FindExpired skips live and completed uploads", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		expired := mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 10, -time.Hour))
+		mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 10, time.Hour))
+
+		got, err := repos.Uploads.FindExpired(context.Background(), time.Now())
+		if err != nil {
+			t.Fatalf("FindExpired() error = %v", err)
+		}
+		if len(got) != 1 || got[0].ID != expired.ID {
+			t.Errorf("FindExpired() = %v, want only %s", got, expired.ID)
+		}
+	})
+
+	t.Run("Delete hides the upload", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := mustRegister(t, repos.Users, "alice@example.com")
+		created := mustCreateUpload(t, repos.Uploads, newUpload(owner.ID, 10, -time.Hour))
+
+		if err := repos.Uploads.Delete(context.Background(), created.ID.String()); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		_, err := repos.Uploads.GetUploadByID(context.Background(), created.ID.String())
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetUploadByID() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+
+		expired, err := repos.Uploads.FindExpired(context.Background(), time.Now())
+		if err != nil {
+			t.Fatalf("FindExpired() error = %v", err)
+		}
+		if len(expired) != 0 {
+			t.Errorf("FindExpired() = %v, want none", expired)
+		}
+	})
+}
+
+func newUpload(ownerID identity.ID, length int64, ttl time.Duration) file.Upload {
+	return file.Upload{
+		OwnerID:   ownerID,
+		Length:    length,
+		Metadata:  map[string]string{"filename": "report.pdf"},
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+func mustCreateUpload(t *testing.T, repo file.UploadRepository, uploadEntity file.Upload) file.Upload {
+	t.Helper()
+
+	created, err := repo.Create(context.Background(), uploadEntity)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return created
+}
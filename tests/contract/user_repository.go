@@ -0,0 +1,664 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func UserRepository(t *testing.T, newRepository func(t *testing.T) user.Repository) {
+	t.Run("Register assigns an ID and timestamps", func(t *testing.T) {
+		repo := newRepository(t)
+
+		registered, err := repo.Register(context.Background(), NewUser("alice@example.com"))
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		if registered.ID.ID == uuid.Nil {
+			t.Error("Register() did not assign an ID")
+		}
+		if registered.CreatedAt.IsZero() || registered.UpdatedAt.IsZero() {
+			t.Error("Register() did not set timestamps")
+		}
+		if registered.DeletedAt != nil {
+			t.Errorf("Register() DeletedAt = %v, want nil", registered.DeletedAt)
+		}
+		if registered.Version != 1 {
+			t.Errorf("Register() Version = %d, want 1", registered.Version)
+		}
+	})
+
+	t.Run("Register rejects a duplicate email", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+
+		_, err := repo.Register(context.Background(), NewUser("alice@example.com"))
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Fatalf("Register() error = %v, want %v", err, gorm.ErrDuplicatedKey)
+		}
+	})
+
+	t.Run("Register reuses the email of a deleted user", func(t *testing.T) {
+		repo := newRepository(t)
+		deleted := mustRegister(t, repo, "alice@example.com")
+		if err := repo.Delete(context.Background(), deleted.ID.String(), deleted.Version); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		if _, err := repo.Register(context.Background(), NewUser("alice@example.com")); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	})
+
+	t.Run("GetUserByID returns the stored user", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		got, err := repo.GetUserByID(context.Background(), registered.ID.String())
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		assertSameUser(t, got, registered)
+	})
+
+	t.Run("GetUserByID reports unknown users as not found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		_, err := repo.GetUserByID(context.Background(), uuid.NewString())
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetUserByID() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("GetUserByEmail returns the stored user", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+		mustRegister(t, repo, "bob@example.com")
+
+		got, err := repo.GetUserByEmail(context.Background(), "alice@example.com")
+		if err != nil {
+			t.Fatalf("GetUserByEmail() error = %v", err)
+		}
+		assertSameUser(t, got, registered)
+
+		_, err = repo.GetUserByEmail(context.Background(), "carol@example.com")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetUserByEmail() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("CheckEmail reports whether the email is taken", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+
+		_, exists, err := repo.CheckEmail(context.Background(), "alice@example.com")
+		if err != nil || !exists {
+			t.Fatalf("CheckEmail() = %v, %v, want true, nil", exists, err)
+		}
+
+		_, exists, err = repo.CheckEmail(context.Background(), "bob@example.com")
+		if exists || !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("CheckEmail() = %v, %v, want false, %v", exists, err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("Update writes only the non-zero fields", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		updated, err := repo.Update(context.Background(), user.User{
+			ID:      registered.ID,
+			Name:    "Alice Updated",
+			Version: registered.Version,
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if updated.Name != "Alice Updated" {
+			t.Errorf("Update() Name = %q, want %q", updated.Name, "Alice Updated")
+		}
+
+		got, err := repo.GetUserByID(context.Background(), registered.ID.String())
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if got.Name != "Alice Updated" {
+			t.Errorf("stored Name = %q, want %q", got.Name, "Alice Updated")
+		}
+		if got.Email != registered.Email || got.PhoneNumber != registered.PhoneNumber {
+			t.Errorf("Update() overwrote fields that were not set: got %+v", got)
+		}
+		if got.Password.Password != registered.Password.Password {
+			t.Error("Update() overwrote the password")
+		}
+	})
+
+	t.Run("Update reports unknown users as not found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		_, err := repo.Update(context.Background(), user.User{
+			ID:      identity.NewID(uuid.New()),
+			Name:    "Ghost",
+			Version: 1,
+		})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Update() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("Update rejects an email owned by another user", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+		bob := mustRegister(t, repo, "bob@example.com")
+
+		_, err := repo.Update(context.Background(), user.User{
+			ID:      bob.ID,
+			Email:   "alice@example.com",
+			Version: bob.Version,
+		})
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Fatalf("Update() error = %v, want %v", err, gorm.ErrDuplicatedKey)
+		}
+	})
+
+	t.Run("Update moves the user to the next version", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		updated, err := repo.Update(context.Background(), user.User{
+			ID:      registered.ID,
+			Name:    "Alice Updated",
+			Version: registered.Version,
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if updated.Version != registered.Version+1 {
+			t.Errorf("Update() Version = %d, want %d", updated.Version, registered.Version+1)
+		}
+
+		_, err = repo.Update(context.Background(), user.User{
+			ID:      registered.ID,
+			Name:    "Alice Stale",
+			Version: registered.Version,
+		})
+		if !errors.Is(err, shared.ErrorVersionConflict) {
+			t.Fatalf("Update() at a stale version error = %v, want %v", err, shared.ErrorVersionConflict)
+		}
+
+		got, err := repo.GetUserByID(context.Background(), registered.ID.String())
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if got.Name != "Alice Updated" || got.Version != updated.Version {
+			t.Errorf("stored user = %q at version %d, want %q at version %d", got.Name, got.Version, "Alice Updated", updated.Version)
+		}
+	})
+
+	t.Run("UpdateImageUrl sets and clears the image", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		updated, err := repo.UpdateImageUrl(context.Background(), registered.ID.String(), registered.Version, shared.NewURLFromTable("profile/new/512.png"))
+		if err != nil {
+			t.Fatalf("UpdateImageUrl() error = %v", err)
+		}
+		if updated.ImageUrl.Path != "profile/new/512.png" || updated.Name != registered.Name {
+			t.Errorf("UpdateImageUrl() = %+v", updated)
+		}
+		if updated.Version != registered.Version+1 {
+			t.Errorf("UpdateImageUrl() Version = %d, want %d", updated.Version, registered.Version+1)
+		}
+
+		cleared, err := repo.UpdateImageUrl(context.Background(), registered.ID.String(), updated.Version, shared.URL{})
+		if err != nil {
+			t.Fatalf("UpdateImageUrl() clearing error = %v", err)
+		}
+		if cleared.ImageUrl.Path != "" {
+			t.Errorf("ImageUrl after clearing = %q, want empty", cleared.ImageUrl.Path)
+		}
+
+		got, err := repo.GetUserByID(context.Background(), registered.ID.String())
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if got.ImageUrl.Path != "" {
+			t.Errorf("stored ImageUrl = %q, want empty", got.ImageUrl.Path)
+		}
+	})
+
+	t.Run("UpdateImageUrl reports unknown users as not found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		_, err := repo.UpdateImageUrl(context.Background(), uuid.NewString(), 1, shared.NewURLFromTable("profile/x.png"))
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("UpdateImageUrl() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("UpdateImageUrl rejects a stale version", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+		if _, err := repo.UpdateImageUrl(context.Background(), registered.ID.String(), registered.Version, shared.NewURLFromTable("profile/new/512.png")); err != nil {
+			t.Fatalf("UpdateImageUrl() error = %v", err)
+		}
+
+		_, err := repo.UpdateImageUrl(context.Background(), registered.ID.String(), registered.Version, shared.URL{})
+		if !errors.Is(err, shared.ErrorVersionConflict) {
+			t.Fatalf("UpdateImageUrl() error = %v, want %v", err, shared.ErrorVersionConflict)
+		}
+	})
+
+	t.Run("Delete hides the user from every read", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		if err := repo.Delete(context.Background(), registered.ID.String(), registered.Version); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		if _, err := repo.GetUserByID(context.Background(), registered.ID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetUserByID() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+		if _, err := repo.GetUserByEmail(context.Background(), registered.Email); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetUserByEmail() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+
+		result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{})
+		if err != nil {
+			t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+		}
+		if result.Count == nil || *result.Count != 0 {
+			t.Errorf("GetAllUsersWithPagination() Count = %v, want 0", result.Count)
+		}
+	})
+
+	t.Run("Delete keeps a user that moved to another version", func(t *testing.T) {
+		repo := newRepository(t)
+		registered := mustRegister(t, repo, "alice@example.com")
+
+		err := repo.Delete(context.Background(), registered.ID.String(), registered.Version+1)
+		if !errors.Is(err, shared.ErrorVersionConflict) {
+			t.Fatalf("Delete() error = %v, want %v", err, shared.ErrorVersionConflict)
+		}
+		if _, err = repo.GetUserByID(context.Background(), registered.ID.String()); err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+	})
+
+	t.Run("Delete ignores unknown users", func(t *testing.T) {
+		repo := newRepository(t)
+
+		if err := repo.Delete(context.Background(), uuid.NewString(), 1); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination pages through every user once", func(t *testing.T) {
+		repo := newRepository(t)
+		for i := range 7 {
+			mustRegister(t, repo, fmt.Sprintf("user%d@example.com", i))
+		}
+
+		seen := make(map[string]bool)
+		for page := 1; page <= 3; page++ {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{
+				Page:    page,
+				PerPage: 3,
+			})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+			}
+
+			want := pagination.Response{Page: page, PerPage: 3, MaxPage: 3, Count: count(7)}
+			if !reflect.DeepEqual(result.Response, want) {
+				t.Errorf("page %d meta = %+v, want %+v", page, result.Response, want)
+			}
+
+			wantLen := 3
+			if page == 3 {
+				wantLen = 1
+			}
+			if len(result.Data) != wantLen {
+				t.Errorf("page %d returned %d users, want %d", page, len(result.Data), wantLen)
+			}
+
+			for _, item := range result.Data {
+				u, ok := item.(user.User)
+				if !ok {
+					t.Fatalf("page %d returned %T, want user.User", page, item)
+				}
+				if seen[u.ID.String()] {
+					t.Errorf("user %s returned on more than one page", u.Email)
+				}
+				seen[u.ID.String()] = true
+			}
+		}
+
+		if len(seen) != 7 {
+			t.Errorf("pages returned %d distinct users, want 7", len(seen))
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination applies defaults", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+
+		result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{})
+		if err != nil {
+			t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+		}
+
+		want := pagination.Response{Page: 1, PerPage: 10, MaxPage: 1, Count: count(1)}
+		if !reflect.DeepEqual(result.Response, want) {
+			t.Errorf("meta = %+v, want %+v", result.Response, want)
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination pages forward and back by cursor", func(t *testing.T) {
+		repo := newRepository(t)
+		var registered []string
+		for i := range 7 {
+			registered = append(registered, mustRegister(t, repo, fmt.Sprintf("user%d@example.com", i)).Email)
+		}
+
+		var (
+			emails []string
+			pages  []pagination.Response
+			cursor = ""
+		)
+		for range 3 {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{PerPage: 3, Cursor: &cursor})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+			}
+			if result.Page != 0 || result.MaxPage != 0 || result.Count == nil || *result.Count != 7 {
+				t.Errorf("meta = %+v, want no page numbers and a count of 7", result.Response)
+			}
+			emails = append(emails, userEmails(t, result.Data)...)
+			pages = append(pages, result.Response)
+			cursor = result.Next
+		}
+
+		if !reflect.DeepEqual(slices.Sorted(slices.Values(emails)), registered) {
+			t.Errorf("pages returned %v, want each of %v once", emails, registered)
+		}
+		if pages[0].Prev != "" || pages[0].Next == "" || pages[2].Next != "" || pages[2].Prev == "" {
+			t.Errorf("cursors = %+v, want no prev on the first page and no next on the last", pages)
+		}
+
+		back := pages[2].Prev
+		result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{PerPage: 3, Cursor: &back})
+		if err != nil {
+			t.Fatalf("GetAllUsersWithPagination(prev) error = %v", err)
+		}
+		if got := userEmails(t, result.Data); !reflect.DeepEqual(got, emails[3:6]) {
+			t.Errorf("previous page = %v, want %v", got, emails[3:6])
+		}
+		if result.Next == "" || result.Prev == "" {
+			t.Errorf("cursors = %+v, want both around the middle page", result.Response)
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination rejects a tampered cursor", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+		mustRegister(t, repo, "bob@example.com")
+
+		first := ""
+		result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{PerPage: 1, Cursor: &first})
+		if err != nil {
+			t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+		}
+
+		for _, cursor := range []string{result.Next + "x", "x" + result.Next, "not-a-cursor"} {
+			_, err = repo.GetAllUsersWithPagination(context.Background(), pagination.Request{PerPage: 1, Cursor: &cursor})
+			if !errors.Is(err, pagination.ErrorCursorInvalid) {
+				t.Errorf("GetAllUsersWithPagination(%q) error = %v, want %v", cursor, err, pagination.ErrorCursorInvalid)
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination skips the count on request", func(t *testing.T) {
+		repo := newRepository(t)
+		mustRegister(t, repo, "alice@example.com")
+
+		first := ""
+		for _, req := range []pagination.Request{{SkipCount: true}, {SkipCount: true, Cursor: &first}} {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), req)
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+			}
+			if result.Count != nil || result.MaxPage != 0 || len(result.Data) != 1 {
+				t.Errorf("meta = %+v with %d users, want no count and one user", result.Response, len(result.Data))
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination sorts by the requested fields", func(t *testing.T) {
+		repo := newRepository(t)
+		for _, email := range []string{"carol@example.com", "alice1@example.com", "bob@example.com", "alice2@example.com"} {
+			u := NewUser(email)
+			u.Name = strings.ToUpper(email[:1]) + strings.TrimRight(email[1:strings.Index(email, "@")], "12")
+			if _, err := repo.Register(context.Background(), u); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+		}
+
+		tests := []struct {
+			sort string
+			want []string
+		}{
+			{"email", []string{"alice1@example.com", "alice2@example.com", "bob@example.com", "carol@example.com"}},
+			{"-name,email", []string{"carol@example.com", "bob@example.com", "alice1@example.com", "alice2@example.com"}},
+			{"name,-email", []string{"alice2@example.com", "alice1@example.com", "bob@example.com", "carol@example.com"}},
+		}
+		for _, tt := range tests {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination(%q) error = %v", tt.sort, err)
+			}
+			if got := userEmails(t, result.Data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAllUsersWithPagination(%q) = %v, want %v", tt.sort, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination filters by typed fields", func(t *testing.T) {
+		repo := newRepository(t)
+		admin := NewUser("admin@example.com")
+		admin.Role = user.NewRoleFromTable(user.RoleAdmin)
+		admin.IsVerified = true
+		for _, u := range []user.User{admin, NewUser("alice@example.com"), NewUser("bob@example.com")} {
+			if _, err := repo.Register(context.Background(), u); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+		}
+
+		tomorrow := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+		tests := []struct {
+			filters []pagination.Filter
+			want    []string
+		}{
+			{[]pagination.Filter{{Field: "role", Operator: pagination.OperatorEqual, Value: "admin"}}, []string{"admin@example.com"}},
+			{[]pagination.Filter{{Field: "is_verified", Operator: pagination.OperatorEqual, Value: "false"}}, []string{"alice@example.com", "bob@example.com"}},
+			{[]pagination.Filter{{Field: "email", Operator: pagination.OperatorIn, Value: "bob@example.com,admin@example.com"}}, []string{"admin@example.com", "bob@example.com"}},
+			{[]pagination.Filter{
+				{Field: "role", Operator: pagination.OperatorNotEqual, Value: "admin"},
+				{Field: "email", Operator: pagination.OperatorGreater, Value: "alice@example.com"},
+			}, []string{"bob@example.com"}},
+			{[]pagination.Filter{{Field: "created_at", Operator: pagination.OperatorGreaterOrEqual, Value: tomorrow}}, []string{}},
+			{[]pagination.Filter{{Field: "created_at", Operator: pagination.OperatorLess, Value: tomorrow}}, []string{"admin@example.com", "alice@example.com", "bob@example.com"}},
+		}
+		for _, tt := range tests {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{Sort: "email", Filters: tt.filters})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination(%v) error = %v", tt.filters, err)
+			}
+			if got := userEmails(t, result.Data); !reflect.DeepEqual(got, tt.want) || *result.Count != int64(len(tt.want)) {
+				t.Errorf("GetAllUsersWithPagination(%v) = %v (count %d), want %v", tt.filters, got, *result.Count, tt.want)
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination rejects fields outside the whitelist", func(t *testing.T) {
+		repo := newRepository(t)
+		empty := ""
+
+		tests := []struct {
+			req  pagination.Request
+			want error
+		}{
+			{pagination.Request{Sort: "password"}, pagination.ErrorSortField},
+			{pagination.Request{Sort: "role"}, pagination.ErrorSortField},
+			{pagination.Request{Sort: "name", Cursor: &empty}, pagination.ErrorSortCursor},
+			{pagination.Request{Filters: []pagination.Filter{{Field: "password", Operator: pagination.OperatorEqual}}}, pagination.ErrorFilterField},
+			{pagination.Request{Filters: []pagination.Filter{{Field: "is_verified", Operator: pagination.OperatorGreater, Value: "true"}}}, pagination.ErrorFilterOperator},
+			{pagination.Request{Filters: []pagination.Filter{{Field: "name", Operator: "like", Value: "a"}}}, pagination.ErrorFilterOperator},
+			{pagination.Request{Filters: []pagination.Filter{{Field: "is_verified", Operator: pagination.OperatorEqual, Value: "maybe"}}}, pagination.ErrorFilterValue},
+			{pagination.Request{Filters: []pagination.Filter{{Field: "created_at", Operator: pagination.OperatorLess, Value: "yesterday"}}}, pagination.ErrorFilterValue},
+		}
+		for _, tt := range tests {
+			if _, err := repo.GetAllUsersWithPagination(context.Background(), tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("GetAllUsersWithPagination(%+v) error = %v, want %v", tt.req, err, tt.want)
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination searches name and email case-insensitively", func(t *testing.T) {
+		repo := newRepository(t)
+		alice := NewUser("alice@example.com")
+		alice.Name = "Alice Liddell"
+		if _, err := repo.Register(context.Background(), alice); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		mustRegister(t, repo, "bob@example.com")
+		mustRegister(t, repo, "carol@example.org")
+
+		tests := []struct {
+			search string
+			want   int64
+		}{
+			{search: "LIDDELL", want: 1},
+			{search: "example.com", want: 2},
+			{search: "EXAMPLE", want: 3},
+			{search: "nobody", want: 0},
+		}
+		for _, tt := range tests {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{Search: tt.search})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination(%q) error = %v", tt.search, err)
+			}
+			if result.Count == nil || *result.Count != tt.want || int64(len(result.Data)) != tt.want {
+				t.Errorf("GetAllUsersWithPagination(%q) = %d users (count %v), want %d", tt.search, len(result.Data), result.Count, tt.want)
+			}
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination ranks exact and prefix matches first", func(t *testing.T) {
+		repo := newRepository(t)
+		for email, name := range map[string]string{"x@example.com": "Xalicex", "a@example.com": "Alice", "b@example.com": "Alicea"} {
+			u := NewUser(email)
+			u.Name = name
+			if _, err := repo.Register(context.Background(), u); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+		}
+
+		result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{Search: "ALICE"})
+		if err != nil {
+			t.Fatalf("GetAllUsersWithPagination() error = %v", err)
+		}
+		if got, want := userEmails(t, result.Data), []string{"a@example.com", "b@example.com", "x@example.com"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAllUsersWithPagination() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("GetAllUsersWithPagination searches wildcards literally", func(t *testing.T) {
+		repo := newRepository(t)
+		for email, name := range map[string]string{"a@example.com": "100% Alice", "b@example.com": "1000 Bobs"} {
+			u := NewUser(email)
+			u.Name = name
+			if _, err := repo.Register(context.Background(), u); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+		}
+
+		for search, want := range map[string]int{"100%": 1, "100": 2, "_": 0, "\\": 0} {
+			result, err := repo.GetAllUsersWithPagination(context.Background(), pagination.Request{Search: search})
+			if err != nil {
+				t.Fatalf("GetAllUsersWithPagination(%q) error = %v", search, err)
+			}
+			if len(result.Data) != want {
+				t.Errorf("GetAllUsersWithPagination(%q) = %d users, want %d", search, len(result.Data), want)
+			}
+		}
+	})
+}
+
+func NewUser(email string) user.User {
+	return user.User{
+		Name:        "Test User",
+		Email:       email,
+		PhoneNumber: "081234567890",
+		Password:    user.NewPasswordFromTable("$2a$10$hashedpasswordplaceholder"),
+		Role:        user.NewRoleFromTable(user.RoleUser),
+		ImageUrl:    shared.NewURLFromTable("profile/default.png"),
+	}
+}
+
+func mustRegister(t *testing.T, repo user.Repository, email string) user.User {
+	t.Helper()
+
+	registered, err := repo.Register(context.Background(), NewUser(email))
+	if err != nil {
+		t.Fatalf("Register(%q) error = %v", email, err)
+	}
+	return registered
+}
+
+func userEmails(t *testing.T, data []any) []string {
+	t.Helper()
+
+	emails := make([]string, 0, len(data))
+	for _, item := range data {
+		u, ok := item.(user.User)
+		if !ok {
+			t.Fatalf("page returned %T, want user.User", item)
+		}
+		emails = append(emails, u.Email)
+	}
+	return emails
+}
+
+func count(n int64) *int64 {
+	return &n
+}
+
+func assertSameUser(t *testing.T, got, want user.User) {
+	t.Helper()
+
+	if got.ID != want.ID ||
+		got.Name != want.Name ||
+		got.Email != want.Email ||
+		got.PhoneNumber != want.PhoneNumber ||
+		got.Password != want.Password ||
+		got.Role != want.Role ||
+		got.ImageUrl != want.ImageUrl ||
+		got.IsVerified != want.IsVerified {
+		t.Errorf("got user %+v, want %+v", got, want)
+	}
+}
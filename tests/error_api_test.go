@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+)
+
+// errDatabaseDown stands in for a driver error whose text must not reach clients.
+var errDatabaseDown = errors.New("dial tcp 10.0.0.5:5432: connection refused")
+
+// flakyUserRepository fails lookups with errDatabaseDown once down is set.
+type flakyUserRepository struct {
+	user.Repository
+	down atomic.Bool
+}
+
+func (r *flakyUserRepository) GetUserByID(ctx context.Context, id string) (user.User, error) {
+	if r.down.Load() {
+		return user.User{}, errDatabaseDown
+	}
+	return r.Repository.GetUserByID(ctx, id)
+}
+
+func (r *flakyUserRepository) CheckEmail(ctx context.Context, email string) (user.User, bool, error) {
+	if r.down.Load() {
+		return user.User{}, false, errDatabaseDown
+	}
+	return r.Repository.CheckEmail(ctx, email)
+}
+
+func newFlakyHarness(t *testing.T) (*harness.Harness, *flakyUserRepository) {
+	repository := &flakyUserRepository{}
+	h := harness.New(t, func(injector do.Injector) {
+		repository.Repository = do.MustInvoke[user.Repository](injector)
+		do.OverrideValue[user.Repository](injector, repository)
+	})
+	return h, repository
+}
+
+func TestErrorHandling(t *testing.T) {
+	t.Run("reports the code of a domain error", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		h.PostJSON("/api/user/register", "", request.UserRegister{
+			Name:     "Alice",
+			Email:    "alice@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertFailure(http.StatusConflict, message.FailedRegister).
+			AssertError(user.ErrorEmailAlreadyExists.Error()).
+			AssertCode(user.ErrorEmailAlreadyExists.Code)
+	})
+
+	t.Run("reports binding errors as invalid requests", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.PostJSON("/api/user/login", "", map[string]string{}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody).
+			AssertCode(middleware.CodeInvalidRequest)
+	})
+
+	t.Run("hides the cause of an internal domain error", func(t *testing.T) {
+		h, repository := newFlakyHarness(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		repository.down.Store(true)
+
+		res := h.Get("/api/user/me", session.Token()).
+			AssertFailure(http.StatusInternalServerError, message.FailedGetUser).
+			AssertError(user.ErrorGetUserById.Error()).
+			AssertCode(user.ErrorGetUserById.Code)
+		assertNotLeaked(t, res)
+	})
+
+	t.Run("hides errors outside the catalog", func(t *testing.T) {
+		h, repository := newFlakyHarness(t)
+		repository.down.Store(true)
+
+		res := h.PostJSON("/api/user/register", "", request.UserRegister{
+			Name:     "Alice",
+			Email:    "alice@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertFailure(http.StatusInternalServerError, message.FailedRegister).
+			AssertError(http.StatusText(http.StatusInternalServerError)).
+			AssertCode(middleware.CodeInternal)
+		assertNotLeaked(t, res)
+	})
+
+	t.Run("hides the detail a domain error is wrapped with", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.Scanner.Err = errDatabaseDown
+
+		res := putAvatar(h, session.Token(), "avatar.png", harness.PNG(t, 128, 128)).
+			AssertFailure(http.StatusServiceUnavailable, message.FailedUpdateAvatar).
+			AssertError(file.ErrorScanFile.Error()).
+			AssertCode(file.ErrorScanFile.Code)
+		assertNotLeaked(t, res)
+	})
+
+	t.Run("answers HEAD requests without a body", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := headUpload(h, session.Token(), "/api/files/"+uuid.NewString()).AssertStatus(http.StatusNotFound)
+		if res.Body.Len() != 0 {
+			t.Errorf("body = %q, want none", res.Body.String())
+		}
+	})
+}
+
+func TestProblemDetails(t *testing.T) {
+	t.Run("lists every invalid field", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.PostJSON("/api/user/register", "", map[string]string{
+			"name":     "Alice",
+			"email":    "not-an-email",
+			"password": "short",
+		}).AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody).
+			AssertCode(middleware.CodeInvalidRequest)
+
+		if got := res.Header().Get("Content-Type"); got != response.ProblemContentType {
+			t.Errorf("Content-Type = %q, want %q", got, response.ProblemContentType)
+		}
+		want := []response.FieldError{
+			{Field: "email", Rule: "email", Message: "must be a valid email address"},
+			{Field: "password", Rule: "min", Message: "must be at least 8 characters long"},
+		}
+		if !reflect.DeepEqual(res.Problem.Errors, want) {
+			t.Errorf("errors = %+v, want %+v", res.Problem.Errors, want)
+		}
+		if res.Problem.Type != "about:blank" || res.Problem.Instance != "/api/user/register" {
+			t.Errorf("problem = %+v", res.Problem)
+		}
+		if res.Problem.TraceID == "" || res.Problem.TraceID != res.Header().Get(middleware.RequestIDHeader) {
+			t.Errorf("trace_id = %q, want the %s header %q", res.Problem.TraceID, middleware.RequestIDHeader, res.Header().Get(middleware.RequestIDHeader))
+		}
+	})
+
+	t.Run("reports a field of the wrong type", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.PostJSON("/api/user/login", "", map[string]any{"email": 5, "password": harness.DefaultPassword}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+
+		want := []response.FieldError{{Field: "email", Rule: "type", Message: "must be a string"}}
+		if !reflect.DeepEqual(res.Problem.Errors, want) {
+			t.Errorf("errors = %+v, want %+v", res.Problem.Errors, want)
+		}
+	})
+
+	t.Run("names form fields by their form key", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPut,
+			Path:   "/api/user/me/avatar",
+			Header: http.Header{"If-Match": {"*"}},
+			Token:  session.Token(),
+			Form:   map[string]string{"file_id": ""},
+		}).AssertStatus(http.StatusBadRequest)
+
+		want := []response.FieldError{{Field: "image", Rule: "required_without", Message: "is required"}}
+		if !reflect.DeepEqual(res.Problem.Errors, want) {
+			t.Errorf("errors = %+v, want %+v", res.Problem.Errors, want)
+		}
+	})
+
+	t.Run("propagates the trace id of the caller", func(t *testing.T) {
+		h := harness.New(t)
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Header: http.Header{"Traceparent": {"00-" + traceID + "-00f067aa0ba902b7-01"}},
+		}).AssertFailure(http.StatusUnauthorized, message.FailedProcessRequest).
+			AssertCode(middleware.ErrorTokenNotFound.Code)
+
+		if res.Problem.TraceID != traceID {
+			t.Errorf("trace_id = %q, want %q", res.Problem.TraceID, traceID)
+		}
+	})
+
+	t.Run("names the problem type under the configured base url", func(t *testing.T) {
+		t.Setenv("PROBLEM_TYPE_BASE_URL", "https://errors.example.test/")
+		h := harness.New(t)
+
+		res := h.Get("/api/user/me", "").AssertStatus(http.StatusUnauthorized)
+		if want := "https://errors.example.test/" + middleware.ErrorTokenNotFound.Code; res.Problem.Type != want {
+			t.Errorf("type = %q, want %q", res.Problem.Type, want)
+		}
+	})
+
+	t.Run("keeps the envelope when configured", func(t *testing.T) {
+		t.Setenv("ERROR_FORMAT", middleware.ErrorFormatEnvelope)
+		h := harness.New(t)
+
+		res := h.PostJSON("/api/user/login", "", map[string]string{}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody).
+			AssertCode(middleware.CodeInvalidRequest)
+
+		if res.Problem != nil || !strings.HasPrefix(res.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("Content-Type = %q, want the JSON envelope", res.Header().Get("Content-Type"))
+		}
+		if detail, _ := res.Envelope.Error.(string); !strings.Contains(detail, "required") {
+			t.Errorf("error = %v, want the validation message", res.Envelope.Error)
+		}
+	})
+}
+
+func assertNotLeaked(t *testing.T, res *harness.Response) {
+	t.Helper()
+
+	if strings.Contains(res.Body.String(), "connection refused") {
+		t.Errorf("response leaks the cause: %s", res.Body.String())
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/shared"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	t.Run("tags the current user with a weak ETag", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		etag := h.ETag(session.Token())
+		if !strings.HasPrefix(etag, `W/"1-`) {
+			t.Fatalf("ETag = %q, want a weak tag of version 1", etag)
+		}
+		if again := h.ETag(session.Token()); again != etag {
+			t.Errorf("ETag = %q, then %q, want the same tag", etag, again)
+		}
+
+		selected := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Token:  session.Token(),
+			Query:  url.Values{"fields": {"id"}},
+		}).AssertStatus(http.StatusOK).Header().Get("ETag")
+		if selected == etag {
+			t.Errorf("ETag of a field selection = %q, want it to differ from the full user", selected)
+		}
+	})
+
+	t.Run("keeps the tag of a user whose signed urls are signed again", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		putAvatar(h, session.Token(), "avatar.png", harness.PNG(t, 300, 200)).AssertStatus(http.StatusOK)
+		etag := h.ETag(session.Token())
+
+		h.Clock.Advance(time.Minute)
+		h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Token:  session.Token(),
+			Header: http.Header{"If-None-Match": {etag}},
+		}).AssertStatus(http.StatusNotModified)
+	})
+
+	t.Run("answers an unchanged user with 304", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		etag := h.ETag(session.Token())
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Token:  session.Token(),
+			Header: http.Header{"If-None-Match": {etag}},
+		}).AssertStatus(http.StatusNotModified)
+		if res.Body.Len() != 0 || res.Header().Get("ETag") != etag {
+			t.Errorf("304 body = %q, ETag = %q, want no body and %q", res.Body.String(), res.Header().Get("ETag"), etag)
+		}
+
+		h.UpdateUser(session.Token(), etag, request.UserUpdate{Name: "Alice Liddell"}).
+			AssertSuccess(http.StatusOK, message.SuccessUpdateUser)
+
+		res = h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Token:  session.Token(),
+			Header: http.Header{"If-None-Match": {etag}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+		if next := res.Header().Get("ETag"); !strings.HasPrefix(next, `W/"2-`) {
+			t.Errorf("ETag after the update = %q, want a weak tag of version 2", next)
+		}
+	})
+
+	t.Run("requires If-Match to change the user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.PatchJSON("/api/user/", session.Token(), request.UserUpdate{Name: "Alice Liddell"}).
+			AssertFailure(http.StatusPreconditionRequired, message.FailedPrecondition).
+			AssertCode(middleware.ErrorIfMatchRequired.Code)
+		h.Delete("/api/user/", session.Token()).
+			AssertFailure(http.StatusPreconditionRequired, message.FailedPrecondition).
+			AssertCode(middleware.ErrorIfMatchRequired.Code)
+		h.UpdateUser(session.Token(), `"latest"`, request.UserUpdate{Name: "Alice Liddell"}).
+			AssertFailure(http.StatusPreconditionFailed, message.FailedPrecondition).
+			AssertCode(middleware.ErrorIfMatchInvalid.Code)
+	})
+
+	t.Run("rejects a change based on a stale ETag", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		stale := h.ETag(session.Token())
+
+		res := h.UpdateUser(session.Token(), stale, request.UserUpdate{Name: "Alice Liddell"}).
+			AssertSuccess(http.StatusOK, message.SuccessUpdateUser)
+		current := res.Header().Get("ETag")
+
+		h.UpdateUser(session.Token(), stale, request.UserUpdate{Name: "Alice Stale"}).
+			AssertFailure(http.StatusPreconditionFailed, message.FailedUpdateUser).
+			AssertCode(shared.ErrorVersionConflict.Code)
+		h.DeleteUser(session.Token(), stale).
+			AssertFailure(http.StatusPreconditionFailed, message.FailedDeleteUser).
+			AssertCode(shared.ErrorVersionConflict.Code)
+
+		var me response.User
+		h.Get("/api/user/me", session.Token()).AssertStatus(http.StatusOK).DecodeData(&me)
+		if me.Name != "Alice Liddell" {
+			t.Errorf("stored name = %q, want %q", me.Name, "Alice Liddell")
+		}
+
+		h.DeleteUser(session.Token(), current).AssertSuccess(http.StatusOK, message.SuccessDeleteUser)
+	})
+	t.Run("makes avatar changes conditional", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		stale := h.ETag(session.Token())
+		avatar := func(etag string) *harness.Response {
+			req := harness.Request{
+				Method: http.MethodPut,
+				Path:   "/api/user/me/avatar",
+				Token:  session.Token(),
+				Files:  map[string]harness.File{"image": {Name: "avatar.png", Content: harness.PNG(t, 128, 128)}},
+			}
+			if etag != "" {
+				req.Header = http.Header{"If-Match": {etag}}
+			}
+			return h.Do(req)
+		}
+
+		avatar("").AssertFailure(http.StatusPreconditionRequired, message.FailedPrecondition).
+			AssertCode(middleware.ErrorIfMatchRequired.Code)
+		h.Delete("/api/user/me/avatar", session.Token()).
+			AssertFailure(http.StatusPreconditionRequired, message.FailedPrecondition).
+			AssertCode(middleware.ErrorIfMatchRequired.Code)
+
+		current := avatar(stale).AssertSuccess(http.StatusOK, message.SuccessUpdateAvatar).Header().Get("ETag")
+		if !strings.HasPrefix(current, `W/"2-`) {
+			t.Fatalf("ETag after setting the avatar = %q, want a weak tag of version 2", current)
+		}
+
+		avatar(stale).AssertFailure(http.StatusPreconditionFailed, message.FailedUpdateAvatar).
+			AssertCode(shared.ErrorVersionConflict.Code)
+		h.DeleteAvatar(session.Token(), stale).
+			AssertFailure(http.StatusPreconditionFailed, message.FailedDeleteAvatar).
+			AssertCode(shared.ErrorVersionConflict.Code)
+
+		res := h.DeleteAvatar(session.Token(), current).AssertSuccess(http.StatusOK, message.SuccessDeleteAvatar)
+		if next := res.Header().Get("ETag"); !strings.HasPrefix(next, `W/"3-`) {
+			t.Errorf("ETag after removing the avatar = %q, want a weak tag of version 3", next)
+		}
+	})
+}
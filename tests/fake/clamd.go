@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+)
+
+// Clamd speaks enough of the clamd protocol to answer zINSTREAM commands. It
+// reports EICAR as infected and streams over MaxStreamLength as an error.
+type Clamd struct {
+	listener net.Listener
+
+	mu              sync.Mutex
+	streams         [][]byte
+	MaxStreamLength int
+}
+
+func NewClamd(t *testing.T) *Clamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	c := &Clamd{listener: listener, MaxStreamLength: 25 << 20}
+	go c.serve()
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+// Address returns the tcp:// URL the daemon listens on.
+func (c *Clamd) Address() string {
+	return "tcp://" + c.listener.Addr().String()
+}
+
+// Close stops accepting connections.
+func (c *Clamd) Close() error {
+	return c.listener.Close()
+}
+
+// Streams returns the content of every stream received so far.
+func (c *Clamd) Streams() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]byte(nil), c.streams...)
+}
+
+func (c *Clamd) serve() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return
+		}
+		go c.handle(conn)
+	}
+}
+
+func (c *Clamd) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+	if command != "zINSTREAM\x00" {
+		_, _ = fmt.Fprintf(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+
+	var stream bytes.Buffer
+	var size [4]byte
+	for {
+		if _, err = io.ReadFull(reader, size[:]); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint32(size[:])
+		if length == 0 {
+			break
+		}
+		if stream.Len()+int(length) > c.MaxStreamLength {
+			_, _ = fmt.Fprintf(conn, "INSTREAM size limit exceeded. ERROR\x00")
+			return
+		}
+		if _, err = io.CopyN(&stream, reader, int64(length)); err != nil {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	c.streams = append(c.streams, stream.Bytes())
+	c.mu.Unlock()
+
+	if bytes.Contains(stream.Bytes(), []byte(EICAR)) {
+		_, _ = fmt.Fprintf(conn, "stream: %s FOUND\x00", EICARSignature)
+		return
+	}
+	_, _ = fmt.Fprintf(conn, "stream: OK\x00")
+}
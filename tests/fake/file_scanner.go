@@ -0,0 +1,56 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+// EICAR is the standard anti-virus test file, which every scanner reports as
+// infected.
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// EICARSignature is the signature scanners report for EICAR.
+const EICARSignature = "Eicar-Test-Signature"
+
+// FileScanner reports content containing EICAR as infected and everything
+// else as clean. Setting Err makes every scan fail with it.
+type FileScanner struct {
+	mu    sync.Mutex
+	scans int
+	Err   error
+}
+
+func NewFileScanner() *FileScanner {
+	return &FileScanner{}
+}
+
+func (s *FileScanner) Scan(_ context.Context, content io.Reader) (port.ScanResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scans++
+	if s.Err != nil {
+		return port.ScanResult{}, s.Err
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return port.ScanResult{}, err
+	}
+	if bytes.Contains(data, []byte(EICAR)) {
+		return port.ScanResult{Infected: true, Signature: EICARSignature}, nil
+	}
+	return port.ScanResult{}, nil
+}
+
+// Scans returns how many files have been scanned.
+func (s *FileScanner) Scans() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.scans
+}
@@ -0,0 +1,35 @@
+package fake
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator hands out 00000000-0000-4000-8000-000000000001,
+// 00000000-0000-4000-8000-000000000002, and so on.
+type IDGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func NewIDGenerator() *IDGenerator {
+	return &IDGenerator{}
+}
+
+func (g *IDGenerator) NewID() uuid.UUID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next++
+	return Sequential(g.next)
+}
+
+func Sequential(n uint64) uuid.UUID {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], n)
+	id[6] = 0x40
+	id[8] |= 0x80
+	return id
+}
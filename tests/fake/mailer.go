@@ -0,0 +1,53 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+)
+
+// Mailer keeps the mails it is asked to send instead of sending them. Setting
+// Err makes every send fail with it.
+type Mailer struct {
+	mu   sync.Mutex
+	sent []port.Mail
+	Err  error
+}
+
+func NewMailer() *Mailer {
+	return &Mailer{}
+}
+
+func (m *Mailer) Send(_ context.Context, mail port.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+// Sent returns the mails sent so far, oldest first.
+func (m *Mailer) Sent() []port.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]port.Mail(nil), m.sent...)
+}
+
+// SentTo returns the mails sent to address, oldest first.
+func (m *Mailer) SentTo(address string) []port.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var mails []port.Mail
+	for _, mail := range m.sent {
+		if mail.To == address {
+			mails = append(mails, mail)
+		}
+	}
+	return mails
+}
@@ -0,0 +1,293 @@
+package fake
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const s3LastModified = "Wed, 01 Jan 2025 09:00:00 GMT"
+
+type (
+	// S3 is an in-memory stand-in for the subset of the S3 API used by the
+	// storage adapter. Requests are path-style and signatures are not checked.
+	S3 struct {
+		*httptest.Server
+
+		mu         sync.Mutex
+		objects    map[string]S3Object
+		uploads    map[string]*s3Upload
+		nextUpload int
+		// CompletedMultipartUploads counts multipart uploads that were completed.
+		CompletedMultipartUploads int
+	}
+
+	S3Object struct {
+		Data   []byte
+		Header http.Header
+	}
+
+	s3Upload struct {
+		key    string
+		header http.Header
+		parts  map[int][]byte
+	}
+)
+
+func NewS3(t *testing.T) *S3 {
+	s := &S3{
+		objects: make(map[string]S3Object),
+		uploads: make(map[string]*s3Upload),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *S3) Object(bucket, key string) (S3Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[bucket+"/"+key]
+	return object, ok
+}
+
+func (s *S3) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *S3) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.nextUpload++
+		uploadID := strconv.Itoa(s.nextUpload)
+		s.uploads[uploadID] = &s3Upload{key: key, header: r.Header.Clone(), parts: make(map[int][]byte)}
+		bucket, object, _ := strings.Cut(key, "/")
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: object, UploadId: uploadID})
+
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			http.Error(w, "NoSuchUpload", http.StatusNotFound)
+			return
+		}
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, err := readBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		upload.parts[partNumber] = data
+		w.Header().Set("ETag", etag(data))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			http.Error(w, "NoSuchUpload", http.StatusNotFound)
+			return
+		}
+		numbers := make([]int, 0, len(upload.parts))
+		for number := range upload.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, upload.parts[number]...)
+		}
+		s.objects[upload.key] = S3Object{Data: data, Header: upload.header}
+		delete(s.uploads, query.Get("uploadId"))
+		s.CompletedMultipartUploads++
+
+		bucket, object, _ := strings.Cut(upload.key, "/")
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: object, ETag: etag(data)})
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		data, err := readBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = S3Object{Data: data, Header: r.Header.Clone()}
+		w.Header().Set("ETag", etag(data))
+
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.list(w, strings.TrimSuffix(key, "/"), query.Get("prefix"))
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := s.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+
+		data := object.Data
+		status := http.StatusOK
+		if start, end, ok := parseRange(r.Header.Get("Range"), len(data)); ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data = data[start : end+1]
+			status = http.StatusPartialContent
+		}
+
+		w.Header().Set("Content-Type", object.Header.Get("Content-Type"))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", s3LastModified)
+		w.Header().Set("ETag", etag(object.Data))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+
+	default:
+		http.Error(w, fmt.Sprintf("unsupported request %s %s", r.Method, r.URL), http.StatusNotImplemented)
+	}
+}
+
+func (s *S3) list(w http.ResponseWriter, bucket, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: bucket, Prefix: prefix}
+
+	for fullKey, object := range s.objects {
+		objectBucket, key, _ := strings.Cut(fullKey, "/")
+		if objectBucket != bucket || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: "2025-01-01T09:00:00.000Z",
+			ETag:         etag(object.Data),
+			Size:         len(object.Data),
+		})
+	}
+	sort.Slice(result.Contents, func(i, j int) bool {
+		return result.Contents[i].Key < result.Contents[j].Key
+	})
+	result.KeyCount = len(result.Contents)
+
+	writeXML(w, result)
+}
+
+// readBody strips the aws-chunked framing that clients use for streaming
+// signatures and trailing checksums.
+func readBody(r *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return data, nil
+	}
+
+	var decoded []byte
+	for len(data) > 0 {
+		line, rest, ok := strings.Cut(string(data), "\r\n")
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := strings.Cut(line, ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 {
+			break
+		}
+		decoded = append(decoded, rest[:size]...)
+		data = []byte(strings.TrimPrefix(rest[size:], "\r\n"))
+	}
+	return decoded, nil
+}
+
+func parseRange(header string, size int) (start, end int, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		return 0, 0, false
+	}
+	from, to, _ := strings.Cut(spec, "-")
+
+	start, err := strconv.Atoi(from)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end = size - 1
+	if to != "" {
+		if end, err = strconv.Atoi(to); err != nil {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code, resource string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string
+		Resource string
+	}{Code: code, Resource: resource})
+}
+
+func writeXML(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/fieldset"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+)
+
+func TestSparseFieldsets(t *testing.T) {
+	t.Run("selects the requested fields of the current user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/user/me",
+			Token:  session.Token(),
+			Query:  url.Values{"fields": {"id,name,image_url"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		var me map[string]json.RawMessage
+		res.DecodeData(&me)
+		if keys := sortedKeys(me); !slices.Equal(keys, []string{"id", "image_url", "name"}) {
+			t.Errorf("fields = %v, want id, image_url and name", keys)
+		}
+	})
+
+	t.Run("embeds the sessions of the current user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v2/user/me",
+			Token:  session.Token(),
+			Query:  url.Values{"fields": {"id"}, "include": {"sessions"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		var me struct {
+			ID       string           `json:"id"`
+			Sessions []map[string]any `json:"sessions"`
+		}
+		res.DecodeData(&me)
+		if me.ID != session.User.ID || len(me.Sessions) != 1 {
+			t.Fatalf("me = %+v, want the user with its session", me)
+		}
+		for _, s := range me.Sessions {
+			if _, ok := s["token"]; ok || s["id"] == nil || s["expires_at"] == nil {
+				t.Errorf("session = %v, want an id and expiry and no token", s)
+			}
+		}
+	})
+
+	t.Run("keeps the full user without a selection", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Get("/api/v1/user/me", session.Token()).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		var me map[string]json.RawMessage
+		res.DecodeData(&me)
+		if _, ok := me["sessions"]; ok || me["email"] == nil || me["is_verified"] == nil {
+			t.Errorf("fields = %v, want every user field and no sessions", sortedKeys(me))
+		}
+	})
+
+	t.Run("selects fields across the user list", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.RegisterUser("Bob", "bob@example.com", harness.DefaultPassword)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v2/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"fields": {"id,avatar"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+		var users []map[string]json.RawMessage
+		res.DecodeData(&users)
+		if len(users) != 2 {
+			t.Fatalf("got %d users, want 2", len(users))
+		}
+		for _, u := range users {
+			if keys := sortedKeys(u); !slices.Equal(keys, []string{"avatar", "id"}) {
+				t.Errorf("fields = %v, want avatar and id", keys)
+			}
+		}
+	})
+
+	t.Run("rejects unknown and sensitive fields", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		tests := map[string]struct {
+			path  string
+			query url.Values
+			id    string
+			code  string
+		}{
+			"password":         {"/api/v1/user/me", url.Values{"fields": {"id,password"}}, message.FailedGetUser, fieldset.ErrorFieldUnknown.Code},
+			"version 1 avatar": {"/api/v1/user/me", url.Values{"fields": {"avatar"}}, message.FailedGetUser, fieldset.ErrorFieldUnknown.Code},
+			"unknown relation": {"/api/v1/user/me", url.Values{"include": {"roles"}}, message.FailedGetUser, fieldset.ErrorRelationUnknown.Code},
+			"list sessions":    {"/api/v1/user/", url.Values{"include": {"sessions"}}, message.FailedGetAllUsers, fieldset.ErrorRelationUnknown.Code},
+			"list token":       {"/api/v2/user/", url.Values{"fields": {"refresh_token"}}, message.FailedGetAllUsers, fieldset.ErrorFieldUnknown.Code},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				h.Do(harness.Request{
+					Method: http.MethodGet,
+					Path:   tt.path,
+					Token:  session.Token(),
+					Query:  tt.query,
+				}).AssertFailure(http.StatusBadRequest, tt.id).
+					AssertCode(tt.code)
+			})
+		}
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/samber/do/v2"
+)
+
+func TestDownloadFile(t *testing.T) {
+	setup := func(t *testing.T) (*harness.Harness, string) {
+		h := harness.New(t)
+		storage := do.MustInvoke[port.FileStoragePort](h.Injector)
+
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "report.txt", "text/plain", []byte("quarterly report")), "docs/report.txt"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+		signedURL, err := storage.SignedURL(context.Background(), "docs/report.txt", time.Hour)
+		if err != nil {
+			t.Fatalf("SignedURL() error = %v", err)
+		}
+		return h, signedURL
+	}
+
+	t.Run("serves a file through a signed url", func(t *testing.T) {
+		h, signedURL := setup(t)
+
+		res := h.Fetch(signedURL).AssertStatus(http.StatusOK)
+		if res.Body.String() != "quarterly report" {
+			t.Errorf("body = %q", res.Body.String())
+		}
+		if got := res.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+			t.Errorf("Content-Type = %q", got)
+		}
+	})
+
+	t.Run("rejects a tampered signature", func(t *testing.T) {
+		h, signedURL := setup(t)
+
+		parsed, _ := url.Parse(signedURL)
+		query := parsed.Query()
+		query.Set("signature", "00"+query.Get("signature")[2:])
+
+		h.Do(harness.Request{Method: http.MethodGet, Path: parsed.Path, Query: query}).
+			AssertFailure(http.StatusForbidden, message.FailedDownloadFile).
+			AssertError(file.ErrorSignatureInvalid.Error())
+	})
+
+	t.Run("rejects a signature for another path", func(t *testing.T) {
+		h, signedURL := setup(t)
+
+		parsed, _ := url.Parse(signedURL)
+		h.Do(harness.Request{Method: http.MethodGet, Path: "/api/storage/docs/other.txt", Query: parsed.Query()}).
+			AssertFailure(http.StatusForbidden, message.FailedDownloadFile).
+			AssertError(file.ErrorSignatureInvalid.Error())
+	})
+
+	t.Run("rejects an expired url", func(t *testing.T) {
+		h, signedURL := setup(t)
+		h.Clock.Advance(time.Hour + time.Second)
+
+		h.Fetch(signedURL).
+			AssertFailure(http.StatusForbidden, message.FailedDownloadFile).
+			AssertError(file.ErrorSignatureExpired.Error())
+	})
+
+	t.Run("returns not found for a deleted file", func(t *testing.T) {
+		h, signedURL := setup(t)
+		if err := do.MustInvoke[port.FileStoragePort](h.Injector).Delete(context.Background(), "docs/report.txt"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		h.Fetch(signedURL).
+			AssertFailure(http.StatusNotFound, message.FailedDownloadFile).
+			AssertError(file.ErrorFileNotFound.Error())
+	})
+
+	t.Run("requires the signature parameters", func(t *testing.T) {
+		h, _ := setup(t)
+
+		h.Get("/api/storage/docs/report.txt", "").
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+}
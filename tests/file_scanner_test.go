@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/file_scanner"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+)
+
+func TestClamAVFileScanner(t *testing.T) {
+	t.Run("reports clean content", func(t *testing.T) {
+		clamd := fake.NewClamd(t)
+		scanner := newClamAVScanner(t, clamd.Address())
+
+		result, err := scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if result.Infected {
+			t.Errorf("Scan() = %+v, want clean", result)
+		}
+	})
+
+	t.Run("reports the signature of infected content", func(t *testing.T) {
+		clamd := fake.NewClamd(t)
+		scanner := newClamAVScanner(t, clamd.Address())
+
+		result, err := scanner.Scan(context.Background(), strings.NewReader(fake.EICAR))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if !result.Infected || result.Signature != fake.EICARSignature {
+			t.Errorf("Scan() = %+v, want infected with %s", result, fake.EICARSignature)
+		}
+	})
+
+	t.Run("streams large content in chunks", func(t *testing.T) {
+		clamd := fake.NewClamd(t)
+		scanner := newClamAVScanner(t, clamd.Address())
+		content := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+
+		if _, err := scanner.Scan(context.Background(), bytes.NewReader(content)); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+
+		streams := clamd.Streams()
+		if len(streams) != 1 || !bytes.Equal(streams[0], content) {
+			t.Errorf("clamd received %d streams, want the content once", len(streams))
+		}
+	})
+
+	t.Run("fails for content over the stream limit", func(t *testing.T) {
+		clamd := fake.NewClamd(t)
+		clamd.MaxStreamLength = 1 << 10
+		scanner := newClamAVScanner(t, clamd.Address())
+
+		_, err := scanner.Scan(context.Background(), bytes.NewReader(make([]byte, 1<<20)))
+		if err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+			t.Fatalf("Scan() error = %v, want the size limit error", err)
+		}
+	})
+
+	t.Run("fails when clamd is unreachable", func(t *testing.T) {
+		clamd := fake.NewClamd(t)
+		address := clamd.Address()
+		scanner := newClamAVScanner(t, address)
+		_ = clamd.Close()
+
+		if _, err := scanner.Scan(context.Background(), strings.NewReader("hello")); err == nil {
+			t.Fatal("Scan() error = nil, want an error")
+		}
+	})
+
+	t.Run("rejects an unsupported address", func(t *testing.T) {
+		_, err := file_scanner.NewClamAVAdapter(file_scanner.ClamAVConfig{Address: "http://localhost:3310"})
+		if err == nil {
+			t.Fatal("NewClamAVAdapter() error = nil, want an error")
+		}
+	})
+}
+
+func newClamAVScanner(t *testing.T, address string) port.FileScannerPort {
+	t.Helper()
+
+	scanner, err := file_scanner.NewClamAVAdapter(file_scanner.ClamAVConfig{Address: address, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewClamAVAdapter() error = %v", err)
+	}
+	return scanner
+}
@@ -0,0 +1,474 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/file_storage"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/url_signer"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+)
+
+const testBucket = "avatars"
+
+func TestS3FileStorage(t *testing.T) {
+	t.Run("uploads small files in a single request under the prefix", func(t *testing.T) {
+		server, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		content := []byte("hello avatar")
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "avatar.png", "image/png", content), "profile/avatar.png"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+
+		object, ok := server.Object(testBucket, "uploads/profile/avatar.png")
+		if !ok {
+			t.Fatalf("object not stored, have %v", server.Keys())
+		}
+		if !bytes.Equal(object.Data, content) {
+			t.Errorf("stored %q, want %q", object.Data, content)
+		}
+		if got := object.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("Content-Type = %q, want image/png", got)
+		}
+		if server.CompletedMultipartUploads != 0 {
+			t.Errorf("CompletedMultipartUploads = %d, want 0", server.CompletedMultipartUploads)
+		}
+	})
+
+	t.Run("uses multipart uploads for files larger than the part size", func(t *testing.T) {
+		server, storage := newS3Storage(t, file_storage.S3Config{PartSize: 5 << 20})
+
+		content := bytes.Repeat([]byte("0123456789abcdef"), (11<<20)/16)
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "video.mp4", "video/mp4", content), "videos/big.mp4"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+
+		object, ok := server.Object(testBucket, "videos/big.mp4")
+		if !ok {
+			t.Fatalf("object not stored, have %v", server.Keys())
+		}
+		if !bytes.Equal(object.Data, content) {
+			t.Errorf("stored %d bytes, want %d identical bytes", len(object.Data), len(content))
+		}
+		if server.CompletedMultipartUploads != 1 {
+			t.Errorf("CompletedMultipartUploads = %d, want 1", server.CompletedMultipartUploads)
+		}
+	})
+
+	t.Run("requests server-side encryption", func(t *testing.T) {
+		tests := []struct {
+			config file_storage.S3Config
+			header string
+			want   string
+		}{
+			{
+				config: file_storage.S3Config{Encryption: file_storage.EncryptionS3},
+				header: "X-Amz-Server-Side-Encryption",
+				want:   "AES256",
+			},
+			{
+				config: file_storage.S3Config{Encryption: file_storage.EncryptionKMS, KMSKeyID: "key-1"},
+				header: "X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
+				want:   "key-1",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.config.Encryption, func(t *testing.T) {
+				server, storage := newS3Storage(t, tt.config)
+
+				if err := storage.UploadFile(context.Background(), newFileHeader(t, "a.txt", "text/plain", []byte("secret")), "a.txt"); err != nil {
+					t.Fatalf("UploadFile() error = %v", err)
+				}
+
+				object, _ := server.Object(testBucket, "a.txt")
+				if got := object.Header.Get(tt.header); got != tt.want {
+					t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("deletes files", func(t *testing.T) {
+		server, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "a.txt", "text/plain", []byte("a")), "a.txt"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+		if err := storage.Delete(context.Background(), "a.txt"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		if _, ok := server.Object(testBucket, "uploads/a.txt"); ok {
+			t.Error("object still stored after Delete()")
+		}
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		server, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := storage.Put(ctx, "a.txt", strings.NewReader("a"), 1, "text/plain"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Put() error = %v, want %v", err, context.Canceled)
+		}
+
+		if _, ok := server.Object(testBucket, "uploads/a.txt"); ok {
+			t.Error("object stored although the context was cancelled")
+		}
+	})
+
+	t.Run("reads files back", func(t *testing.T) {
+		_, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		content := []byte("hello avatar")
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "avatar.png", "image/png", content), "profile/avatar.png"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+
+		info, err := storage.Stat(context.Background(), "profile/avatar.png")
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Path != "profile/avatar.png" || info.Size != int64(len(content)) || info.ContentType != "image/png" {
+			t.Errorf("Stat() = %+v", info)
+		}
+
+		reader, err := storage.Open(context.Background(), "profile/avatar.png")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer reader.Close()
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Errorf("Open() read %q, want %q", got, content)
+		}
+	})
+
+	t.Run("reports missing files as not found", func(t *testing.T) {
+		_, storage := newS3Storage(t, file_storage.S3Config{})
+
+		if _, err := storage.Stat(context.Background(), "missing.png"); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Errorf("Stat() error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+		if _, err := storage.Open(context.Background(), "missing.png"); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Errorf("Open() error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+	})
+
+	t.Run("lists files under a prefix", func(t *testing.T) {
+		_, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		for _, name := range []string{"profile/b.png", "profile/a.png", "other/c.png"} {
+			if err := storage.UploadFile(context.Background(), newFileHeader(t, name, "image/png", []byte(name)), name); err != nil {
+				t.Fatalf("UploadFile(%q) error = %v", name, err)
+			}
+		}
+
+		files, err := storage.List(context.Background(), "profile/")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		assertPaths(t, files, "profile/a.png", "profile/b.png")
+	})
+
+	t.Run("presigns download urls", func(t *testing.T) {
+		server, storage := newS3Storage(t, file_storage.S3Config{Prefix: "uploads"})
+
+		signedURL, err := storage.SignedURL(context.Background(), "profile/avatar.png", time.Hour)
+		if err != nil {
+			t.Fatalf("SignedURL() error = %v", err)
+		}
+
+		parsed, err := url.Parse(signedURL)
+		if err != nil {
+			t.Fatalf("SignedURL() returned an invalid url %q: %v", signedURL, err)
+		}
+		if !strings.HasPrefix(signedURL, server.URL+"/"+testBucket+"/uploads/profile/avatar.png?") {
+			t.Errorf("SignedURL() = %q", signedURL)
+		}
+		if got := parsed.Query().Get("X-Amz-Expires"); got != "3600" {
+			t.Errorf("X-Amz-Expires = %q, want 3600", got)
+		}
+		if parsed.Query().Get("X-Amz-Signature") == "" {
+			t.Error("SignedURL() is missing X-Amz-Signature")
+		}
+	})
+
+	t.Run("links SSE-C objects to the signed download route", func(t *testing.T) {
+		clock := fake.NewClock(time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC))
+		signer := url_signer.NewHMACAdapter([]byte("secret"), clock)
+		storage, err := file_storage.NewS3Adapter(file_storage.S3Config{
+			Bucket:          testBucket,
+			Prefix:          "uploads",
+			Encryption:      file_storage.EncryptionSSEC,
+			CustomerKey:     bytes.Repeat([]byte("k"), 32),
+			DownloadBaseURL: "http://example.test/api/v1/storage/",
+		}, signer, clock)
+		if err != nil {
+			t.Fatalf("NewS3Adapter() error = %v", err)
+		}
+
+		signedURL, err := storage.SignedURL(context.Background(), "profile/avatar.png", time.Hour)
+		if err != nil {
+			t.Fatalf("SignedURL() error = %v", err)
+		}
+
+		parsed, err := url.Parse(signedURL)
+		if err != nil {
+			t.Fatalf("SignedURL() returned an invalid url %q: %v", signedURL, err)
+		}
+		if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != "http://example.test/api/v1/storage/profile/avatar.png" {
+			t.Errorf("SignedURL() location = %q", got)
+		}
+		expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+		if err != nil {
+			t.Fatalf("SignedURL() has no expiry: %v", err)
+		}
+		if err = signer.Verify("profile/avatar.png", time.Unix(expires, 0), parsed.Query().Get("signature")); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	})
+
+	t.Run("requires a download base url for SSE-C", func(t *testing.T) {
+		_, err := file_storage.NewS3Adapter(file_storage.S3Config{
+			Bucket:      testBucket,
+			Encryption:  file_storage.EncryptionSSEC,
+			CustomerKey: bytes.Repeat([]byte("k"), 32),
+		}, nil, nil)
+		if err == nil {
+			t.Fatal("NewS3Adapter() error = nil, want an error")
+		}
+	})
+
+	t.Run("rejects a missing bucket", func(t *testing.T) {
+		if _, err := file_storage.NewS3Adapter(file_storage.S3Config{}, nil, nil); err == nil {
+			t.Fatal("NewS3Adapter() error = nil, want an error")
+		}
+	})
+}
+
+func TestLocalFileStorage(t *testing.T) {
+	newStorage := func(t *testing.T) (port.FileStoragePort, *fake.Clock) {
+		clock := fake.NewClock(time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC))
+		signer := url_signer.NewHMACAdapter([]byte("secret"), clock)
+		storage := file_storage.NewLocalAdapter(file_storage.LocalConfig{
+			Root:    t.TempDir(),
+			BaseURL: "http://example.test/api/storage/",
+		}, signer, clock)
+		return storage, clock
+	}
+
+	t.Run("stores, stats, opens and deletes files", func(t *testing.T) {
+		storage, _ := newStorage(t)
+
+		content := []byte("hello avatar")
+		if err := storage.UploadFile(context.Background(), newFileHeader(t, "avatar.png", "image/png", content), "profile/avatar.png"); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+
+		info, err := storage.Stat(context.Background(), "profile/avatar.png")
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Size != int64(len(content)) || info.ContentType != "image/png" {
+			t.Errorf("Stat() = %+v", info)
+		}
+
+		reader, err := storage.Open(context.Background(), "profile/avatar.png")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Errorf("Open() read %q, want %q", got, content)
+		}
+
+		if err = storage.Delete(context.Background(), "profile/avatar.png"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err = storage.Stat(context.Background(), "profile/avatar.png"); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Errorf("Stat() after Delete() error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+		if _, err = storage.Open(context.Background(), "profile/avatar.png"); !errors.Is(err, file.ErrorFileNotFound) {
+			t.Errorf("Open() after Delete() error = %v, want %v", err, file.ErrorFileNotFound)
+		}
+	})
+
+	t.Run("lists stored files", func(t *testing.T) {
+		storage, _ := newStorage(t)
+
+		files, err := storage.List(context.Background(), "")
+		if err != nil {
+			t.Fatalf("List() on an empty root error = %v", err)
+		}
+		assertPaths(t, files)
+
+		for _, name := range []string{"profile/b.png", "profile/a.png"} {
+			if err = storage.UploadFile(context.Background(), newFileHeader(t, name, "image/png", []byte(name)), name); err != nil {
+				t.Fatalf("UploadFile(%q) error = %v", name, err)
+			}
+		}
+
+		files, err = storage.List(context.Background(), "profile/")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		assertPaths(t, files, "profile/a.png", "profile/b.png")
+	})
+
+	t.Run("stores files at their path", func(t *testing.T) {
+		root := t.TempDir()
+		clock := fake.NewClock(time.Now())
+		storage := file_storage.NewLocalAdapter(file_storage.LocalConfig{Root: root}, url_signer.NewHMACAdapter([]byte("secret"), clock), clock)
+
+		if err := storage.Put(context.Background(), "profile/abc/64.png", strings.NewReader("thumb"), 5, "image/png"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		if err := storage.Put(context.Background(), "../../escape.txt", strings.NewReader("escape"), 6, "text/plain"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		for _, name := range []string{"profile/abc/64.png", "escape.txt"} {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); err != nil {
+				t.Errorf("expected %s inside the root: %v", name, err)
+			}
+		}
+
+		if err := storage.Delete(context.Background(), "profile/abc/64.png"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "profile")); !os.IsNotExist(err) {
+			t.Errorf("empty directories were left behind after Delete(): %v", err)
+		}
+	})
+
+	t.Run("reads and deletes files from the legacy layout", func(t *testing.T) {
+		root := t.TempDir()
+		clock := fake.NewClock(time.Now())
+		storage := file_storage.NewLocalAdapter(file_storage.LocalConfig{Root: root}, url_signer.NewHMACAdapter([]byte("secret"), clock), clock)
+
+		legacyDir := filepath.Join(root, "old.png")
+		if err := os.MkdirAll(legacyDir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(legacyDir, "old.png"), []byte("legacy"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := storage.Stat(context.Background(), "profile/old.png")
+		if err != nil || info.Size != int64(len("legacy")) {
+			t.Fatalf("Stat() = %+v, %v", info, err)
+		}
+		if err = storage.Delete(context.Background(), "profile/old.png"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err = os.Stat(legacyDir); !os.IsNotExist(err) {
+			t.Errorf("legacy directory left behind after Delete(): %v", err)
+		}
+	})
+
+	t.Run("signs urls that expire", func(t *testing.T) {
+		storage, clock := newStorage(t)
+
+		signedURL, err := storage.SignedURL(context.Background(), "profile/my avatar.png", time.Hour)
+		if err != nil {
+			t.Fatalf("SignedURL() error = %v", err)
+		}
+
+		parsed, err := url.Parse(signedURL)
+		if err != nil {
+			t.Fatalf("SignedURL() returned an invalid url %q: %v", signedURL, err)
+		}
+		if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != "http://example.test/api/storage/profile/my avatar.png" {
+			t.Errorf("SignedURL() location = %q", got)
+		}
+		if want := strconv.FormatInt(clock.Now().Add(time.Hour).Unix(), 10); parsed.Query().Get("expires") != want {
+			t.Errorf("expires = %q, want %q", parsed.Query().Get("expires"), want)
+		}
+		if parsed.Query().Get("signature") == "" {
+			t.Error("SignedURL() is missing the signature")
+		}
+	})
+}
+
+func assertPaths(t *testing.T, files []port.FileInfo, want ...string) {
+	t.Helper()
+
+	got := make([]string, 0, len(files))
+	for _, info := range files {
+		got = append(got, info.Path)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
+
+func newS3Storage(t *testing.T, config file_storage.S3Config) (*fake.S3, port.FileStoragePort) {
+	t.Helper()
+
+	server := fake.NewS3(t)
+	config.Bucket = testBucket
+	config.Endpoint = server.URL
+	config.Region = "us-east-1"
+	config.AccessKey = "access"
+	config.SecretKey = "secret"
+	config.PathStyle = true
+
+	clock := fake.NewClock(time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC))
+	storage, err := file_storage.NewS3Adapter(config, url_signer.NewHMACAdapter([]byte("secret"), clock), clock)
+	if err != nil {
+		t.Fatalf("NewS3Adapter() error = %v", err)
+	}
+	return server, storage
+}
+
+func newFileHeader(t *testing.T, filename, contentType string, content []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("failed to create part: %v", err)
+	}
+	if _, err = part.Write(content); err != nil {
+		t.Fatalf("failed to write part: %v", err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/", &body)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err = req.ParseMultipartForm(32 << 20); err != nil {
+		t.Fatalf("failed to parse multipart form: %v", err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/command"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/scaffold"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := scaffold.ParseFields("title:string, body:text,price:float,stock:int,active:bool,owner_id:string,due_at:time")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.GoName()+" "+field.GoType())
+	}
+	want := "Title string,Body string,Price float64,Stock int64,Active bool,OwnerID string,DueAt time.Time"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("fields = %s, want %s", got, want)
+	}
+
+	tests := map[string]struct {
+		spec string
+		want error
+	}{
+		"missing type":  {"title", scaffold.ErrorFieldInvalid},
+		"bad name":      {"Title:string", scaffold.ErrorFieldInvalid},
+		"unknown type":  {"title:varchar", scaffold.ErrorFieldType},
+		"reserved name": {"created_at:time", scaffold.ErrorFieldReserved},
+		"repeated name": {"title:string,title:text", scaffold.ErrorFieldRepeated},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := scaffold.ParseFields(tt.spec); !errors.Is(err, tt.want) {
+				t.Errorf("ParseFields(%q) error = %v, want %v", tt.spec, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateModule(t *testing.T) {
+	root := ".."
+
+	t.Run("rejects invalid and taken names", func(t *testing.T) {
+		for _, name := range []string{"Product", "type", "module", "blog-post"} {
+			if _, err := scaffold.NewModule(root, name, nil); !errors.Is(err, scaffold.ErrorModuleName) {
+				t.Errorf("NewModule(%q) error = %v, want %v", name, err, scaffold.ErrorModuleName)
+			}
+		}
+
+		m, err := scaffold.NewModule(root, "user", nil)
+		if err != nil {
+			t.Fatalf("NewModule() error = %v", err)
+		}
+		if _, err = scaffold.Plan(root, m); !errors.Is(err, scaffold.ErrorModuleExists) {
+			t.Errorf("Plan() error = %v, want %v", err, scaffold.ErrorModuleExists)
+		}
+	})
+
+	t.Run("writes the module and registers it", func(t *testing.T) {
+		target := t.TempDir()
+		copyFiles(t, root, target, "go.mod", "platform/provider/modules.go",
+			"internal/presentation/message/locales/en.json", "internal/presentation/message/locales/id.json")
+
+		if err := command.Generate(target, []string{"module", "product", "--fields", "title:string,price:float"}); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		for _, path := range []string{
+			"internal/domain/product/entity.go",
+			"internal/infrastructure/database/repository/product.go",
+			"internal/application/service/product_service.go",
+			"internal/presentation/route/product/router.go",
+			"platform/provider/product/module.go",
+		} {
+			if _, err := os.Stat(filepath.Join(target, path)); err != nil {
+				t.Errorf("%s was not written: %v", path, err)
+			}
+		}
+
+		modules, _ := os.ReadFile(filepath.Join(target, "platform/provider/modules.go"))
+		if !strings.Contains(string(modules), `"github.com/fawwasaldy/gin-clean-architecture/platform/provider/product"`) {
+			t.Errorf("modules.go does not import the module:\n%s", modules)
+		}
+		for _, locale := range []string{"en", "id"} {
+			var catalog map[string]any
+			content, _ := os.ReadFile(filepath.Join(target, "internal/presentation/message/locales", locale+".json"))
+			if err := json.Unmarshal(content, &catalog); err != nil {
+				t.Fatalf("%s catalog is not JSON: %v", locale, err)
+			}
+			if catalog["success_get_all_products"] != "Successfully retrieved all products" {
+				t.Errorf("%s catalog misses the module messages", locale)
+			}
+		}
+
+		if err := command.Generate(target, []string{"module", "product"}); !errors.Is(err, scaffold.ErrorModuleExists) {
+			t.Errorf("second Generate() error = %v, want %v", err, scaffold.ErrorModuleExists)
+		}
+		if err := command.Generate(target, []string{"product"}); !errors.Is(err, command.ErrorGenerateUsage) {
+			t.Errorf("Generate() without module error = %v, want %v", err, command.ErrorGenerateUsage)
+		}
+	})
+
+	t.Run("generates code that builds and passes its tests", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("builds the whole tree")
+		}
+
+		fields, err := scaffold.ParseFields("title:string,body:text,views:int,rating:float,published:bool,published_at:time,author_id:string")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := scaffold.NewModule(root, "blog_post", fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := scaffold.Plan(root, m)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		// The overlay adds the generated files to the build without touching
+		// the tree.
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		staged := t.TempDir()
+		overlay := map[string]map[string]string{"Replace": {}}
+		for path, content := range files {
+			stagedPath := filepath.Join(staged, strings.ReplaceAll(path, "/", "_"))
+			if err := os.WriteFile(stagedPath, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			overlay["Replace"][filepath.Join(absRoot, path)] = stagedPath
+		}
+		overlayPath := filepath.Join(staged, "overlay.json")
+		content, _ := json.Marshal(overlay)
+		if err := os.WriteFile(overlayPath, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := exec.Command("go", "build", "-overlay", overlayPath, "./...")
+		cmd.Dir = absRoot
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go build failed: %v\n%s", err, output)
+		}
+
+		for _, path := range []string{"tests/blog_post_service_test.go", "tests/blog_post_api_test.go"} {
+			if _, ok := files[path]; !ok {
+				t.Errorf("%s was not generated", path)
+			}
+		}
+		cmd = exec.Command("go", "test", "-overlay", overlayPath, "-vet=off", "-count=1", "-run", "^TestBlogPost", "-v", "./tests/")
+		cmd.Dir = absRoot
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go test failed: %v\n%s", err, output)
+		}
+		for _, test := range []string{"TestBlogPostService", "TestBlogPostAPI"} {
+			if !strings.Contains(string(output), "--- PASS: "+test+" ") {
+				t.Errorf("%s did not run:\n%s", test, output)
+			}
+		}
+	})
+}
+
+func copyFiles(t *testing.T, from, to string, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(from, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(to, path)
+		if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(target, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
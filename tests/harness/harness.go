@@ -0,0 +1,267 @@
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/file_storage"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/memory"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/provider"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type (
+	Option func(injector do.Injector)
+
+	Harness struct {
+		t        *testing.T
+		Injector do.Injector
+		Engine   *gin.Engine
+		handler  http.Handler
+		Clock    *fake.Clock
+		IDs      *fake.IDGenerator
+		Scanner  *fake.FileScanner
+		Mailer   *fake.Mailer
+		// StorageRoot is the temporary directory backing port.FileStoragePort.
+		StorageRoot string
+	}
+
+	Request struct {
+		Method string
+		Path   string
+		Query  url.Values
+		Token  string
+		Header http.Header
+		JSON   any
+		Form   map[string]string
+		Files  map[string]File
+		// Body is sent as is; set its Content-Type through Header.
+		Body []byte
+	}
+
+	File struct {
+		Name    string
+		Content []byte
+	}
+)
+
+// Epoch is the time the harness clock starts at.
+var Epoch = time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// BaseURL is the origin used for URLs generated by the harness storage.
+const BaseURL = "http://example.test"
+
+// New boots the real router against an injector whose database-backed
+// dependencies are replaced by in-memory implementations and whose clock, ID
+// generator, file scanner and mailer are fakes. Options run last and may override anything else.
+func New(t *testing.T, options ...Option) *Harness {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	storageRoot := t.TempDir()
+	clock := fake.NewClock(Epoch)
+	ids := fake.NewIDGenerator()
+	scanner := fake.NewFileScanner()
+	mailer := fake.NewMailer()
+	injector := do.New()
+	provider.RegisterDependencies(injector)
+
+	do.OverrideValue[port.ClockPort](injector, clock)
+	do.OverrideValue[port.IDGeneratorPort](injector, ids)
+	do.OverrideValue[port.FileScannerPort](injector, scanner)
+	do.OverrideValue[port.MailerPort](injector, mailer)
+	do.OverrideValue[user.Repository](injector, memory.NewUserRepository(clock, ids, do.MustInvoke[*pagination.CursorCodec](injector)))
+	do.OverrideValue[refresh_token.Repository](injector, memory.NewRefreshTokenRepository(clock, ids))
+	do.OverrideValue[file.Repository](injector, memory.NewFileRepository(clock, ids))
+	do.OverrideValue[file.UploadRepository](injector, memory.NewUploadRepository(clock, ids))
+	do.OverrideValue[idempotency.Repository](injector, memory.NewIdempotencyRepository(clock, ids))
+	do.OverrideValue[application.UnitOfWork](injector, memory.NewUnitOfWork())
+	do.Override(injector, func(do.Injector) (*gorm.DB, error) {
+		return openDatabase(t)
+	})
+	do.OverrideValue[port.FileStoragePort](injector, file_storage.NewLocalAdapter(file_storage.LocalConfig{
+		Root:    storageRoot,
+		BaseURL: BaseURL + file_storage.DownloadPath,
+	}, do.MustInvoke[port.URLSignerPort](injector), clock))
+
+	for _, option := range options {
+		option(injector)
+	}
+
+	engine := gin.New()
+	route.UseGlobalMiddleware(engine, injector)
+	do.ProvideValue(injector, engine)
+
+	route.RegisterRoutes(injector)
+
+	t.Cleanup(func() {
+		_ = injector.Shutdown()
+	})
+
+	return &Harness{
+		t:           t,
+		Injector:    injector,
+		Engine:      engine,
+		handler:     route.Handler(engine),
+		Clock:       clock,
+		IDs:         ids,
+		Scanner:     scanner,
+		Mailer:      mailer,
+		StorageRoot: storageRoot,
+	}
+}
+
+// openDatabase backs the repositories of modules that have no in-memory
+// implementation, such as freshly generated ones, with a migrated in-memory
+// SQLite database.
+func openDatabase(t *testing.T) (*gorm.DB, error) {
+	db, err := config.OpenSQLite(config.SQLiteMemory, &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		config.CloseDatabaseConnection(db)
+	})
+
+	if err = migration.Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func WithValue[T any](value T) Option {
+	return func(injector do.Injector) {
+		do.OverrideValue(injector, value)
+	}
+}
+
+func (h *Harness) Do(req Request) *Response {
+	h.t.Helper()
+
+	target := req.Path
+	if len(req.Query) > 0 {
+		target += "?" + req.Query.Encode()
+	}
+
+	body, contentType := h.encodeBody(req)
+	httpReq := httptest.NewRequest(req.Method, target, body)
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if req.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+req.Token)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.handler.ServeHTTP(recorder, httpReq)
+
+	return newResponse(h.t, req, recorder)
+}
+
+func (h *Harness) Get(path, token string) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodGet, Path: path, Token: token})
+}
+
+// Fetch requests a fully qualified URL produced by the application, such as a
+// signed storage URL.
+func (h *Harness) Fetch(rawURL string) *Response {
+	h.t.Helper()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		h.t.Fatalf("invalid url %q: %v", rawURL, err)
+	}
+	return h.Do(Request{Method: http.MethodGet, Path: parsed.EscapedPath(), Query: parsed.Query()})
+}
+
+func (h *Harness) PostJSON(path, token string, body any) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodPost, Path: path, Token: token, JSON: body})
+}
+
+func (h *Harness) PatchJSON(path, token string, body any) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodPatch, Path: path, Token: token, JSON: body})
+}
+
+func (h *Harness) Delete(path, token string) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodDelete, Path: path, Token: token})
+}
+
+func (h *Harness) encodeBody(req Request) (io.Reader, string) {
+	h.t.Helper()
+
+	switch {
+	case req.Body != nil:
+		return bytes.NewReader(req.Body), ""
+
+	case req.JSON != nil:
+		raw, err := json.Marshal(req.JSON)
+		if err != nil {
+			h.t.Fatalf("failed to encode request body: %v", err)
+		}
+		return bytes.NewReader(raw), "application/json"
+
+	case req.Form != nil || req.Files != nil:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for key, value := range req.Form {
+			if err := writer.WriteField(key, value); err != nil {
+				h.t.Fatalf("failed to write form field %q: %v", key, err)
+			}
+		}
+		for field, file := range req.Files {
+			part, err := writer.CreateFormFile(field, file.Name)
+			if err != nil {
+				h.t.Fatalf("failed to create form file %q: %v", field, err)
+			}
+			if _, err = part.Write(file.Content); err != nil {
+				h.t.Fatalf("failed to write form file %q: %v", field, err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			h.t.Fatalf("failed to close multipart body: %v", err)
+		}
+		return &buf, writer.FormDataContentType()
+
+	default:
+		return nil, ""
+	}
+}
+
+func (r Request) String() string {
+	return fmt.Sprintf("%s %s", r.Method, r.Path)
+}
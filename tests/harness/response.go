@@ -0,0 +1,169 @@
+package harness
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/i18n"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+)
+
+type (
+	// Envelope mirrors response.Response with Data and Meta left undecoded.
+	Envelope struct {
+		Status  bool            `json:"status"`
+		Message string          `json:"message"`
+		Error   any             `json:"error,omitempty"`
+		Code    string          `json:"code,omitempty"`
+		Data    json.RawMessage `json:"data,omitempty"`
+		Meta    json.RawMessage `json:"meta,omitempty"`
+	}
+
+	Response struct {
+		*httptest.ResponseRecorder
+		Envelope Envelope
+		// Problem is set instead of Envelope for application/problem+json
+		// responses.
+		Problem *response.Problem
+
+		t       *testing.T
+		request Request
+	}
+)
+
+func newResponse(t *testing.T, req Request, recorder *httptest.ResponseRecorder) *Response {
+	res := &Response{
+		ResponseRecorder: recorder,
+		t:                t,
+		request:          req,
+	}
+
+	if recorder.Body.Len() > 0 && strings.HasPrefix(recorder.Header().Get("Content-Type"), response.ProblemContentType) {
+		res.Problem = new(response.Problem)
+		if err := json.Unmarshal(recorder.Body.Bytes(), res.Problem); err != nil {
+			t.Fatalf("%s: response is not a problem: %v\n%s", req, err, recorder.Body.String())
+		}
+		return res
+	}
+
+	if recorder.Body.Len() > 0 && strings.Contains(recorder.Header().Get("Content-Type"), "json") {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res.Envelope); err != nil {
+			t.Fatalf("%s: response is not a JSON envelope: %v\n%s", req, err, recorder.Body.String())
+		}
+	}
+
+	return res
+}
+
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+
+	if r.Code != code {
+		r.t.Fatalf("%s: status = %d, want %d\n%s", r.request, r.Code, code, r.Body.String())
+	}
+	return r
+}
+
+// AssertSuccess checks the status and message of a success, given as a
+// message ID and compared in the default locale.
+func (r *Response) AssertSuccess(code int, id string) *Response {
+	r.t.Helper()
+
+	message := Text(id)
+	r.AssertStatus(code)
+	if !r.Envelope.Status {
+		r.t.Errorf("%s: status flag = false, want true", r.request)
+	}
+	if r.Envelope.Message != message {
+		r.t.Errorf("%s: message = %q, want %q", r.request, r.Envelope.Message, message)
+	}
+	if r.Envelope.Error != nil {
+		r.t.Errorf("%s: error = %v, want none", r.request, r.Envelope.Error)
+	}
+	return r
+}
+
+// AssertFailure checks the status and message of an error, which is the title
+// of a problem or the message of an envelope. The message is given as a
+// message ID and compared in the default locale.
+func (r *Response) AssertFailure(code int, id string) *Response {
+	r.t.Helper()
+
+	message := Text(id)
+	r.AssertStatus(code)
+	if r.Problem != nil {
+		if r.Problem.Status != code {
+			r.t.Errorf("%s: problem status = %d, want %d", r.request, r.Problem.Status, code)
+		}
+		if r.Problem.Title != message {
+			r.t.Errorf("%s: title = %q, want %q", r.request, r.Problem.Title, message)
+		}
+		if r.Problem.Detail == "" {
+			r.t.Errorf("%s: detail is empty", r.request)
+		}
+		return r
+	}
+	if r.Envelope.Status {
+		r.t.Errorf("%s: status flag = true, want false", r.request)
+	}
+	if r.Envelope.Message != message {
+		r.t.Errorf("%s: message = %q, want %q", r.request, r.Envelope.Message, message)
+	}
+	if r.Envelope.Error == nil {
+		r.t.Errorf("%s: error is empty", r.request)
+	}
+	return r
+}
+
+// AssertError checks the detail of a problem or the error of an envelope.
+func (r *Response) AssertError(want string) *Response {
+	r.t.Helper()
+
+	if r.Problem != nil {
+		if r.Problem.Detail != want {
+			r.t.Errorf("%s: detail = %q, want %q", r.request, r.Problem.Detail, want)
+		}
+		return r
+	}
+	if got, _ := r.Envelope.Error.(string); got != want {
+		r.t.Errorf("%s: error = %v, want %q", r.request, r.Envelope.Error, want)
+	}
+	return r
+}
+
+func (r *Response) AssertCode(want string) *Response {
+	r.t.Helper()
+
+	got := r.Envelope.Code
+	if r.Problem != nil {
+		got = r.Problem.Code
+	}
+	if got != want {
+		r.t.Errorf("%s: code = %q, want %q", r.request, got, want)
+	}
+	return r
+}
+
+// Text translates a message ID into the default locale.
+func Text(id string, params ...i18n.Params) string {
+	return message.Catalog.Localizer(message.Catalog.DefaultLanguage()).Translate(id, params...)
+}
+
+func (r *Response) DecodeData(into any) {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.Envelope.Data, into); err != nil {
+		r.t.Fatalf("%s: failed to decode data: %v\n%s", r.request, err, r.Envelope.Data)
+	}
+}
+
+func (r *Response) DecodeMeta(into any) {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.Envelope.Meta, into); err != nil {
+		r.t.Fatalf("%s: failed to decode meta: %v\n%s", r.request, err, r.Envelope.Meta)
+	}
+}
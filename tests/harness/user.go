@@ -0,0 +1,81 @@
+package harness
+
+import (
+	"net/http"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+)
+
+const DefaultPassword = "password123"
+
+type Session struct {
+	User   response.UserCreate
+	Tokens response.RefreshToken
+}
+
+func (h *Harness) RegisterUser(name, email, password string) response.UserCreate {
+	h.t.Helper()
+
+	res := h.PostJSON("/api/user/register", "", request.UserRegister{
+		Name:     name,
+		Email:    email,
+		Password: password,
+	}).AssertStatus(http.StatusCreated)
+
+	var created response.UserCreate
+	res.DecodeData(&created)
+	return created
+}
+
+func (h *Harness) Login(email, password string) response.RefreshToken {
+	h.t.Helper()
+
+	res := h.PostJSON("/api/user/login", "", request.UserLogin{
+		Email:    email,
+		Password: password,
+	}).AssertStatus(http.StatusOK)
+
+	var tokens response.RefreshToken
+	res.DecodeData(&tokens)
+	return tokens
+}
+
+func (h *Harness) RegisterAndLogin(name, email string) Session {
+	h.t.Helper()
+
+	created := h.RegisterUser(name, email, DefaultPassword)
+	return Session{
+		User:   created,
+		Tokens: h.Login(email, DefaultPassword),
+	}
+}
+
+func (s Session) Token() string {
+	return s.Tokens.AccessToken
+}
+
+// ETag is the tag of the current user as GET /api/user/me answers it.
+func (h *Harness) ETag(token string) string {
+	h.t.Helper()
+	return h.Get("/api/user/me", token).AssertStatus(http.StatusOK).Header().Get("ETag")
+}
+
+// UpdateUser patches the current user with If-Match set to etag.
+func (h *Harness) UpdateUser(token, etag string, body any) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodPatch, Path: "/api/user/", Token: token, Header: http.Header{"If-Match": {etag}}, JSON: body})
+}
+
+// DeleteUser deletes the current user with If-Match set to etag.
+func (h *Harness) DeleteUser(token, etag string) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodDelete, Path: "/api/user/", Token: token, Header: http.Header{"If-Match": {etag}}})
+}
+
+// DeleteAvatar removes the avatar of the current user with If-Match set to
+// etag.
+func (h *Harness) DeleteAvatar(token, etag string) *Response {
+	h.t.Helper()
+	return h.Do(Request{Method: http.MethodDelete, Path: "/api/user/me/avatar", Token: token, Header: http.Header{"If-Match": {etag}}})
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/i18n"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/response"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"golang.org/x/text/language"
+)
+
+func TestBundle(t *testing.T) {
+	bundle, err := i18n.NewBundle(language.English, fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{
+			"greeting": "Hello, {name}",
+			"files": {"zero": "No files", "one": "{count} file", "other": "{count} files"},
+			"only_english": "Only in English"
+		}`)},
+		"locales/id.json": {Data: []byte(`{
+			"greeting": "Halo, {name}",
+			"files": "{count} berkas"
+		}`)},
+	}, "locales")
+	if err != nil {
+		t.Fatalf("NewBundle() error = %v", err)
+	}
+
+	english := bundle.Localizer(language.English)
+	indonesian := bundle.Localizer(language.Indonesian)
+
+	tests := []struct {
+		name      string
+		localizer i18n.Localizer
+		id        string
+		params    i18n.Params
+		want      string
+	}{
+		{name: "interpolates parameters", localizer: english, id: "greeting", params: i18n.Params{"name": "Alice"}, want: "Hello, Alice"},
+		{name: "translates", localizer: indonesian, id: "greeting", params: i18n.Params{"name": "Alice"}, want: "Halo, Alice"},
+		{name: "picks the singular", localizer: english, id: "files", params: i18n.Params{"count": 1}, want: "1 file"},
+		{name: "picks the plural", localizer: english, id: "files", params: i18n.Params{"count": 3}, want: "3 files"},
+		{name: "ignores forms the language does not use", localizer: english, id: "files", params: i18n.Params{"count": 0}, want: "0 files"},
+		{name: "uses one form without plurals", localizer: indonesian, id: "files", params: i18n.Params{"count": 1}, want: "1 berkas"},
+		{name: "falls back to the default locale", localizer: indonesian, id: "only_english", want: "Only in English"},
+		{name: "falls back to the ID", localizer: indonesian, id: "unknown", want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.localizer.Translate(tt.id, tt.params); got != tt.want {
+				t.Errorf("Translate(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("matches Accept-Language", func(t *testing.T) {
+		for header, want := range map[string]language.Tag{
+			"":                       language.English,
+			"id-ID,id;q=0.9,en;q=.5": language.Indonesian,
+			"fr-FR,en-GB;q=0.8":      language.English,
+			"de":                     language.English,
+			"en;q=0.5,id;q=0.9":      language.Indonesian,
+		} {
+			if got := bundle.Match(header); got != want {
+				t.Errorf("Match(%q) = %s, want %s", header, got, want)
+			}
+		}
+	})
+
+	t.Run("rejects a catalog without the default locale", func(t *testing.T) {
+		_, err := i18n.NewBundle(language.English, fstest.MapFS{
+			"locales/id.json": {Data: []byte(`{}`)},
+		}, "locales")
+		if err == nil {
+			t.Error("NewBundle() error = nil, want an error")
+		}
+	})
+}
+
+func TestMessageCatalog(t *testing.T) {
+	for _, tag := range message.Catalog.Languages() {
+		if missing := message.Catalog.Missing(tag); len(missing) > 0 {
+			t.Errorf("%s catalog misses %v", tag, missing)
+		}
+	}
+}
+
+func TestLocalization(t *testing.T) {
+	t.Run("translates the message into the accepted language", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/user/register",
+			Header: http.Header{"Accept-Language": {"id-ID,id;q=0.9,en;q=0.5"}},
+			JSON: request.UserRegister{
+				Name:     "Alice",
+				Email:    "alice@example.com",
+				Password: harness.DefaultPassword,
+			},
+		}).AssertStatus(http.StatusCreated)
+
+		if res.Envelope.Message != "Berhasil mendaftar" {
+			t.Errorf("message = %q, want the Indonesian message", res.Envelope.Message)
+		}
+		if got := res.Header().Get("Content-Language"); got != "id" {
+			t.Errorf("Content-Language = %q, want id", got)
+		}
+	})
+
+	t.Run("answers in English for other languages", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Header: http.Header{"Accept-Language": {"fr"}},
+		}).AssertFailure(http.StatusUnauthorized, message.FailedProcessRequest)
+
+		if got := res.Header().Get("Content-Language"); got != "en" {
+			t.Errorf("Content-Language = %q, want en", got)
+		}
+	})
+
+	t.Run("translates validation errors", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/user/register",
+			Header: http.Header{"Accept-Language": {"id"}},
+			JSON:   map[string]string{"name": "Alice", "email": "alice", "password": "short"},
+		}).AssertStatus(http.StatusBadRequest)
+
+		if res.Problem.Title != "Gagal membaca data dari body" {
+			t.Errorf("title = %q, want the Indonesian title", res.Problem.Title)
+		}
+		if res.Problem.Detail != "permintaan memiliki 2 isian yang tidak valid" {
+			t.Errorf("detail = %q, want the Indonesian detail", res.Problem.Detail)
+		}
+		want := []response.FieldError{
+			{Field: "email", Rule: "email", Message: "harus berupa alamat email yang valid"},
+			{Field: "password", Rule: "min", Message: "minimal 8 karakter"},
+		}
+		if !reflect.DeepEqual(res.Problem.Errors, want) {
+			t.Errorf("errors = %+v, want %+v", res.Problem.Errors, want)
+		}
+	})
+
+	t.Run("counts invalid fields in English", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.PostJSON("/api/user/login", "", map[string]string{"email": "alice@example.com"}).
+			AssertStatus(http.StatusBadRequest)
+
+		if want := "request has 1 invalid field"; res.Problem.Detail != want {
+			t.Errorf("detail = %q, want %q", res.Problem.Detail, want)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+func TestIdempotentRequests(t *testing.T) {
+	register := func(h *harness.Harness, key string, body request.UserRegister) *harness.Response {
+		return h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/user/register",
+			Header: http.Header{middleware.IdempotencyKeyHeader: {key}},
+			JSON:   body,
+		})
+	}
+	alice := request.UserRegister{Name: "Alice", Email: "alice@example.com", Password: harness.DefaultPassword}
+
+	t.Run("replays the response to a retry", func(t *testing.T) {
+		h := harness.New(t)
+
+		first := register(h, "key-1", alice).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+		retry := register(h, "key-1", alice).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+
+		if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+			t.Errorf("%s = %q, want true", middleware.IdempotentReplayedHeader, retry.Header().Get(middleware.IdempotentReplayedHeader))
+		}
+		if first.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+			t.Errorf("%s of the first response = %q, want none", middleware.IdempotentReplayedHeader, first.Header().Get(middleware.IdempotentReplayedHeader))
+		}
+		if retry.Body.String() != first.Body.String() {
+			t.Errorf("replayed body = %s, want %s", retry.Body.String(), first.Body.String())
+		}
+
+		// The user was registered once, so registering again without the key
+		// fails.
+		h.PostJSON("/api/user/register", "", alice).
+			AssertFailure(http.StatusConflict, message.FailedRegister).
+			AssertCode(user.ErrorEmailAlreadyExists.Code)
+	})
+
+	t.Run("claims the key once for a version negotiated through Accept", func(t *testing.T) {
+		h := harness.New(t)
+		registerV2 := func() *harness.Response {
+			return h.Do(harness.Request{
+				Method: http.MethodPost,
+				Path:   "/api/user/register",
+				Header: http.Header{
+					middleware.IdempotencyKeyHeader: {"key-1"},
+					"Accept":                        {middleware.VersionMediaTypePrefix + "v2" + middleware.VersionMediaTypeSuffix},
+				},
+				JSON: alice,
+			})
+		}
+
+		first := registerV2().AssertSuccess(http.StatusCreated, message.SuccessRegister)
+		retry := registerV2().AssertSuccess(http.StatusCreated, message.SuccessRegister)
+		if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" || retry.Body.String() != first.Body.String() {
+			t.Errorf("retry = %s (%s %q), want the replayed %s", retry.Body.String(), middleware.IdempotentReplayedHeader, retry.Header().Get(middleware.IdempotentReplayedHeader), first.Body.String())
+		}
+	})
+
+	t.Run("replays an error response", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		register(h, "key-1", alice).AssertFailure(http.StatusConflict, message.FailedRegister)
+		retry := register(h, "key-1", alice).
+			AssertFailure(http.StatusConflict, message.FailedRegister).
+			AssertCode(user.ErrorEmailAlreadyExists.Code)
+		if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+			t.Errorf("%s = %q, want true", middleware.IdempotentReplayedHeader, retry.Header().Get(middleware.IdempotentReplayedHeader))
+		}
+	})
+
+	t.Run("rejects a key reused with a different payload", func(t *testing.T) {
+		h := harness.New(t)
+		register(h, "key-1", alice).AssertStatus(http.StatusCreated)
+
+		register(h, "key-1", request.UserRegister{Name: "Bob", Email: "bob@example.com", Password: harness.DefaultPassword}).
+			AssertFailure(http.StatusConflict, message.FailedIdempotency).
+			AssertCode(idempotency.ErrorKeyReused.Code)
+	})
+
+	t.Run("rejects a retry while the request is in flight", func(t *testing.T) {
+		h := harness.New(t)
+		claim, err := do.MustInvoke[service.IdempotencyService](h.Injector).Begin(context.Background(), "", "key-1", "in flight")
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+
+		register(h, "key-1", alice).
+			AssertFailure(http.StatusConflict, message.FailedIdempotency).
+			AssertCode(idempotency.ErrorRequestInFlight.Code)
+
+		// A request abandoned past the lock timeout is taken over.
+		h.Clock.Advance(idempotency.DefaultLockTimeout + 1)
+		first := register(h, "key-1", alice).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+
+		// The abandoned request can no longer store its response.
+		err = do.MustInvoke[service.IdempotencyService](h.Injector).Complete(context.Background(), claim.ID, response.IdempotentResponse{Status: http.StatusOK})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Complete() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+		retry := register(h, "key-1", alice).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+		if retry.Body.String() != first.Body.String() {
+			t.Errorf("replayed body = %s, want %s", retry.Body.String(), first.Body.String())
+		}
+	})
+
+	t.Run("scopes keys to the caller", func(t *testing.T) {
+		h := harness.New(t)
+		alice := h.RegisterAndLogin("Alice", "alice@example.com")
+		bob := h.RegisterAndLogin("Bob", "bob@example.com")
+		update := func(session harness.Session) *harness.Response {
+			return h.Do(harness.Request{
+				Method: http.MethodPatch,
+				Path:   "/api/user/",
+				Token:  session.Token(),
+				Header: http.Header{middleware.IdempotencyKeyHeader: {"key-1"}, "If-Match": {"*"}},
+				JSON:   request.UserUpdate{Name: "Renamed"},
+			})
+		}
+
+		update(alice).AssertSuccess(http.StatusOK, message.SuccessUpdateUser)
+		res := update(bob).AssertSuccess(http.StatusOK, message.SuccessUpdateUser)
+		if res.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+			t.Errorf("%s = %q, want a response of its own", middleware.IdempotentReplayedHeader, res.Header().Get(middleware.IdempotentReplayedHeader))
+		}
+
+		var me response.User
+		h.Get("/api/user/me", bob.Token()).AssertStatus(http.StatusOK).DecodeData(&me)
+		if me.Name != "Renamed" {
+			t.Errorf("name of the second caller = %q, want %q", me.Name, "Renamed")
+		}
+	})
+
+	t.Run("rejects a body larger than the limit", func(t *testing.T) {
+		t.Setenv("IDEMPOTENCY_MAX_BODY_MB", "1")
+		h := harness.New(t)
+		large := alice
+		large.Name = strings.Repeat("a", 1<<20)
+
+		register(h, "key-1", large).
+			AssertFailure(http.StatusRequestEntityTooLarge, message.FailedIdempotency).
+			AssertCode(idempotency.ErrorBodyTooLarge.Code)
+
+		// Nothing was claimed, so the key can still be used.
+		register(h, "key-1", alice).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+	})
+
+	t.Run("does not store a response larger than the limit", func(t *testing.T) {
+		t.Setenv("IDEMPOTENCY_MAX_BODY_MB", "1")
+		h := harness.New(t)
+		runs := 0
+		h.Engine.POST("/large", func(ctx *gin.Context) {
+			runs++
+			ctx.String(http.StatusOK, strings.Repeat("a", 1<<20+1))
+		})
+
+		for range 2 {
+			h.Do(harness.Request{
+				Method: http.MethodPost,
+				Path:   "/large",
+				Header: http.Header{middleware.IdempotencyKeyHeader: {"key-1"}},
+			}).AssertStatus(http.StatusOK)
+		}
+		if runs != 2 {
+			t.Errorf("handler ran %d times, want the retry to run it again", runs)
+		}
+	})
+
+	t.Run("passes streamed tus chunks through", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+		header := func() http.Header {
+			return http.Header{middleware.IdempotencyKeyHeader: {"key-1"}}
+		}
+
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), header()).AssertStatus(http.StatusNoContent)
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), header()).
+			AssertFailure(http.StatusConflict, message.FailedPatchUpload).
+			AssertError(file.ErrorUploadOffsetMismatch.Error())
+	})
+
+	t.Run("ignores the key on safe requests", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		for range 2 {
+			res := h.Do(harness.Request{
+				Method: http.MethodGet,
+				Path:   "/api/user/me",
+				Token:  session.Token(),
+				Header: http.Header{middleware.IdempotencyKeyHeader: {"key-1"}},
+			}).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+			if res.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+				t.Errorf("%s = %q on a GET, want none", middleware.IdempotentReplayedHeader, res.Header().Get(middleware.IdempotentReplayedHeader))
+			}
+		}
+	})
+}
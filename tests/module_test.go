@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/table"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/module"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/provider/file"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+)
+
+func TestModules(t *testing.T) {
+	t.Run("registers the feature modules in name order", func(t *testing.T) {
+		var names []string
+		for _, m := range module.Modules() {
+			names = append(names, m.Name())
+		}
+		if !slices.IsSorted(names) || !slices.Contains(names, "file") || !slices.Contains(names, "user") {
+			t.Errorf("modules = %v, want file and user in name order", names)
+		}
+	})
+
+	t.Run("rejects a module registered twice", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Register() did not panic on a duplicate name")
+			}
+		}()
+		module.Register(file.Module{})
+	})
+
+	t.Run("migrates and seeds the tables of every module", func(t *testing.T) {
+		db, err := config.OpenSQLite(config.SQLiteMemory, gormConfig())
+		if err != nil {
+			t.Fatalf("failed to open sqlite: %v", err)
+		}
+		defer config.CloseDatabaseConnection(db)
+
+		if err := migration.Migrate(db); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		for _, tbl := range []any{&table.User{}, &table.RefreshToken{}, &table.File{}, &table.Upload{}, &table.IdempotencyKey{}} {
+			if !db.Migrator().HasTable(tbl) {
+				t.Errorf("table of %T is missing", tbl)
+			}
+		}
+
+		if err := migration.Seeder(db); err != nil {
+			t.Fatalf("Seeder() error = %v", err)
+		}
+		var users int64
+		if db.Model(&table.User{}).Count(&users); users == 0 {
+			t.Error("the user module seeded no users")
+		}
+	})
+
+	t.Run("runs the migration steps of every module", func(t *testing.T) {
+		db, err := config.OpenSQLite(config.SQLiteMemory, gormConfig())
+		if err != nil {
+			t.Fatalf("failed to open sqlite: %v", err)
+		}
+		defer config.CloseDatabaseConnection(db)
+
+		if err := migration.Migrate(db); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		// The user module drops the unique index older versions created.
+		if err := db.Exec("CREATE UNIQUE INDEX idx_users_email_deleted_at ON users (email, deleted_at)").Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := migration.Migrate(db); err != nil {
+			t.Fatalf("second Migrate() error = %v", err)
+		}
+		if db.Migrator().HasIndex(&table.User{}, "idx_users_email_deleted_at") {
+			t.Error("the legacy index of the user module was not dropped")
+		}
+	})
+
+	t.Run("schedules the jobs of the file module", func(t *testing.T) {
+		t.Setenv("UPLOAD_CLEANUP_INTERVAL", "5m")
+		t.Setenv("FILE_CLEANUP_INTERVAL", "")
+		h := harness.New(t)
+
+		jobs := file.Module{}.Jobs(h.Injector)
+		if len(jobs) != 2 {
+			t.Fatalf("jobs = %d, want 2", len(jobs))
+		}
+		if jobs[0].Interval != 5*time.Minute || jobs[1].Interval != time.Hour {
+			t.Errorf("intervals = %v and %v, want 5m and the default hour", jobs[0].Interval, jobs[1].Interval)
+		}
+		if _, err := jobs[1].Run(t.Context()); err != nil {
+			t.Errorf("%s error = %v", jobs[1].Name, err)
+		}
+	})
+}
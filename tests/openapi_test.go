@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/command"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route/docs"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/openapi"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/gin-gonic/gin"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Run("serves the document of the registered routes", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Get(docs.SpecPath, "").AssertStatus(http.StatusOK)
+		var document openapi.Document
+		if err := json.Unmarshal(res.Body.Bytes(), &document); err != nil {
+			t.Fatalf("document is not JSON: %v", err)
+		}
+
+		if document.OpenAPI != openapi.Version {
+			t.Errorf("openapi = %q, want %q", document.OpenAPI, openapi.Version)
+		}
+		for _, route := range h.Engine.Routes() {
+			path := strings.NewReplacer(":id", "{id}", "*path", "{path}").Replace(route.Path)
+			if document.Paths[path][strings.ToLower(route.Method)] == nil {
+				t.Errorf("%s %s is missing from the document", route.Method, path)
+			}
+		}
+	})
+
+	t.Run("describes request validation and authentication", func(t *testing.T) {
+		h := harness.New(t)
+
+		var document openapi.Document
+		if err := json.Unmarshal(h.Get(docs.SpecPath, "").Body.Bytes(), &document); err != nil {
+			t.Fatalf("document is not JSON: %v", err)
+		}
+
+		register := document.Components.Schemas["request.UserRegister"]
+		if register == nil {
+			t.Fatal("request.UserRegister schema is missing")
+		}
+		if !slices.Equal(register.Required, []string{"name", "email", "password"}) {
+			t.Errorf("required = %v, want name, email and password", register.Required)
+		}
+		if password := register.Properties["password"]; password.MinLength == nil || *password.MinLength != 8 {
+			t.Errorf("password = %+v, want a minimum length of 8", password)
+		}
+		if email := register.Properties["email"]; email.Format != "email" {
+			t.Errorf("email format = %q, want email", email.Format)
+		}
+		if _, ok := register.Properties["image"]; ok {
+			t.Error("the JSON body lists the image file")
+		}
+
+		registerOperation := document.Paths["/api/user/register"]["post"]
+		if registerOperation.Security != nil {
+			t.Errorf("register security = %v, want none", registerOperation.Security)
+		}
+		if _, ok := registerOperation.RequestBody.Content["multipart/form-data"]; !ok {
+			t.Error("register does not accept multipart/form-data")
+		}
+
+		me := document.Paths["/api/user/me"]["get"]
+		if len(me.Security) != 1 || me.Security[0][openapi.BearerAuth] == nil {
+			t.Errorf("me security = %v, want %s", me.Security, openapi.BearerAuth)
+		}
+		if _, ok := me.Responses["401"]; !ok {
+			t.Error("me does not document 401")
+		}
+
+		list := document.Paths["/api/user/"]["get"].Responses["200"].Content["application/json"].Schema
+		if len(list.AllOf) != 2 || list.AllOf[0].Ref != "#/components/schemas/response.Response" {
+			t.Fatalf("list schema = %+v, want the envelope", list)
+		}
+		if meta := list.AllOf[1].Properties["meta"]; meta.Ref != "#/components/schemas/pagination.Response" {
+			t.Errorf("list meta = %+v, want pagination.Response", meta)
+		}
+	})
+
+	t.Run("serves the documentation page", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Get(docs.UIPath, "").AssertStatus(http.StatusOK)
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), "text/html") || !strings.Contains(res.Body.String(), docs.SpecPath) {
+			t.Errorf("page = %s %q, want HTML loading %s", res.Header().Get("Content-Type"), res.Body.String(), docs.SpecPath)
+		}
+	})
+
+	t.Run("matches the committed document", func(t *testing.T) {
+		h := harness.New(t)
+
+		if err := command.CheckOpenAPI(h.Injector, filepath.Join("..", command.OpenAPIPath)); err != nil {
+			t.Fatalf("CheckOpenAPI() error = %v", err)
+		}
+	})
+
+	t.Run("checks the document without a .env file", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("builds the application")
+		}
+
+		dir := t.TempDir()
+		binary := filepath.Join(dir, "app")
+		build := exec.Command("go", "build", "-o", binary, ".")
+		build.Dir = ".."
+		if output, err := build.CombinedOutput(); err != nil {
+			t.Fatalf("go build failed: %v\n%s", err, output)
+		}
+		copyFiles(t, "..", dir, command.OpenAPIPath)
+
+		check := exec.Command(binary, "--openapi-check")
+		check.Dir = dir
+		check.Env = slices.DeleteFunc(os.Environ(), func(variable string) bool {
+			return strings.HasPrefix(variable, "APP_ENV=")
+		})
+		if output, err := check.CombinedOutput(); err != nil {
+			t.Fatalf("--openapi-check failed: %v\n%s", err, output)
+		}
+	})
+
+	t.Run("detects a stale document", func(t *testing.T) {
+		h := harness.New(t)
+		path := filepath.Join(t.TempDir(), "openapi.json")
+		if err := os.WriteFile(path, []byte(`{"openapi":"3.1.0"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := command.CheckOpenAPI(h.Injector, path); !errors.Is(err, command.ErrorOpenAPIStale) {
+			t.Fatalf("CheckOpenAPI() error = %v, want %v", err, command.ErrorOpenAPIStale)
+		}
+
+		if err := command.WriteOpenAPI(h.Injector, path); err != nil {
+			t.Fatalf("WriteOpenAPI() error = %v", err)
+		}
+		if err := command.CheckOpenAPI(h.Injector, path); err != nil {
+			t.Errorf("CheckOpenAPI() after WriteOpenAPI() error = %v", err)
+		}
+	})
+
+	t.Run("rejects routes without documentation", func(t *testing.T) {
+		engine := gin.New()
+		engine.GET("/undocumented", func(*gin.Context) {})
+		spec := openapi.NewSpec(openapi.Info{Title: "test", Version: "1"})
+
+		if _, err := spec.Build(engine.Routes()); err == nil || !strings.Contains(err.Error(), "GET /undocumented") {
+			t.Errorf("Build() error = %v, want the undocumented route", err)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/idempotency"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/clock"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/adapter/id_generator"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/memory"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/repository"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/contract"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var cursors = pagination.NewCursorCodec([]byte("repository-test"))
+
+func TestMemoryUserRepository(t *testing.T) {
+	contract.UserRepository(t, func(t *testing.T) user.Repository {
+		return memory.NewUserRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter(), cursors)
+	})
+}
+
+func TestMemoryRefreshTokenRepository(t *testing.T) {
+	contract.RefreshTokenRepository(t, func(t *testing.T) contract.RefreshTokenRepositories {
+		return contract.RefreshTokenRepositories{
+			Users:         memory.NewUserRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter(), cursors),
+			RefreshTokens: memory.NewRefreshTokenRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter()),
+		}
+	})
+}
+
+func TestMemoryFileRepository(t *testing.T) {
+	contract.FileRepository(t, func(t *testing.T) contract.FileRepositories {
+		return contract.FileRepositories{
+			Users: memory.NewUserRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter(), cursors),
+			Files: memory.NewFileRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter()),
+		}
+	})
+}
+
+func TestMemoryUploadRepository(t *testing.T) {
+	contract.UploadRepository(t, func(t *testing.T) contract.UploadRepositories {
+		return contract.UploadRepositories{
+			Users:   memory.NewUserRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter(), cursors),
+			Files:   memory.NewFileRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter()),
+			Uploads: memory.NewUploadRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter()),
+		}
+	})
+}
+
+func TestMemoryIdempotencyRepository(t *testing.T) {
+	contract.IdempotencyRepository(t, func(t *testing.T) idempotency.Repository {
+		return memory.NewIdempotencyRepository(clock.NewSystemAdapter(), id_generator.NewUUIDAdapter())
+	})
+}
+
+func TestGormUserRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		contract.UserRepository(t, func(t *testing.T) user.Repository {
+			return repository.NewUserRepository(open(t))
+		})
+	})
+}
+
+func TestGormRefreshTokenRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		contract.RefreshTokenRepository(t, func(t *testing.T) contract.RefreshTokenRepositories {
+			injector := open(t)
+			return contract.RefreshTokenRepositories{
+				Users:         repository.NewUserRepository(injector),
+				RefreshTokens: repository.NewRefreshTokenRepository(injector),
+			}
+		})
+	})
+}
+
+func TestGormFileRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		contract.FileRepository(t, func(t *testing.T) contract.FileRepositories {
+			injector := open(t)
+			return contract.FileRepositories{
+				Users: repository.NewUserRepository(injector),
+				Files: repository.NewFileRepository(injector),
+			}
+		})
+	})
+}
+
+func TestGormUploadRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		contract.UploadRepository(t, func(t *testing.T) contract.UploadRepositories {
+			injector := open(t)
+			return contract.UploadRepositories{
+				Users:   repository.NewUserRepository(injector),
+				Files:   repository.NewFileRepository(injector),
+				Uploads: repository.NewUploadRepository(injector),
+			}
+		})
+	})
+}
+
+func TestGormIdempotencyRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		contract.IdempotencyRepository(t, func(t *testing.T) idempotency.Repository {
+			return repository.NewIdempotencyRepository(open(t))
+		})
+	})
+}
+
+func TestGormRepositoriesTakeIDsFromTheGenerator(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, open func(t *testing.T) do.Injector) {
+		injector := open(t)
+		do.OverrideValue[port.IDGeneratorPort](injector, fake.NewIDGenerator())
+		ctx := context.Background()
+
+		registered, err := repository.NewUserRepository(injector).Register(ctx, contract.NewUser("alice@example.com"))
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		token, err := repository.NewRefreshTokenRepository(injector).Create(ctx, refresh_token.RefreshToken{
+			UserID:    registered.ID,
+			Token:     "token",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Create(refresh token) error = %v", err)
+		}
+		upload, err := repository.NewUploadRepository(injector).Create(ctx, file.Upload{
+			OwnerID:   registered.ID,
+			Length:    3,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Create(upload) error = %v", err)
+		}
+		stored, err := repository.NewFileRepository(injector).Create(ctx, file.File{
+			OwnerID:    registered.ID,
+			StorageKey: file.StorageKey("digest"),
+			Name:       "report.pdf",
+			Size:       3,
+			SHA256:     "digest",
+			RefCount:   1,
+		})
+		if err != nil {
+			t.Fatalf("Create(file) error = %v", err)
+		}
+		record, err := repository.NewIdempotencyRepository(injector).Create(ctx, idempotency.Record{
+			Scope:     "scope",
+			Key:       "key",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Create(idempotency key) error = %v", err)
+		}
+
+		got := []uuid.UUID{registered.ID.ID, token.ID.ID, upload.ID.ID, stored.ID.ID, record.ID.ID}
+		want := []uuid.UUID{fake.Sequential(1), fake.Sequential(2), fake.Sequential(3), fake.Sequential(4), fake.Sequential(5)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("IDs = %v, want %v", got, want)
+		}
+	})
+}
+
+// forEachDatabase runs fn against SQLite, and against PostgreSQL as well when
+// TEST_POSTGRES_DSN is set.
+func forEachDatabase(t *testing.T, fn func(t *testing.T, open func(t *testing.T) do.Injector)) {
+	t.Run(config.DriverSQLite, func(t *testing.T) {
+		fn(t, func(t *testing.T) do.Injector {
+			db, err := config.OpenSQLite(config.SQLiteMemory, gormConfig())
+			if err != nil {
+				t.Fatalf("failed to open sqlite: %v", err)
+			}
+			return newDatabaseInjector(t, db)
+		})
+	})
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		return
+	}
+
+	t.Run(config.DriverPostgres, func(t *testing.T) {
+		fn(t, func(t *testing.T) do.Injector {
+			db, err := config.OpenPostgres(dsn, gormConfig())
+			if err != nil {
+				t.Fatalf("failed to open postgres: %v", err)
+			}
+			if err = migration.Rollback(db); err != nil {
+				t.Fatalf("failed to reset postgres: %v", err)
+			}
+			return newDatabaseInjector(t, db)
+		})
+	})
+}
+
+func newDatabaseInjector(t *testing.T, db *gorm.DB) do.Injector {
+	t.Helper()
+
+	t.Cleanup(func() {
+		config.CloseDatabaseConnection(db)
+	})
+
+	if err := migration.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	injector := do.New()
+	do.ProvideValue(injector, db)
+	do.ProvideValue(injector, clock.NewSystemAdapter())
+	do.ProvideValue(injector, id_generator.NewUUIDAdapter())
+	do.ProvideValue(injector, cursors)
+	do.Provide(injector, func(injector do.Injector) (*transaction.Repository, error) {
+		return transaction.NewRepository(injector), nil
+	})
+	return injector
+}
+
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	}
+}
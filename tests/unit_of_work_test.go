@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/config"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/migration"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/repository"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/infrastructure/database/transaction"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/samber/do/v2"
+	"gorm.io/gorm"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	t.Run("retries a service write that lost a serialization race", func(t *testing.T) {
+		// Two connections to one WAL database: a transaction of the first
+		// that read before the second committed cannot write afterwards.
+		path := filepath.Join(t.TempDir(), "app.db") + "?_pragma=journal_mode(WAL)"
+		db := openFileDatabase(t, path)
+		other := openFileDatabase(t, path)
+		if err := migration.Migrate(db); err != nil {
+			t.Fatalf("failed to migrate: %v", err)
+		}
+
+		users := &racingUserRepository{}
+		h := harness.New(t, func(injector do.Injector) {
+			do.OverrideValue(injector, db)
+			do.Override(injector, func(injector do.Injector) (user.Repository, error) {
+				users.Repository = repository.NewUserRepository(injector)
+				return users, nil
+			})
+			do.Override(injector, func(injector do.Injector) (application.UnitOfWork, error) {
+				return transaction.NewUnitOfWork(injector), nil
+			})
+		})
+		created := h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		users.race = func(id string) {
+			if err := other.Exec("UPDATE users SET phone_number = ?, version = version + 1 WHERE id = ?", "0800", id).Error; err != nil {
+				t.Fatalf("concurrent update failed: %v", err)
+			}
+		}
+		updated, err := do.MustInvoke[service.UserService](h.Injector).Update(context.Background(), created.ID, 0, request.UserUpdate{Name: "Renamed"})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		if users.reads != 2 {
+			t.Errorf("the user was read %d times, want the transaction run again once", users.reads)
+		}
+		if updated.Name != "Renamed" || updated.PhoneNumber != "0800" {
+			t.Errorf("Update() = %+v, want the new name on top of the concurrent update", updated)
+		}
+	})
+}
+
+func openFileDatabase(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+
+	db, err := config.OpenSQLite(path, gormConfig())
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		config.CloseDatabaseConnection(db)
+	})
+	return db
+}
+
+// racingUserRepository commits a concurrent change through race, once, right
+// after the first read of a user.
+type racingUserRepository struct {
+	user.Repository
+	race  func(id string)
+	reads int
+}
+
+func (r *racingUserRepository) GetUserByID(ctx context.Context, id string) (user.User, error) {
+	retrievedUser, err := r.Repository.GetUserByID(ctx, id)
+	if r.race != nil {
+		r.reads++
+		if r.reads == 1 {
+			r.race(id)
+		}
+	}
+	return retrievedUser, err
+}
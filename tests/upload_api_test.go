@@ -0,0 +1,495 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/service"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/file"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/identity"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/port"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/google/uuid"
+	"github.com/samber/do/v2"
+)
+
+func TestTusDiscovery(t *testing.T) {
+	t.Run("advertises the protocol without authentication", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{Method: http.MethodOptions, Path: "/api/files/"}).AssertStatus(http.StatusNoContent)
+
+		assertHeaders(t, res, map[string]string{
+			"Tus-Resumable":          "1.0.0",
+			"Tus-Version":            "1.0.0",
+			"Tus-Extension":          "creation,termination,checksum,expiration",
+			"Tus-Max-Size":           strconv.Itoa(file.DefaultMaxUploadSize),
+			"Tus-Checksum-Algorithm": "md5,sha1,sha256",
+		})
+	})
+
+	t.Run("still answers cors preflights", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodOptions,
+			Path:   "/api/files/",
+			Header: http.Header{"Access-Control-Request-Method": {http.MethodPost}},
+		}).AssertStatus(http.StatusNoContent)
+
+		if got := res.Header().Get("Tus-Extension"); got != "" {
+			t.Errorf("Tus-Extension = %q, want the preflight to stop at cors", got)
+		}
+	})
+
+	t.Run("rejects an unsupported protocol version", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/files/",
+			Token:  session.Token(),
+			Header: http.Header{"Tus-Resumable": {"0.2.2"}, "Upload-Length": {"10"}},
+		}).AssertFailure(http.StatusPreconditionFailed, message.FailedTusVersion)
+
+		if got := res.Header().Get("Tus-Version"); got != "1.0.0" {
+			t.Errorf("Tus-Version = %q", got)
+		}
+	})
+}
+
+func TestTusUpload(t *testing.T) {
+	t.Run("assembles chunks into a file", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		location := createUpload(h, session.Token(), 11, "filename "+base64.StdEncoding.EncodeToString([]byte("hello.txt")))
+		uploadID := fake.Sequential(3).String()
+		if location != "/api/files/"+uploadID {
+			t.Fatalf("Location = %q", location)
+		}
+
+		head := headUpload(h, session.Token(), location).AssertStatus(http.StatusOK)
+		assertHeaders(t, head, map[string]string{
+			"Upload-Offset":   "0",
+			"Upload-Length":   "11",
+			"Upload-Metadata": "filename aGVsbG8udHh0",
+			"Upload-Expires":  harness.Epoch.Add(file.DefaultUploadExpiration).Format(http.TimeFormat),
+			"Cache-Control":   "no-store",
+		})
+
+		patchUpload(h, session.Token(), location, 0, []byte("hello "), nil).AssertStatus(http.StatusNoContent)
+		assertHeaders(t, headUpload(h, session.Token(), location), map[string]string{"Upload-Offset": "6"})
+
+		res := patchUpload(h, session.Token(), location, 6, []byte("world"), nil).AssertStatus(http.StatusNoContent)
+		// IDs 4 and 5 went to the parts.
+		fileID := fake.Sequential(6).String()
+		assertHeaders(t, res, map[string]string{"Upload-Offset": "11", "File-Id": fileID})
+
+		digest := file.Digest([]byte("hello world"))
+		assertStoredFiles(t, h, file.StorageKey(digest))
+		content, err := os.ReadFile(filepath.Join(h.StorageRoot, "files", digest))
+		if err != nil {
+			t.Fatalf("failed to read the assembled file: %v", err)
+		}
+		if string(content) != "hello world" {
+			t.Errorf("assembled file = %q", content)
+		}
+
+		stored, err := do.MustInvoke[file.Repository](h.Injector).GetFileByID(context.Background(), fileID)
+		if err != nil {
+			t.Fatalf("GetFileByID() error = %v", err)
+		}
+		if stored.Name != "hello.txt" || stored.Size != 11 || stored.SHA256 != digest || stored.RefCount != 1 ||
+			stored.OwnerID.String() != session.User.ID || stored.ContentType != "text/plain; charset=utf-8" {
+			t.Errorf("file record = %+v", stored)
+		}
+	})
+
+	t.Run("completes an empty upload on creation", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(tusRequest(http.MethodPost, "/api/files/", session.Token(), http.Header{"Upload-Length": {"0"}})).
+			AssertStatus(http.StatusCreated)
+		assertHeaders(t, res, map[string]string{"Upload-Offset": "0", "File-Id": fake.Sequential(4).String()})
+	})
+
+	t.Run("assembles only the accepted parts", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 11, "")
+		patchUpload(h, session.Token(), location, 0, []byte("hello "), nil).AssertStatus(http.StatusNoContent)
+
+		// A part left at the same offset by an attempt that lost the race.
+		uploadID, err := uuid.Parse(strings.TrimPrefix(location, "/api/files/"))
+		if err != nil {
+			t.Fatalf("Location = %q: %v", location, err)
+		}
+		lost := file.Upload{ID: identity.NewID(uploadID)}.PartKey(0, "lost")
+		if err = do.MustInvoke[port.FileStoragePort](h.Injector).Put(context.Background(), lost, strings.NewReader("HELLO "), -1, file.TusContentType); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		patchUpload(h, session.Token(), location, 6, []byte("world"), nil).AssertStatus(http.StatusNoContent)
+
+		content, err := os.ReadFile(filepath.Join(h.StorageRoot, file.StorageKey(file.Digest([]byte("hello world")))))
+		if err != nil {
+			t.Fatalf("failed to read the assembled file: %v", err)
+		}
+		if string(content) != "hello world" {
+			t.Errorf("assembled file = %q, want %q", content, "hello world")
+		}
+	})
+
+	t.Run("rejects a chunk at the wrong offset", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+
+		patchUpload(h, session.Token(), location, 3, []byte("abc"), nil).
+			AssertFailure(http.StatusConflict, message.FailedPatchUpload).
+			AssertError(file.ErrorUploadOffsetMismatch.Error())
+	})
+
+	t.Run("rejects a chunk with the wrong content type", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+
+		req := tusRequest(http.MethodPatch, location, session.Token(), http.Header{
+			"Upload-Offset": {"0"},
+			"Content-Type":  {"application/octet-stream"},
+		})
+		req.Body = []byte("abc")
+		h.Do(req).AssertFailure(http.StatusUnsupportedMediaType, message.FailedPatchUpload)
+	})
+
+	t.Run("rejects a chunk past the upload length", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 4, "")
+
+		patchUpload(h, session.Token(), location, 0, []byte("too long"), nil).
+			AssertFailure(http.StatusRequestEntityTooLarge, message.FailedPatchUpload)
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("rejects an upload over the maximum size", func(t *testing.T) {
+		t.Setenv("UPLOAD_MAX_SIZE_MB", "1")
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(tusRequest(http.MethodPost, "/api/files/", session.Token(), http.Header{"Upload-Length": {strconv.Itoa(2 << 20)}})).
+			AssertFailure(http.StatusRequestEntityTooLarge, message.FailedCreateUpload)
+	})
+
+	t.Run("rejects malformed metadata", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(tusRequest(http.MethodPost, "/api/files/", session.Token(), http.Header{
+			"Upload-Length":   {"10"},
+			"Upload-Metadata": {"filename not-base64!"},
+		})).AssertFailure(http.StatusBadRequest, message.FailedCreateUpload).
+			AssertError(file.ErrorUploadMetadata.Error())
+	})
+
+	t.Run("hides uploads owned by someone else", func(t *testing.T) {
+		h := harness.New(t)
+		alice := h.RegisterAndLogin("Alice", "alice@example.com")
+		bob := h.RegisterAndLogin("Bob", "bob@example.com")
+		location := createUpload(h, alice.Token(), 10, "")
+
+		headUpload(h, bob.Token(), location).AssertStatus(http.StatusNotFound)
+		patchUpload(h, bob.Token(), location, 0, []byte("abc"), nil).AssertStatus(http.StatusNotFound)
+		h.Do(tusRequest(http.MethodDelete, location, bob.Token(), nil)).AssertStatus(http.StatusNotFound)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.Do(tusRequest(http.MethodPost, "/api/files/", "", http.Header{"Upload-Length": {"10"}})).
+			AssertStatus(http.StatusUnauthorized)
+	})
+}
+
+func TestTusChecksum(t *testing.T) {
+	t.Run("accepts a chunk matching its checksum", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+
+		sum := sha1.Sum([]byte("abc"))
+		res := patchUpload(h, session.Token(), location, 0, []byte("abc"), http.Header{
+			"Upload-Checksum": {"sha1 " + base64.StdEncoding.EncodeToString(sum[:])},
+		}).AssertStatus(http.StatusNoContent)
+		assertHeaders(t, res, map[string]string{"Upload-Offset": "3"})
+	})
+
+	t.Run("discards a chunk that fails its checksum", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+
+		sum := sha1.Sum([]byte("abd"))
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), http.Header{
+			"Upload-Checksum": {"sha1 " + base64.StdEncoding.EncodeToString(sum[:])},
+		}).AssertFailure(460, message.FailedPatchUpload).
+			AssertError(file.ErrorChecksumMismatch.Error())
+
+		assertHeaders(t, headUpload(h, session.Token(), location), map[string]string{"Upload-Offset": "0"})
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("rejects an unsupported algorithm", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 10, "")
+
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), http.Header{"Upload-Checksum": {"crc32 AAAA"}}).
+			AssertFailure(http.StatusBadRequest, message.FailedPatchUpload).
+			AssertError(file.ErrorChecksumAlgorithm.Error())
+	})
+}
+
+func TestTusTermination(t *testing.T) {
+	h := harness.New(t)
+	session := h.RegisterAndLogin("Alice", "alice@example.com")
+	location := createUpload(h, session.Token(), 10, "")
+	patchUpload(h, session.Token(), location, 0, []byte("abc"), nil).AssertStatus(http.StatusNoContent)
+
+	h.Do(tusRequest(http.MethodDelete, location, session.Token(), nil)).AssertStatus(http.StatusNoContent)
+
+	headUpload(h, session.Token(), location).AssertStatus(http.StatusNotFound)
+	assertStoredFiles(t, h)
+}
+
+func TestTusExpiration(t *testing.T) {
+	h := harness.New(t)
+	session := h.RegisterAndLogin("Alice", "alice@example.com")
+	expiring := createUpload(h, session.Token(), 10, "")
+	patchUpload(h, session.Token(), expiring, 0, []byte("abc"), nil).AssertStatus(http.StatusNoContent)
+
+	completed := createUpload(h, session.Token(), 3, "")
+	patchUpload(h, session.Token(), completed, 0, []byte("abc"), nil).AssertStatus(http.StatusNoContent)
+
+	h.Clock.Advance(file.DefaultUploadExpiration + time.Minute)
+	token := h.Login("alice@example.com", harness.DefaultPassword).AccessToken
+
+	headUpload(h, token, expiring).AssertStatus(http.StatusGone)
+	patchUpload(h, token, expiring, 3, []byte("def"), nil).AssertStatus(http.StatusGone)
+	headUpload(h, token, completed).AssertStatus(http.StatusOK)
+
+	deleted, err := do.MustInvoke[service.UploadService](h.Injector).DeleteExpired(context.Background())
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+
+	headUpload(h, token, expiring).AssertStatus(http.StatusNotFound)
+	assertStoredFiles(t, h, file.StorageKey(file.Digest([]byte("abc"))))
+}
+
+func TestTusScanning(t *testing.T) {
+	t.Run("rejects an infected upload and discards it", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), int64(len(fake.EICAR)), "")
+
+		patchUpload(h, session.Token(), location, 0, []byte(fake.EICAR), nil).
+			AssertFailure(http.StatusUnprocessableEntity, message.FailedPatchUpload).
+			AssertError(file.ErrorFileInfected.Error())
+
+		headUpload(h, session.Token(), location).AssertStatus(http.StatusNotFound)
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("keeps the upload until the scanner is available", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 3, "")
+
+		h.Scanner.Err = errors.New("scanner unavailable")
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), nil).
+			AssertFailure(http.StatusServiceUnavailable, message.FailedPatchUpload)
+		res := headUpload(h, session.Token(), location).AssertStatus(http.StatusOK)
+		assertHeaders(t, res, map[string]string{"Upload-Offset": "3", "File-Id": ""})
+
+		h.Scanner.Err = nil
+		res = patchUpload(h, session.Token(), location, 3, nil, nil).AssertStatus(http.StatusNoContent)
+		if res.Header().Get("File-Id") == "" {
+			t.Error("File-Id missing once the upload was scanned")
+		}
+		assertStoredFiles(t, h, file.StorageKey(file.Digest([]byte("abc"))))
+	})
+}
+
+func TestFileRegistry(t *testing.T) {
+	complete := func(h *harness.Harness, token string, content []byte) *harness.Response {
+		location := createUpload(h, token, int64(len(content)), "")
+		return patchUpload(h, token, location, 0, content, nil).AssertStatus(http.StatusNoContent)
+	}
+
+	t.Run("stores identical content once per owner", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		first := complete(h, session.Token(), []byte("hello world")).Header().Get("File-Id")
+		second := complete(h, session.Token(), []byte("hello world")).Header().Get("File-Id")
+		if first != second {
+			t.Errorf("File-Id = %s, want the first file %s", second, first)
+		}
+
+		stored, err := do.MustInvoke[file.Repository](h.Injector).GetFileByID(context.Background(), first)
+		if err != nil {
+			t.Fatalf("GetFileByID() error = %v", err)
+		}
+		if stored.RefCount != 2 {
+			t.Errorf("RefCount = %d, want 2", stored.RefCount)
+		}
+		assertStoredFiles(t, h, file.StorageKey(file.Digest([]byte("hello world"))))
+	})
+
+	t.Run("shares the object between owners", func(t *testing.T) {
+		h := harness.New(t)
+		alice := h.RegisterAndLogin("Alice", "alice@example.com")
+		bob := h.RegisterAndLogin("Bob", "bob@example.com")
+
+		first := complete(h, alice.Token(), []byte("hello world")).Header().Get("File-Id")
+		second := complete(h, bob.Token(), []byte("hello world")).Header().Get("File-Id")
+		if first == second {
+			t.Errorf("File-Id = %s for both owners, want a file each", first)
+		}
+		assertStoredFiles(t, h, file.StorageKey(file.Digest([]byte("hello world"))))
+	})
+
+	t.Run("rejects an upload over the quota", func(t *testing.T) {
+		t.Setenv("FILE_QUOTA_MB", "1")
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		complete(h, session.Token(), make([]byte, 1<<19))
+
+		h.Do(tusRequest(http.MethodPost, "/api/files/", session.Token(), http.Header{"Upload-Length": {strconv.Itoa(1 << 20)}})).
+			AssertFailure(http.StatusRequestEntityTooLarge, message.FailedCreateUpload).
+			AssertError(file.ErrorQuotaExceeded.Error())
+	})
+
+	t.Run("deletes terminated files after the grace period", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		location := createUpload(h, session.Token(), 3, "")
+		patchUpload(h, session.Token(), location, 0, []byte("abc"), nil).AssertStatus(http.StatusNoContent)
+
+		h.Do(tusRequest(http.MethodDelete, location, session.Token(), nil)).AssertStatus(http.StatusNoContent)
+		assertStoredFiles(t, h, file.StorageKey(file.Digest([]byte("abc"))))
+
+		if deleted := cleanUpFiles(t, h); deleted != 1 {
+			t.Errorf("cleanup deleted %d files, want 1", deleted)
+		}
+		assertStoredFiles(t, h)
+	})
+}
+
+func TestAvatarFromUpload(t *testing.T) {
+	upload := func(h *harness.Harness, token string, content []byte) string {
+		location := createUpload(h, token, int64(len(content)), "filename YXZhdGFyLnBuZw==")
+		res := patchUpload(h, token, location, 0, content, nil).AssertStatus(http.StatusNoContent)
+		return res.Header().Get("File-Id")
+	}
+
+	t.Run("uses a completed upload as the avatar", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		image := harness.PNG(t, 128, 128)
+		fileID := upload(h, session.Token(), image)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPut,
+			Path:   "/api/user/me/avatar",
+			Header: http.Header{"If-Match": {"*"}},
+			Token:  session.Token(),
+			JSON:   map[string]string{"file_id": fileID},
+		}).AssertSuccess(http.StatusOK, message.SuccessUpdateAvatar)
+
+		var updated response.User
+		res.DecodeData(&updated)
+		if len(updated.ImageThumbnails) != 3 {
+			t.Errorf("image_thumbnails = %v, want three sizes", updated.ImageThumbnails)
+		}
+
+		imageID := fake.Sequential(6).String()
+		assertStoredFiles(t, h, file.StorageKey(file.Digest(image)),
+			"profile/"+imageID+"/256.png", "profile/"+imageID+"/512.png", "profile/"+imageID+"/64.png")
+	})
+
+	t.Run("rejects a file owned by someone else", func(t *testing.T) {
+		h := harness.New(t)
+		alice := h.RegisterAndLogin("Alice", "alice@example.com")
+		bob := h.RegisterAndLogin("Bob", "bob@example.com")
+		fileID := upload(h, alice.Token(), harness.PNG(t, 128, 128))
+
+		h.Do(harness.Request{
+			Method: http.MethodPut,
+			Path:   "/api/user/me/avatar",
+			Header: http.Header{"If-Match": {"*"}},
+			Token:  bob.Token(),
+			JSON:   map[string]string{"file_id": fileID},
+		}).AssertFailure(http.StatusNotFound, message.FailedUpdateAvatar).
+			AssertError(file.ErrorFileNotFound.Error())
+	})
+}
+
+func tusRequest(method, path, token string, header http.Header) harness.Request {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Tus-Resumable", "1.0.0")
+	return harness.Request{Method: method, Path: path, Token: token, Header: header}
+}
+
+func createUpload(h *harness.Harness, token string, length int64, metadata string) string {
+	header := http.Header{"Upload-Length": {strconv.FormatInt(length, 10)}}
+	if metadata != "" {
+		header.Set("Upload-Metadata", metadata)
+	}
+	res := h.Do(tusRequest(http.MethodPost, "/api/files/", token, header)).AssertStatus(http.StatusCreated)
+	return res.Header().Get("Location")
+}
+
+func headUpload(h *harness.Harness, token, location string) *harness.Response {
+	return h.Do(tusRequest(http.MethodHead, location, token, nil))
+}
+
+func patchUpload(h *harness.Harness, token, location string, offset int64, chunk []byte, header http.Header) *harness.Response {
+	req := tusRequest(http.MethodPatch, location, token, header)
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Body = chunk
+	return h.Do(req)
+}
+
+func assertHeaders(t *testing.T, res *harness.Response, want map[string]string) {
+	t.Helper()
+
+	for header, value := range want {
+		if got := res.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/request"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/refresh_token"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/domain/user"
+	v2 "github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller/v2"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/platform/pagination"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/fake"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+	"github.com/google/uuid"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("creates a user from a JSON body", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.PostJSON("/api/user/register", "", request.UserRegister{
+			Name:        "Alice",
+			Email:       "alice@example.com",
+			PhoneNumber: "081234567890",
+			Password:    harness.DefaultPassword,
+		}).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+
+		var created response.UserCreate
+		res.DecodeData(&created)
+		if created.ID != fake.Sequential(1).String() {
+			t.Errorf("id = %q, want %q", created.ID, fake.Sequential(1))
+		}
+		if created.Email != "alice@example.com" || created.Role != user.RoleUser || created.IsVerified {
+			t.Errorf("created user = %+v", created)
+		}
+	})
+
+	t.Run("stores the uploaded profile image", func(t *testing.T) {
+		h := harness.New(t)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/user/register",
+			Form: map[string]string{
+				"name":     "Alice",
+				"email":    "alice@example.com",
+				"password": harness.DefaultPassword,
+			},
+			Files: map[string]harness.File{
+				"image": {Name: "avatar.png", Content: harness.PNG(t, 128, 128)},
+			},
+		}).AssertSuccess(http.StatusCreated, message.SuccessRegister)
+
+		var created response.UserCreate
+		res.DecodeData(&created)
+		imageID := fake.Sequential(2).String()
+		if want := harness.BaseURL + "/api/v1/storage/profile/" + imageID + "/512.png?"; !strings.HasPrefix(created.ImageUrl, want) {
+			t.Fatalf("image_url = %q, want a signed url starting with %q", created.ImageUrl, want)
+		}
+		if len(created.ImageThumbnails) != len(user.AvatarSizes) {
+			t.Errorf("image_thumbnails = %v, want one per size", created.ImageThumbnails)
+		}
+		assertStoredFiles(t, h, "profile/"+imageID+"/256.png", "profile/"+imageID+"/512.png", "profile/"+imageID+"/64.png")
+
+		body := h.Fetch(created.ImageUrl).AssertStatus(http.StatusOK).Body.Bytes()
+		if !bytes.HasPrefix(body, []byte("\x89PNG")) {
+			t.Errorf("downloaded image is not a png: %q", body[:min(len(body), 8)])
+		}
+	})
+
+	t.Run("rejects an upload that is not an image", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.Do(harness.Request{
+			Method: http.MethodPost,
+			Path:   "/api/user/register",
+			Form: map[string]string{
+				"name":     "Alice",
+				"email":    "alice@example.com",
+				"password": harness.DefaultPassword,
+			},
+			Files: map[string]harness.File{
+				"image": {Name: "avatar.png", Content: []byte("not really a png")},
+			},
+		}).AssertFailure(http.StatusBadRequest, message.FailedRegister).
+			AssertError(user.ErrorAvatarUnsupportedType.Error())
+
+		assertStoredFiles(t, h)
+	})
+
+	t.Run("rejects a duplicate email", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		h.PostJSON("/api/user/register", "", request.UserRegister{
+			Name:     "Alice Again",
+			Email:    "alice@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertFailure(http.StatusConflict, message.FailedRegister).
+			AssertError(user.ErrorEmailAlreadyExists.Error())
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		h := harness.New(t)
+
+		tests := map[string]map[string]any{
+			"missing name":    {"email": "alice@example.com", "password": harness.DefaultPassword},
+			"invalid email":   {"name": "Alice", "email": "alice", "password": harness.DefaultPassword},
+			"short password":  {"name": "Alice", "email": "alice@example.com", "password": "short"},
+			"short phone no.": {"name": "Alice", "email": "alice@example.com", "password": harness.DefaultPassword, "phone_number": "123"},
+		}
+		for name, body := range tests {
+			t.Run(name, func(t *testing.T) {
+				h.PostJSON("/api/user/register", "", body).
+					AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+			})
+		}
+	})
+}
+
+func TestLogin(t *testing.T) {
+	t.Run("issues tokens for valid credentials", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		res := h.PostJSON("/api/user/login", "", request.UserLogin{
+			Email:    "alice@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertSuccess(http.StatusOK, message.SuccessLogin)
+
+		var tokens response.RefreshToken
+		res.DecodeData(&tokens)
+		if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+			t.Errorf("tokens = %+v, want both tokens", tokens)
+		}
+		if tokens.Role != user.RoleUser {
+			t.Errorf("role = %q, want %q", tokens.Role, user.RoleUser)
+		}
+	})
+
+	t.Run("rejects a wrong password", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		h.PostJSON("/api/user/login", "", request.UserLogin{
+			Email:    "alice@example.com",
+			Password: "wrongpassword",
+		}).AssertFailure(http.StatusUnauthorized, message.FailedLogin).
+			AssertError(refresh_token.ErrorPasswordNotMatch.Error())
+	})
+
+	t.Run("rejects an unknown email", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.PostJSON("/api/user/login", "", request.UserLogin{
+			Email:    "nobody@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedLogin).
+			AssertError(user.ErrorEmailNotFound.Error())
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.PostJSON("/api/user/login", "", map[string]string{"email": "alice@example.com"}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+}
+
+func TestAuthentication(t *testing.T) {
+	protected := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/user/me"},
+		{http.MethodPost, "/api/user/logout"},
+		{http.MethodGet, "/api/user/"},
+		{http.MethodPatch, "/api/user/"},
+		{http.MethodDelete, "/api/user/"},
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "missing header", header: "", want: middleware.ErrorTokenNotFound.Error()},
+		{name: "missing bearer prefix", header: "Token abc", want: middleware.ErrorTokenNotValid.Error()},
+		{name: "malformed token", header: "Bearer not-a-jwt", want: middleware.ErrorTokenNotValid.Error()},
+	}
+
+	h := harness.New(t)
+	for _, route := range protected {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s %s %s", route.method, route.path, tt.name), func(t *testing.T) {
+				req := harness.Request{Method: route.method, Path: route.path, Header: http.Header{}}
+				if tt.header != "" {
+					req.Header.Set("Authorization", tt.header)
+				}
+
+				h.Do(req).
+					AssertFailure(http.StatusUnauthorized, message.FailedProcessRequest).
+					AssertError(tt.want)
+			})
+		}
+	}
+}
+
+func TestAccessTokenExpiry(t *testing.T) {
+	h := harness.New(t)
+	session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+	h.Clock.Advance(15*time.Minute - time.Second)
+	h.Get("/api/user/me", session.Token()).AssertStatus(http.StatusOK)
+
+	h.Clock.Advance(2 * time.Second)
+	h.Get("/api/user/me", session.Token()).
+		AssertFailure(http.StatusUnauthorized, message.FailedProcessRequest).
+		AssertError(middleware.ErrorTokenNotValid.Error())
+}
+
+func TestMe(t *testing.T) {
+	t.Run("returns the authenticated user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Get("/api/user/me", session.Token()).
+			AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		var me response.User
+		res.DecodeData(&me)
+		if me.ID != session.User.ID || me.Email != "alice@example.com" {
+			t.Errorf("me = %+v, want user %s", me, session.User.ID)
+		}
+	})
+
+	t.Run("fails once the user is deleted", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.DeleteUser(session.Token(), "*").AssertStatus(http.StatusOK)
+
+		h.Get("/api/user/me", session.Token()).
+			AssertFailure(http.StatusNotFound, message.FailedGetUser).
+			AssertError(user.ErrorUserNotFound.Error())
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: session.Tokens.RefreshToken,
+			UserID:       session.User.ID,
+		}).AssertSuccess(http.StatusOK, message.SuccessRefreshToken)
+
+		var rotated response.RefreshToken
+		res.DecodeData(&rotated)
+		if rotated.AccessToken == "" || rotated.RefreshToken == "" {
+			t.Fatalf("tokens = %+v, want both tokens", rotated)
+		}
+		if rotated.RefreshToken == session.Tokens.RefreshToken {
+			t.Error("refresh token was not rotated")
+		}
+
+		h.Get("/api/user/me", rotated.AccessToken).AssertStatus(http.StatusOK)
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: session.Tokens.RefreshToken,
+			UserID:       session.User.ID,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedRefreshToken).
+			AssertError(user.ErrorTokenInvalid.Error())
+	})
+
+	t.Run("rejects an expired refresh token", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Clock.Advance(7*24*time.Hour + time.Second)
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: session.Tokens.RefreshToken,
+			UserID:       session.User.ID,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedRefreshToken).
+			AssertError(user.ErrorTokenExpired.Error())
+	})
+
+	t.Run("accepts a refresh token right before it expires", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Clock.Advance(7*24*time.Hour - time.Second)
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: session.Tokens.RefreshToken,
+			UserID:       session.User.ID,
+		}).AssertSuccess(http.StatusOK, message.SuccessRefreshToken)
+	})
+
+	t.Run("rejects a token that does not match", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: "forged-token",
+			UserID:       session.User.ID,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedRefreshToken).
+			AssertError(user.ErrorTokenInvalid.Error())
+	})
+
+	t.Run("rejects a user without a refresh token", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: "any-token",
+			UserID:       uuid.NewString(),
+		}).AssertFailure(http.StatusUnauthorized, message.FailedRefreshToken).
+			AssertError(refresh_token.ErrorThisUserRefreshTokenNotFound.Error())
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.PostJSON("/api/user/refresh-token", "", map[string]string{"user_id": uuid.NewString()}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("revokes the refresh token", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(harness.Request{Method: http.MethodPost, Path: "/api/user/logout", Token: session.Token()}).
+			AssertSuccess(http.StatusOK, message.SuccessLogout)
+
+		h.PostJSON("/api/user/refresh-token", "", request.RefreshToken{
+			RefreshToken: session.Tokens.RefreshToken,
+			UserID:       session.User.ID,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedRefreshToken).
+			AssertError(refresh_token.ErrorThisUserRefreshTokenNotFound.Error())
+	})
+
+	t.Run("fails once the user is deleted", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.DeleteUser(session.Token(), "*").AssertStatus(http.StatusOK)
+
+		h.Do(harness.Request{Method: http.MethodPost, Path: "/api/user/logout", Token: session.Token()}).
+			AssertFailure(http.StatusNotFound, message.FailedLogout).
+			AssertError(user.ErrorUserNotFound.Error())
+	})
+}
+
+func TestGetAllUsers(t *testing.T) {
+	t.Run("paginates users", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		for i := range 4 {
+			h.RegisterUser(fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i), harness.DefaultPassword)
+		}
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"page": {"2"}, "per_page": {"2"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+		var users []response.User
+		res.DecodeData(&users)
+		if len(users) != 2 {
+			t.Errorf("got %d users, want 2", len(users))
+		}
+
+		var meta pagination.Response
+		res.DecodeMeta(&meta)
+		want := pagination.Counted(pagination.Request{Page: 2, PerPage: 2}, 5)
+		if !reflect.DeepEqual(meta, want) {
+			t.Errorf("meta = %+v, want %+v", meta, want)
+		}
+	})
+
+	t.Run("pages by cursor", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		for i := range 2 {
+			h.RegisterUser(fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i), harness.DefaultPassword)
+		}
+
+		page := func(cursor string) ([]response.User, pagination.Response) {
+			res := h.Do(harness.Request{
+				Method: http.MethodGet,
+				Path:   "/api/v1/user/",
+				Token:  session.Token(),
+				Query:  url.Values{"cursor": {cursor}, "per_page": {"2"}, "skip_count": {"true"}},
+			}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+			var users []response.User
+			res.DecodeData(&users)
+			var meta pagination.Response
+			res.DecodeMeta(&meta)
+			return users, meta
+		}
+
+		first, meta := page("")
+		if len(first) != 2 || meta.Next == "" || meta.Prev != "" || meta.Count != nil || meta.Page != 0 {
+			t.Fatalf("first page = %d users with meta %+v, want 2 users, a next cursor and no count", len(first), meta)
+		}
+		second, meta := page(meta.Next)
+		if len(second) != 1 || second[0].Email != "user1@example.com" || meta.Next != "" || meta.Prev == "" {
+			t.Errorf("second page = %+v with meta %+v, want the last user and a prev cursor", second, meta)
+		}
+	})
+
+	t.Run("rejects a forged cursor", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"cursor": {"eyJrIjoxfQ.c2lnbmF0dXJl"}},
+		}).AssertFailure(http.StatusBadRequest, message.FailedGetAllUsers).
+			AssertCode(pagination.ErrorCursorInvalid.Code)
+	})
+
+	t.Run("sorts and filters by query parameters", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.RegisterUser("Bob", "bob@example.com", harness.DefaultPassword)
+		h.RegisterUser("Carol", "carol@example.com", harness.DefaultPassword)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/user/",
+			Token:  session.Token(),
+			Query: url.Values{
+				"sort":                {"-name"},
+				"filter[role]":        {"user"},
+				"filter[email][in]":   {"alice@example.com,carol@example.com"},
+				"filter[is_verified]": {"false"},
+			},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+		var users []response.User
+		res.DecodeData(&users)
+		if len(users) != 2 || users[0].Name != "Carol" || users[1].Name != "Alice" {
+			t.Errorf("users = %+v, want Carol then Alice", users)
+		}
+	})
+
+	t.Run("rejects unknown sort and filter fields", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		tests := map[string]struct {
+			query url.Values
+			code  string
+		}{
+			"sort field":      {url.Values{"sort": {"password"}}, pagination.ErrorSortField.Code},
+			"filter field":    {url.Values{"filter[password]": {"secret"}}, pagination.ErrorFilterField.Code},
+			"nested field":    {url.Values{"filter[name][eq][x]": {"Alice"}}, pagination.ErrorFilterField.Code},
+			"filter operator": {url.Values{"filter[name][like]": {"Al%"}}, pagination.ErrorFilterOperator.Code},
+			"filter value":    {url.Values{"filter[created_at][gte]": {"last week"}}, pagination.ErrorFilterValue.Code},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				h.Do(harness.Request{
+					Method: http.MethodGet,
+					Path:   "/api/v1/user/",
+					Token:  session.Token(),
+					Query:  tt.query,
+				}).AssertFailure(http.StatusBadRequest, message.FailedGetAllUsers).
+					AssertCode(tt.code)
+			})
+		}
+	})
+
+	t.Run("filters by search term", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.RegisterUser("Bob", "bob@example.com", harness.DefaultPassword)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"search": {"bob"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+		var users []response.User
+		res.DecodeData(&users)
+		if len(users) != 1 || users[0].Email != "bob@example.com" {
+			t.Errorf("users = %+v, want only bob", users)
+		}
+		want := map[string]string{"name": "<mark>Bob</mark>", "email": "<mark>bob</mark>@example.com"}
+		if len(users) == 1 && !reflect.DeepEqual(users[0].Highlights, want) {
+			t.Errorf("highlights = %v, want %v", users[0].Highlights, want)
+		}
+	})
+
+	t.Run("ranks and highlights search matches", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Annabel", "annabel@example.com")
+		h.RegisterUser("Ann", "ann@example.com", harness.DefaultPassword)
+		h.RegisterUser("Joanne <Jo>", "jo@example.com", harness.DefaultPassword)
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v2/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"search": {"ANN"}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+
+		var users []v2.User
+		res.DecodeData(&users)
+		var names []string
+		for _, u := range users {
+			names = append(names, u.Name)
+		}
+		if want := []string{"Ann", "Annabel", "Joanne <Jo>"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("names = %v, want %v", names, want)
+		}
+		if got := users[2].Highlights; got["name"] != "Jo<mark>ann</mark>e &lt;Jo&gt;" || got["email"] != "" {
+			t.Errorf("highlights = %v, want the escaped name only", got)
+		}
+	})
+
+	t.Run("rejects a malformed query", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/",
+			Token:  session.Token(),
+			Query:  url.Values{"page": {"first"}},
+		}).AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	t.Run("updates the authenticated user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.UpdateUser(session.Token(), h.ETag(session.Token()), request.UserUpdate{Name: "Alice Liddell"}).
+			AssertSuccess(http.StatusOK, message.SuccessUpdateUser)
+
+		var updated response.UserUpdate
+		res.DecodeData(&updated)
+		if updated.Name != "Alice Liddell" || updated.Email != "alice@example.com" {
+			t.Errorf("updated = %+v", updated)
+		}
+
+		var me response.User
+		h.Get("/api/user/me", session.Token()).AssertStatus(http.StatusOK).DecodeData(&me)
+		if me.Name != "Alice Liddell" {
+			t.Errorf("stored name = %q, want %q", me.Name, "Alice Liddell")
+		}
+	})
+
+	t.Run("rejects an email owned by another user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.RegisterUser("Bob", "bob@example.com", harness.DefaultPassword)
+
+		h.UpdateUser(session.Token(), "*", request.UserUpdate{Email: "bob@example.com"}).
+			AssertFailure(http.StatusConflict, message.FailedUpdateUser).
+			AssertError(user.ErrorEmailAlreadyExists.Error())
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.UpdateUser(session.Token(), "*", map[string]string{"email": "not-an-email"}).
+			AssertFailure(http.StatusBadRequest, message.FailedGetDataFromBody)
+	})
+
+	t.Run("fails once the user is deleted", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.DeleteUser(session.Token(), "*").AssertStatus(http.StatusOK)
+
+		h.UpdateUser(session.Token(), "*", request.UserUpdate{Name: "Ghost"}).
+			AssertFailure(http.StatusNotFound, message.FailedUpdateUser).
+			AssertError(user.ErrorUserNotFound.Error())
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	t.Run("deletes the authenticated user", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		h.DeleteUser(session.Token(), "*").
+			AssertSuccess(http.StatusOK, message.SuccessDeleteUser)
+
+		h.PostJSON("/api/user/login", "", request.UserLogin{
+			Email:    "alice@example.com",
+			Password: harness.DefaultPassword,
+		}).AssertFailure(http.StatusUnauthorized, message.FailedLogin)
+	})
+
+	t.Run("fails when the user is already deleted", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+		h.DeleteUser(session.Token(), "*").AssertStatus(http.StatusOK)
+
+		h.DeleteUser(session.Token(), "*").
+			AssertFailure(http.StatusNotFound, message.FailedDeleteUser).
+			AssertError(user.ErrorUserNotFound.Error())
+	})
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fawwasaldy/gin-clean-architecture/internal/application/response"
+	v2 "github.com/fawwasaldy/gin-clean-architecture/internal/presentation/controller/v2"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/message"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/middleware"
+	"github.com/fawwasaldy/gin-clean-architecture/internal/presentation/route"
+	"github.com/fawwasaldy/gin-clean-architecture/tests/harness"
+)
+
+func TestAPIVersions(t *testing.T) {
+	t.Run("serves version 1 under /api/v1", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Get("/api/v1/user/me", session.Token()).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		var me response.User
+		res.DecodeData(&me)
+		if me.Email != "alice@example.com" {
+			t.Errorf("email = %q, want alice@example.com", me.Email)
+		}
+		assertHeaders(t, res, map[string]string{middleware.APIVersionHeader: "v1", "Deprecation": "", "Sunset": ""})
+	})
+
+	t.Run("nests the avatar in version 2", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Get("/api/v2/user/me", session.Token()).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+		assertHeaders(t, res, map[string]string{middleware.APIVersionHeader: "v2"})
+		if data := string(res.Envelope.Data); !strings.Contains(data, `"avatar":null`) || strings.Contains(data, "image_url") {
+			t.Errorf("data = %s, want a null avatar and no image_url", data)
+		}
+
+		updated := h.Do(harness.Request{
+			Method: http.MethodPut,
+			Path:   "/api/v2/user/me/avatar",
+			Header: http.Header{"If-Match": {"*"}},
+			Token:  session.Token(),
+			Files:  map[string]harness.File{"image": {Name: "avatar.png", Content: harness.PNG(t, 128, 128)}},
+		}).AssertSuccess(http.StatusOK, message.SuccessUpdateAvatar)
+
+		var user v2.User
+		updated.DecodeData(&user)
+		if user.Avatar == nil || user.Avatar.URL == "" || len(user.Avatar.Thumbnails) != 3 {
+			t.Errorf("avatar = %+v, want a url and three thumbnails", user.Avatar)
+		}
+
+		list := h.Get("/api/v2/user/", session.Token()).AssertSuccess(http.StatusOK, message.SuccessGetAllUsers)
+		var users []v2.User
+		list.DecodeData(&users)
+		if len(users) != 1 || users[0].Avatar == nil {
+			t.Errorf("users = %+v, want Alice with her avatar", users)
+		}
+	})
+
+	t.Run("keeps the endpoints version 2 did not change", func(t *testing.T) {
+		h := harness.New(t)
+		h.RegisterUser("Alice", "alice@example.com", harness.DefaultPassword)
+
+		h.PostJSON("/api/v2/user/login", "", map[string]string{"email": "alice@example.com", "password": harness.DefaultPassword}).
+			AssertSuccess(http.StatusOK, message.SuccessLogin)
+
+		location := h.Do(tusRequest(http.MethodPost, "/api/v2/files/", h.RegisterAndLogin("Bob", "bob@example.com").Token(), http.Header{"Upload-Length": {"5"}})).
+			AssertStatus(http.StatusCreated).Header().Get("Location")
+		if !strings.HasPrefix(location, "/api/v2/files/") {
+			t.Errorf("Location = %q, want an upload under /api/v2/files/", location)
+		}
+	})
+
+	t.Run("deprecates the unversioned routes", func(t *testing.T) {
+		t.Setenv("API_LEGACY_SUNSET", "2027-04-01")
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Get("/api/user/me", session.Token()).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+		assertHeaders(t, res, map[string]string{
+			middleware.APIVersionHeader: "v1",
+			"Deprecation":               "@" + strconv.FormatInt(route.LegacyDeprecatedAt.Unix(), 10),
+			"Sunset":                    "Thu, 01 Apr 2027 00:00:00 GMT",
+			"Link":                      `</api/v1>; rel="successor-version"`,
+		})
+
+		var me response.User
+		res.DecodeData(&me)
+		if me.Email != "alice@example.com" {
+			t.Errorf("email = %q, want alice@example.com", me.Email)
+		}
+	})
+
+	t.Run("negotiates the version through the Accept header", func(t *testing.T) {
+		h := harness.New(t)
+		session := h.RegisterAndLogin("Alice", "alice@example.com")
+
+		res := h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Token:  session.Token(),
+			Header: http.Header{"Accept": {"text/html, " + middleware.VersionMediaTypePrefix + "v2" + middleware.VersionMediaTypeSuffix}},
+		}).AssertSuccess(http.StatusOK, message.SuccessGetUser)
+
+		assertHeaders(t, res, map[string]string{middleware.APIVersionHeader: "v2", "Deprecation": ""})
+		if vary := res.Header().Values("Vary"); !slices.Equal(vary, []string{"Accept", "Accept-Language"}) {
+			t.Errorf("Vary = %v, want Accept and Accept-Language once each", vary)
+		}
+		var user map[string]json.RawMessage
+		res.DecodeData(&user)
+		if _, ok := user["avatar"]; !ok {
+			t.Errorf("data = %s, want the version 2 user", res.Envelope.Data)
+		}
+	})
+
+	t.Run("rejects an unknown version in the Accept header", func(t *testing.T) {
+		h := harness.New(t)
+
+		h.Do(harness.Request{
+			Method: http.MethodGet,
+			Path:   "/api/user/me",
+			Header: http.Header{"Accept": {middleware.VersionMediaTypePrefix + "v9" + middleware.VersionMediaTypeSuffix}},
+		}).AssertFailure(http.StatusNotAcceptable, message.FailedNegotiateVersion).
+			AssertCode(middleware.ErrorVersionNotAcceptable.Code)
+	})
+}